@@ -0,0 +1,384 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/types"
+)
+
+// Root is implemented by the components, that wrap the root component of a routing graph,
+// which can be replaced, such as config.ReloadableComponent
+type Root interface {
+	Current() fiber.Component
+}
+
+// Handler serves the admin API of a running routing graph:
+//
+//	GET    /graph                                         the tree of the components
+//	GET    /components/{id}                               the subtree of the component
+//	POST   /components/{id}/routes/{route_id}/disable     drains the route of the multi-route component
+//	POST   /components/{id}/routes/{route_id}/enable      enables the drained route again
+//	PUT    /components/{id}/strategy                      re-initializes the router's strategy with the properties,
+//	                                                      merged over the current ones (null removes a property)
+//	PUT    /components/{id}/forced_route                  forces the router to select the route {"route": "..."}
+//	DELETE /components/{id}/forced_route                  makes the router use its strategy again
+//	POST   /components/{id}/faults/disable                disables the injection of the fault injector's faults
+//	POST   /components/{id}/faults/enable                 enables the injection of the faults again
+//
+// The components are looked up by their IDs, the first one found in the depth-first order is used.
+// The changes are applied to the live graph, concurrently with the requests being dispatched
+type Handler struct {
+	root fiber.Component
+}
+
+// NewHandler is a creator factory for the Handler. If the root component implements Root,
+// the current root component is resolved on every request
+func NewHandler(root fiber.Component) *Handler {
+	return &Handler{root: root}
+}
+
+// Node describes a component of the routing graph
+type Node struct {
+	ID             string      `json:"id"`
+	Kind           string      `json:"kind"`
+	Type           string      `json:"type,omitempty"`
+	Strategy       *TypedValue `json:"strategy,omitempty"`
+	FanIn          *TypedValue `json:"fan_in,omitempty"`
+	Interceptors   []string    `json:"interceptors,omitempty"`
+	ForcedRoute    string      `json:"forced_route,omitempty"`
+	DisabledRoutes []string    `json:"disabled_routes,omitempty"`
+	FaultsEnabled  *bool       `json:"faults_enabled,omitempty"`
+	Routes         []*Node     `json:"routes,omitempty"`
+}
+
+// TypedValue describes a routing strategy or fan-in, by the name of its type and its properties
+type TypedValue struct {
+	Type       string          `json:"type"`
+	Properties json.RawMessage `json:"properties,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type forcedRouteRequest struct {
+	Route string `json:"route"`
+}
+
+type strategyRequest struct {
+	Properties json.RawMessage `json:"properties"`
+}
+
+// ServeHTTP routes the admin request by its method and path
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	root := h.root
+	if wrapper, ok := root.(Root); ok {
+		root = wrapper.Current()
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(segments) == 1 && segments[0] == "graph":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		node, err := describe(root)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, node)
+	case len(segments) >= 2 && segments[0] == "components":
+		component := find(root, segments[1])
+		if component == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("unknown component: %s", segments[1]))
+			return
+		}
+		h.serveComponent(w, r, component, segments[2:])
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path: %s", r.URL.Path))
+	}
+}
+
+func (h *Handler) serveComponent(w http.ResponseWriter, r *http.Request, component fiber.Component, segments []string) {
+	var err error
+	switch {
+	case len(segments) == 0:
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+	case len(segments) == 3 && segments[0] == "routes" && (segments[2] == "disable" || segments[2] == "enable"):
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		err = setRouteEnabled(component, segments[1], segments[2] == "enable")
+	case len(segments) == 2 && segments[0] == "faults" && (segments[1] == "disable" || segments[1] == "enable"):
+		if r.Method != http.MethodPost {
+			methodNotAllowed(w, http.MethodPost)
+			return
+		}
+		err = setFaultsEnabled(component, segments[1] == "enable")
+	case len(segments) == 1 && segments[0] == "strategy":
+		if r.Method != http.MethodPut {
+			methodNotAllowed(w, http.MethodPut)
+			return
+		}
+		var body strategyRequest
+		if err = json.NewDecoder(r.Body).Decode(&body); err == nil {
+			err = setStrategyProperties(component, body.Properties)
+		}
+	case len(segments) == 1 && segments[0] == "forced_route":
+		var body forcedRouteRequest
+		switch r.Method {
+		case http.MethodPut:
+			if err = json.NewDecoder(r.Body).Decode(&body); err == nil && body.Route == "" {
+				err = errors.New("route is required")
+			}
+		case http.MethodDelete:
+		default:
+			methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+			return
+		}
+		if err == nil {
+			err = forceRoute(component, body.Route)
+		}
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown path: %s", r.URL.Path))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("component %s: %w", component.ID(), err))
+		return
+	}
+
+	node, err := describe(component)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, node)
+}
+
+func setRouteEnabled(component fiber.Component, routeID string, enabled bool) error {
+	controller, ok := component.(fiber.RouteController)
+	if !ok {
+		return errors.New("routes can't be disabled")
+	}
+	if enabled {
+		return controller.EnableRoute(routeID)
+	}
+	return controller.DisableRoute(routeID)
+}
+
+func setFaultsEnabled(component fiber.Component, enabled bool) error {
+	injector, ok := component.(*fiber.FaultInjector)
+	if !ok {
+		return errors.New("not a fault injector")
+	}
+	if enabled {
+		injector.Enable()
+	} else {
+		injector.Disable()
+	}
+	return nil
+}
+
+// setStrategyProperties replaces the strategy of the router with a new strategy of the same type,
+// initialized with the properties, merged over the properties of the current strategy
+func setStrategyProperties(component fiber.Component, properties json.RawMessage) error {
+	router, ok := component.(fiber.Router)
+	if !ok {
+		return errors.New("not a router")
+	}
+	describer, ok := component.(fiber.Describer)
+	if !ok || describer.Describe().Strategy == nil {
+		return errors.New("routing strategy is not set")
+	}
+	name, ok := types.NameOf(types.RoutingStrategy, describer.Describe().Strategy)
+	if !ok {
+		return fmt.Errorf("routing strategy %T is not installed", describer.Describe().Strategy)
+	}
+	current, err := types.Properties(describer.Describe().Strategy)
+	if err != nil {
+		return fmt.Errorf("strategy %s: %w", name, err)
+	}
+	if properties, err = mergeProperties(current, properties); err != nil {
+		return err
+	}
+	strategy, err := types.StrategyByName(name)
+	if err != nil {
+		return err
+	}
+	if err := strategy.Initialize(properties); err != nil {
+		return fmt.Errorf("strategy %s: %w", name, err)
+	}
+	router.SetStrategy(strategy)
+	return nil
+}
+
+// mergeProperties sets the properties over the current ones, which keep their values, unless they're
+// set or removed (with null). The properties replace the current ones, that are not a JSON object
+func mergeProperties(current json.RawMessage, properties json.RawMessage) (json.RawMessage, error) {
+	var merged map[string]json.RawMessage
+	if len(current) == 0 || json.Unmarshal(current, &merged) != nil {
+		return properties, nil
+	}
+	var patch map[string]json.RawMessage
+	if len(properties) > 0 {
+		if err := json.Unmarshal(properties, &patch); err != nil {
+			return nil, errors.New("properties must be an object")
+		}
+	}
+	for key, value := range patch {
+		if string(value) == "null" {
+			delete(merged, key)
+		} else {
+			merged[key] = value
+		}
+	}
+	return json.Marshal(merged)
+}
+
+func forceRoute(component fiber.Component, routeID string) error {
+	forcer, ok := component.(fiber.RouteForcer)
+	if !ok {
+		return errors.New("routes can't be forced")
+	}
+	return forcer.ForceRoute(routeID)
+}
+
+// describe describes the component and its routes, including the disabled ones
+func describe(component fiber.Component) (*Node, error) {
+	node := &Node{
+		ID:           component.ID(),
+		Kind:         string(component.Kind()),
+		Interceptors: interceptorNames(component),
+	}
+
+	var routes map[string]fiber.Component
+	if describer, ok := component.(fiber.Describer); ok {
+		description := describer.Describe()
+		node.Type = description.Type
+		routes = description.Routes
+
+		var err error
+		if node.Strategy, err = typedValue(types.RoutingStrategy, description.Strategy); err != nil {
+			return nil, fmt.Errorf("component %s: %w", node.ID, err)
+		}
+		if node.FanIn, err = typedValue(types.FanIn, description.FanIn); err != nil {
+			return nil, fmt.Errorf("component %s: %w", node.ID, err)
+		}
+	} else if multiRoute, ok := component.(fiber.MultiRouteComponent); ok {
+		routes = multiRoute.GetRoutes()
+	}
+	if controller, ok := component.(fiber.RouteController); ok {
+		for id := range controller.DisabledRoutes() {
+			node.DisabledRoutes = append(node.DisabledRoutes, id)
+		}
+		sort.Strings(node.DisabledRoutes)
+	}
+	if forcer, ok := component.(fiber.RouteForcer); ok {
+		node.ForcedRoute = forcer.ForcedRoute()
+	}
+	if injector, ok := component.(*fiber.FaultInjector); ok {
+		enabled := injector.Enabled()
+		node.FaultsEnabled = &enabled
+	}
+
+	for _, id := range sortedKeys(routes) {
+		route, err := describe(routes[id])
+		if err != nil {
+			return nil, err
+		}
+		node.Routes = append(node.Routes, route)
+	}
+	return node, nil
+}
+
+func typedValue(category types.Category, instance interface{}) (*TypedValue, error) {
+	if instance == nil {
+		return nil, nil
+	}
+	name, ok := types.NameOf(category, instance)
+	if !ok {
+		name = fmt.Sprintf("%T", instance)
+	}
+	properties, err := types.Properties(instance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid properties of %s: %w", name, err)
+	}
+	return &TypedValue{Type: name, Properties: properties}, nil
+}
+
+// interceptorNames returns the names of the types of the component's interceptors
+func interceptorNames(component fiber.Component) []string {
+	if proxy, ok := component.(*fiber.Proxy); ok {
+		component = proxy.Component
+	}
+	withInterceptors, ok := component.(interface{ Interceptors() []fiber.Interceptor })
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, interceptor := range withInterceptors.Interceptors() {
+		name, ok := types.NameOf(types.Interceptor, interceptor)
+		if !ok {
+			name = fmt.Sprintf("%T", interceptor)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// find returns the first component with the given ID, in the depth-first order
+func find(component fiber.Component, id string) fiber.Component {
+	if component.ID() == id {
+		return component
+	}
+	var routes map[string]fiber.Component
+	if describer, ok := component.(fiber.Describer); ok {
+		routes = describer.Describe().Routes
+	} else if multiRoute, ok := component.(fiber.MultiRouteComponent); ok {
+		routes = multiRoute.GetRoutes()
+	}
+	for _, routeID := range sortedKeys(routes) {
+		if found := find(routes[routeID], id); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func sortedKeys(routes map[string]fiber.Component) []string {
+	keys := make([]string, 0, len(routes))
+	for key := range routes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func methodNotAllowed(w http.ResponseWriter, methods ...string) {
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
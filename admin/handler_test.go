@@ -0,0 +1,286 @@
+package admin_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/admin"
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/fibertest"
+	fiberhttp "github.com/gojek/fiber/http"
+	"github.com/gojek/fiber/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultRouteStrategy selects the default route, with the other routes as fallbacks
+type defaultRouteStrategy struct {
+	fiber.BaseFiberType
+	DefaultRoute string `json:"default_route"`
+	Experiment   string `json:"experiment,omitempty"`
+}
+
+func (s *defaultRouteStrategy) Initialize(properties json.RawMessage) error {
+	if err := json.Unmarshal(properties, s); err != nil {
+		return err
+	}
+	if s.DefaultRoute == "" {
+		return errors.New("default_route is required")
+	}
+	return nil
+}
+
+func (s *defaultRouteStrategy) SelectRoute(
+	_ context.Context,
+	_ fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	var fallbacks []fiber.Component
+	for id, route := range routes {
+		if id != s.DefaultRoute {
+			fallbacks = append(fallbacks, route)
+		}
+	}
+	return routes[s.DefaultRoute], fallbacks, fiber.NewLabelsMap(), nil
+}
+
+func newGraph(t *testing.T) fiber.Component {
+	require.NoError(t, types.InstallType("admin_test.DefaultRouteStrategy", &defaultRouteStrategy{}))
+
+	newBackend := func(body string) *httptest.Server {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(backend.Close)
+		return backend
+	}
+
+	configPath := filepath.Join(t.TempDir(), "fiber.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+type: COMBINER
+id: combiner
+fan_in:
+  type: fiber.FastestResponseFanIn
+interceptors:
+  - type: fiber.LoggingInterceptor
+routes:
+  - id: router
+    type: LAZY_ROUTER
+    strategy:
+      type: admin_test.DefaultRouteStrategy
+      properties:
+        default_route: route_a
+    routes:
+      - id: route_a
+        type: PROXY
+        endpoint: `+newBackend("route_a").URL+`
+      - id: route_b
+        type: PROXY
+        endpoint: `+newBackend("route_b").URL), 0600))
+
+	component, err := config.InitComponentFromConfig(configPath)
+	require.NoError(t, err)
+	return component
+}
+
+func dispatch(t *testing.T, component fiber.Component) string {
+	httpReq, err := http.NewRequest(http.MethodGet, "http://localhost", http.NoBody)
+	require.NoError(t, err)
+	req, err := fiberhttp.NewHTTPRequest(httpReq)
+	require.NoError(t, err)
+
+	resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+	require.True(t, ok)
+	return string(resp.Payload())
+}
+
+func serve(t *testing.T, handler http.Handler, method string, path string, body string) (int, string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestHandler_Graph(t *testing.T) {
+	handler := admin.NewHandler(newGraph(t))
+
+	code, body := serve(t, handler, http.MethodGet, "/graph", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{
+		"id": "combiner",
+		"kind": "Combiner",
+		"type": "COMBINER",
+		"fan_in": {"type": "fiber.FastestResponseFanIn"},
+		"interceptors": ["fiber.LoggingInterceptor"],
+		"routes": [{
+			"id": "router",
+			"kind": "MultiRouteComponent",
+			"type": "LAZY_ROUTER",
+			"strategy": {"type": "admin_test.DefaultRouteStrategy", "properties": {"default_route": "route_a"}},
+			"routes": [
+				{"id": "route_a", "kind": "Caller", "type": "PROXY"},
+				{"id": "route_b", "kind": "Caller", "type": "PROXY"}
+			]
+		}]
+	}`, body)
+
+	code, _ = serve(t, handler, http.MethodPost, "/graph", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+	code, body = serve(t, handler, http.MethodGet, "/components/route_c", "")
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.JSONEq(t, `{"error": "unknown component: route_c"}`, body)
+}
+
+func TestHandler_RouteControl(t *testing.T) {
+	graph := newGraph(t)
+	handler := admin.NewHandler(graph)
+	assert.Equal(t, "route_a", dispatch(t, graph))
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		body             string
+		expectedCode     int
+		expectedBody     string
+		expectedResponse string
+	}{
+		{
+			name:             "disable route",
+			method:           http.MethodPost,
+			path:             "/components/router/routes/route_a/disable",
+			expectedCode:     http.StatusOK,
+			expectedBody:     `"disabled_routes":["route_a"]`,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "enable route",
+			method:           http.MethodPost,
+			path:             "/components/router/routes/route_a/enable",
+			expectedCode:     http.StatusOK,
+			expectedResponse: "route_a",
+		},
+		{
+			name:             "unknown route",
+			method:           http.MethodPost,
+			path:             "/components/router/routes/route_c/disable",
+			expectedCode:     http.StatusBadRequest,
+			expectedBody:     `{"error":"component router: unknown route: route_c"}`,
+			expectedResponse: "route_a",
+		},
+		{
+			name:             "update strategy",
+			method:           http.MethodPut,
+			path:             "/components/router/strategy",
+			body:             `{"properties": {"default_route": "route_b"}}`,
+			expectedCode:     http.StatusOK,
+			expectedBody:     `"properties":{"default_route":"route_b"}`,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "merge strategy properties",
+			method:           http.MethodPut,
+			path:             "/components/router/strategy",
+			body:             `{"properties": {"experiment": "exp_1"}}`,
+			expectedCode:     http.StatusOK,
+			expectedBody:     `"properties":{"default_route":"route_b","experiment":"exp_1"}`,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "remove strategy property",
+			method:           http.MethodPut,
+			path:             "/components/router/strategy",
+			body:             `{"properties": {"experiment": null}}`,
+			expectedCode:     http.StatusOK,
+			expectedBody:     `"properties":{"default_route":"route_b"}`,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "invalid strategy properties",
+			method:           http.MethodPut,
+			path:             "/components/router/strategy",
+			body:             `{"properties": {"default_route": null}}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedBody:     `{"error":"component router: strategy admin_test.DefaultRouteStrategy: default_route is required"}`,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "force route",
+			method:           http.MethodPut,
+			path:             "/components/router/forced_route",
+			body:             `{"route": "route_a"}`,
+			expectedCode:     http.StatusOK,
+			expectedBody:     `"forced_route":"route_a"`,
+			expectedResponse: "route_a",
+		},
+		{
+			name:             "unset forced route",
+			method:           http.MethodDelete,
+			path:             "/components/router/forced_route",
+			expectedCode:     http.StatusOK,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "not a router",
+			method:           http.MethodPut,
+			path:             "/components/combiner/forced_route",
+			body:             `{"route": "router"}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedBody:     `{"error":"component combiner: routes can't be forced"}`,
+			expectedResponse: "route_b",
+		},
+		{
+			name:             "method not allowed",
+			method:           http.MethodGet,
+			path:             "/components/router/strategy",
+			expectedCode:     http.StatusMethodNotAllowed,
+			expectedResponse: "route_b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body := serve(t, handler, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.expectedCode, code, body)
+			assert.Contains(t, body, tt.expectedBody)
+			assert.Equal(t, tt.expectedResponse, dispatch(t, graph))
+		})
+	}
+}
+
+func TestHandler_FaultInjector(t *testing.T) {
+	route := fibertest.NewComponent("route_a", fibertest.OK("route_a"))
+	injector := fiber.NewFaultInjector("injector", route)
+	require.NoError(t, injector.SetFaults(fiber.Faults{Truncate: &fiber.TruncateFault{Percentage: 100, Length: 5}}))
+	handler := admin.NewHandler(injector)
+	assert.Equal(t, "route", dispatch(t, injector))
+
+	code, body := serve(t, handler, http.MethodPost, "/components/injector/faults/disable", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{
+		"id": "injector",
+		"kind": "FaultInjector",
+		"type": "FAULT_INJECTOR",
+		"faults_enabled": false,
+		"routes": [{"id": "route_a", "kind": "Caller"}]
+	}`, body)
+	assert.Equal(t, "route_a", dispatch(t, injector))
+
+	code, body = serve(t, handler, http.MethodPost, "/components/injector/faults/enable", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, `"faults_enabled":true`)
+	assert.Equal(t, "route", dispatch(t, injector))
+
+	code, body = serve(t, handler, http.MethodPost, "/components/route_a/faults/enable", "")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.JSONEq(t, `{"error": "component route_a: not a fault injector"}`, body)
+	code, _ = serve(t, handler, http.MethodGet, "/components/injector/faults/enable", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+}
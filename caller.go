@@ -3,6 +3,7 @@ package fiber
 import (
 	"context"
 	"errors"
+	"io"
 
 	"github.com/gojek/fiber/util"
 )
@@ -34,17 +35,43 @@ func NewCaller(id string, dispatcher Dispatcher) (*Caller, error) {
 
 // Dispatch uses Dispatcher to process incoming request and asynchronously sends
 // received response into the output channel. The output channel will be closed
-// after Dispatcher has processed request and response was sent back
+// after Dispatcher has processed request and response was sent back.
+// If the Dispatcher is a streaming StreamDispatcher, every streamed response is
+// sent into the output channel, which is closed when the stream ends. ContextDispatcher(s)
+// receive the context of the request, so they can be cancelled when it's done
 func (c *Caller) Dispatch(ctx context.Context, req Request) ResponseQueue {
-	ctx = c.beforeDispatch(ctx, req)
+	ctx, resp := c.beforeDispatch(ctx, req)
+	if resp != nil {
+		return c.shortCircuit(ctx, req, resp)
+	}
 	out := make(chan Response, 1)
 	queue := NewResponseQueue(out, 1)
 	defer c.afterDispatch(ctx, req, queue)
+	ctx, out = c.withTimeout(ctx, req, out)
 
 	go func() {
 		defer c.afterCompletion(ctx, req, queue)
+		defer close(out)
+
+		if streamDispatcher, ok := c.dispatcher.(StreamDispatcher); ok && streamDispatcher.IsStreaming() {
+			for resp := range streamDispatcher.DoStream(ctx, req) {
+				out <- resp
+			}
+			return
+		}
+		if contextDispatcher, ok := c.dispatcher.(ContextDispatcher); ok {
+			out <- contextDispatcher.DoContext(ctx, req)
+			return
+		}
 		out <- c.dispatcher.Do(req)
-		close(out)
 	}()
 	return queue
 }
+
+// Close releases the resources held by the Dispatcher, if it implements io.Closer
+func (c *Caller) Close() error {
+	if closer, ok := c.dispatcher.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -42,3 +42,67 @@ func TestCaller_Dispatch(t *testing.T) {
 
 	dispatcher.AssertExpectations(t)
 }
+
+type MockStreamDispatcher struct {
+	MockDispatcher
+	responses []fiber.Response
+}
+
+func (h *MockStreamDispatcher) IsStreaming() bool {
+	return true
+}
+
+func (h *MockStreamDispatcher) DoStream(context.Context, fiber.Request) <-chan fiber.Response {
+	out := make(chan fiber.Response, len(h.responses))
+	for _, resp := range h.responses {
+		out <- resp
+	}
+	close(out)
+	return out
+}
+
+func TestCaller_DispatchStream(t *testing.T) {
+	expectedResponses := []fiber.Response{
+		testutils.MockResp(http.StatusOK, "first", nil, nil),
+		testutils.MockResp(http.StatusOK, "second", nil, nil),
+		testutils.MockResp(http.StatusOK, "third", nil, nil),
+	}
+
+	dispatcher := &MockStreamDispatcher{responses: expectedResponses}
+	caller, _ := fiber.NewCaller("", dispatcher)
+
+	req := testutils.MockReq("GET", "http://:8080/test", "")
+
+	var responses []fiber.Response
+	for resp := range caller.Dispatch(context.Background(), req).Iter() {
+		responses = append(responses, resp)
+	}
+
+	assert.Equal(t, expectedResponses, responses)
+	dispatcher.AssertNotCalled(t, "Do", mock.Anything)
+}
+
+type MockClosingDispatcher struct {
+	MockDispatcher
+}
+
+func (h *MockClosingDispatcher) Close() error {
+	return h.Called().Error(0)
+}
+
+func TestCaller_Close(t *testing.T) {
+	dispatcher := new(MockClosingDispatcher)
+	dispatcher.On("Close").Return(nil).Once()
+	closingCaller, _ := fiber.NewCaller("closing", dispatcher)
+
+	caller, _ := fiber.NewCaller("", new(MockDispatcher))
+
+	fanOut := fiber.NewFanOut("")
+	fanOut.SetRoutes(map[string]fiber.Component{
+		closingCaller.ID(): fiber.NewProxy(nil, closingCaller),
+		caller.ID():        caller,
+	})
+
+	assert.NoError(t, fanOut.Close())
+	dispatcher.AssertExpectations(t)
+}
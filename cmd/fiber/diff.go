@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gojek/fiber/config"
+)
+
+func diff(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fiber diff <old config> <new config>")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	trees := make([]*config.Node, 2)
+	for i, configPath := range flags.Args() {
+		root, err := config.ParseTreeFile(configPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", configPath, err)
+			return exitUsage
+		}
+		trees[i] = root
+	}
+
+	changes := diffTrees(trees[0], trees[1])
+	for _, change := range changes {
+		fmt.Fprintln(stdout, change)
+	}
+	if len(changes) > 0 {
+		return exitFailure
+	}
+	return exitOK
+}
+
+// diffTrees returns the list of changes between the two component trees. Components are
+// matched by their paths (the IDs from the root to the component), so a renamed component
+// is reported as removed and added
+func diffTrees(oldRoot *config.Node, newRoot *config.Node) []string {
+	oldNodes, oldPaths := flatten(oldRoot)
+	newNodes, newPaths := flatten(newRoot)
+
+	var changes []string
+	for _, path := range oldPaths {
+		oldNode := oldNodes[path]
+		newNode, exist := newNodes[path]
+		if !exist {
+			changes = append(changes, fmt.Sprintf("- %s (%s)", path, oldNode.Type))
+			continue
+		}
+		if oldNode.Type != newNode.Type {
+			changes = append(changes, fmt.Sprintf("~ %s: type: %s -> %s", path, oldNode.Type, newNode.Type))
+		}
+		for _, key := range oldNode.AttributeKeys() {
+			oldValue := oldNode.Attributes[key]
+			if newValue, exist := newNode.Attributes[key]; !exist {
+				changes = append(changes, fmt.Sprintf("~ %s: %s: %s -> (unset)", path, key, oldValue))
+			} else if oldValue != newValue {
+				changes = append(changes, fmt.Sprintf("~ %s: %s: %s -> %s", path, key, oldValue, newValue))
+			}
+		}
+		for _, key := range newNode.AttributeKeys() {
+			if _, exist := oldNode.Attributes[key]; !exist {
+				changes = append(changes, fmt.Sprintf("~ %s: %s: (unset) -> %s", path, key, newNode.Attributes[key]))
+			}
+		}
+	}
+	for _, path := range newPaths {
+		if _, exist := oldNodes[path]; !exist {
+			changes = append(changes, fmt.Sprintf("+ %s (%s)", path, newNodes[path].Type))
+		}
+	}
+	return changes
+}
+
+// flatten returns the nodes of the tree by their paths, and the paths in the depth-first order
+func flatten(root *config.Node) (map[string]*config.Node, []string) {
+	nodes := make(map[string]*config.Node)
+	var paths []string
+	root.Walk(func(path []string, node *config.Node) {
+		key := strings.Join(path, "/")
+		if _, exist := nodes[key]; !exist {
+			paths = append(paths, key)
+		}
+		nodes[key] = node
+	})
+	return nodes, paths
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gojek/fiber/config"
+)
+
+func graph(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", "dot", "output format: dot or mermaid")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fiber graph [-format dot|mermaid] <config>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	var render func(io.Writer, *config.Node)
+	switch *format {
+	case "dot":
+		render = renderDOT
+	case "mermaid":
+		render = renderMermaid
+	default:
+		fmt.Fprintf(stderr, "fiber: unknown graph format: %s\n", *format)
+		return exitUsage
+	}
+
+	root, err := config.ParseTreeFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", flags.Arg(0), err)
+		return exitFailure
+	}
+	render(stdout, root)
+	return exitOK
+}
+
+// renderDOT renders the component tree in the Graphviz DOT language
+func renderDOT(w io.Writer, root *config.Node) {
+	fmt.Fprintln(w, "digraph fiber {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	root.Walk(func(path []string, node *config.Node) {
+		id := strings.Join(path, "/")
+		fmt.Fprintf(w, "  %s [label=%s];\n", dotQuote(id), dotQuote(strings.Join(nodeLabel(node), "\n")))
+		if len(path) > 1 {
+			fmt.Fprintf(w, "  %s -> %s;\n", dotQuote(strings.Join(path[:len(path)-1], "/")), dotQuote(id))
+		}
+	})
+	fmt.Fprintln(w, "}")
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + strings.ReplaceAll(s, "\n", `\n`) + `"`
+}
+
+// renderMermaid renders the component tree as a Mermaid flowchart
+func renderMermaid(w io.Writer, root *config.Node) {
+	fmt.Fprintln(w, "graph LR")
+	ids := make(map[string]string)
+	root.Walk(func(path []string, node *config.Node) {
+		key := strings.Join(path, "/")
+		id := fmt.Sprintf("n%d", len(ids))
+		ids[key] = id
+
+		label := strings.ReplaceAll(strings.Join(nodeLabel(node), "<br/>"), `"`, "#quot;")
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", id, label)
+		if len(path) > 1 {
+			fmt.Fprintf(w, "  %s --> %s\n", ids[strings.Join(path[:len(path)-1], "/")], id)
+		}
+	})
+}
+
+// nodeLabel returns the lines of the node label: its ID, type and the most
+// important attribute, such as the endpoint of a proxy or the strategy of a router
+func nodeLabel(node *config.Node) []string {
+	label := []string{node.ID, node.Type}
+	if endpoint, ok := node.Attributes["endpoint"]; ok {
+		label = append(label, endpoint)
+	}
+	for _, key := range []string{"strategy", "fan_in"} {
+		if value, ok := node.Attributes[key]; ok {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal([]byte(value), &typed)
+			label = append(label, key+": "+typed.Type)
+		}
+	}
+	return label
+}
@@ -0,0 +1,68 @@
+// Command fiber is the command line tool to work with fiber config files and to serve them.
+//
+// Usage:
+//
+//	fiber validate <config>...
+//	fiber graph [-format dot|mermaid] <config>
+//	fiber diff <old config> <new config>
+//	fiber schema
+//	fiber serve [flags] [<config>]
+//	fiber replay [flags] <config> <capture file>
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usage = `Usage: fiber <command> [arguments]
+
+Commands:
+  validate   report every problem in the config files
+  graph      render the component tree of the config as DOT or Mermaid
+  diff       show the structural changes between two configs
+  schema     print the JSON Schema of the config format
+  serve      serve the config over http and grpc
+  replay     replay the captured traffic against the config and report the mismatches
+
+Run 'fiber <command> -h' for the arguments of the command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "validate":
+		return validate(args[1:], stdout, stderr)
+	case "graph":
+		return graph(args[1:], stdout, stderr)
+	case "diff":
+		return diff(args[1:], stdout, stderr)
+	case "schema":
+		return schema(args[1:], stdout, stderr)
+	case "serve":
+		return serve(args[1:], stdout, stderr)
+	case "replay":
+		return replay(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "fiber: unknown command: %s\n\n%s", args[0], usage)
+		return exitUsage
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const routerConfig = `
+type: EAGER_ROUTER
+id: eager_router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: route_a
+    type: PROXY
+    timeout: "20s"
+    endpoint: "http://localhost:8080/routes/route-a"
+  - id: route_b
+    type: PROXY
+    timeout: "40s"
+    endpoint: "http://localhost:8080/routes/route-b"
+`
+
+func writeConfig(t *testing.T, name string, content string) string {
+	configPath := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0600))
+	return configPath
+}
+
+func TestRun(t *testing.T) {
+	validConfig := writeConfig(t, "valid.yaml", routerConfig)
+	invalidConfig := writeConfig(t, "invalid.yaml", `
+type: LAZY_ROUTER
+id: router
+routes:
+  - id: route_a
+    type: PROXY`)
+	changedConfig := writeConfig(t, "changed.yaml", `
+type: EAGER_ROUTER
+id: eager_router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: route_a
+    type: PROXY
+    timeout: "10s"
+    endpoint: "http://localhost:8080/routes/route-a"
+  - id: route_c
+    type: PROXY
+    endpoint: "http://localhost:8080/routes/route-c"
+`)
+
+	tests := []struct {
+		name           string
+		args           []string
+		expectedCode   int
+		expectedStdout string
+	}{
+		{
+			name:         "no command",
+			expectedCode: exitUsage,
+		},
+		{
+			name:         "unknown command",
+			args:         []string{"unknown"},
+			expectedCode: exitUsage,
+		},
+		{
+			name:           "valid config",
+			args:           []string{"validate", validConfig},
+			expectedCode:   exitOK,
+			expectedStdout: validConfig + ": OK\n",
+		},
+		{
+			name:         "invalid config",
+			args:         []string{"validate", validConfig, invalidConfig},
+			expectedCode: exitFailure,
+			expectedStdout: validConfig + ": OK\n" +
+				invalidConfig + ":5: $.routes[0].endpoint: missing endpoint\n" +
+				invalidConfig + ":2: $.strategy.type: missing strategy type\n",
+		},
+		{
+			name:         "missing config",
+			args:         []string{"validate", filepath.Join(t.TempDir(), "unknown.yaml")},
+			expectedCode: exitFailure,
+		},
+		{
+			name:         "dot graph",
+			args:         []string{"graph", validConfig},
+			expectedCode: exitOK,
+			expectedStdout: `digraph fiber {
+  rankdir=LR;
+  node [shape=box];
+  "eager_router" [label="eager_router\nEAGER_ROUTER\nstrategy: fiber.RandomRoutingStrategy"];
+  "eager_router/route_a" [label="route_a\nPROXY\nhttp://localhost:8080/routes/route-a"];
+  "eager_router" -> "eager_router/route_a";
+  "eager_router/route_b" [label="route_b\nPROXY\nhttp://localhost:8080/routes/route-b"];
+  "eager_router" -> "eager_router/route_b";
+}
+`,
+		},
+		{
+			name:         "mermaid graph",
+			args:         []string{"graph", "-format", "mermaid", validConfig},
+			expectedCode: exitOK,
+			expectedStdout: `graph LR
+  n0["eager_router<br/>EAGER_ROUTER<br/>strategy: fiber.RandomRoutingStrategy"]
+  n1["route_a<br/>PROXY<br/>http://localhost:8080/routes/route-a"]
+  n0 --> n1
+  n2["route_b<br/>PROXY<br/>http://localhost:8080/routes/route-b"]
+  n0 --> n2
+`,
+		},
+		{
+			name:         "unknown graph format",
+			args:         []string{"graph", "-format", "svg", validConfig},
+			expectedCode: exitUsage,
+		},
+		{
+			name:         "no changes",
+			args:         []string{"diff", validConfig, validConfig},
+			expectedCode: exitOK,
+		},
+		{
+			name:         "changes",
+			args:         []string{"diff", validConfig, changedConfig},
+			expectedCode: exitFailure,
+			expectedStdout: `~ eager_router/route_a: timeout: 20s -> 10s
+- eager_router/route_b (PROXY)
++ eager_router/route_c (PROXY)
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+
+			assert.Equal(t, tt.expectedCode, code, stderr.String())
+			assert.Equal(t, tt.expectedStdout, stdout.String())
+		})
+	}
+}
+
+func TestRun_Schema(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"schema"}, &stdout, &stderr)
+
+	assert.Equal(t, exitOK, code, stderr.String())
+	assert.True(t, json.Valid(stdout.Bytes()))
+	assert.Contains(t, stdout.String(), `"$schema": "http://json-schema.org/draft-07/schema#"`)
+
+	assert.Equal(t, exitUsage, run([]string{"schema", "fiber.yaml"}, &stdout, &stderr))
+}
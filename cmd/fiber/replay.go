@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/extras/interceptor"
+)
+
+// maxReportedPayload is the length, that the mismatched payloads are truncated to in the report
+const maxReportedPayload = 200
+
+// replayOptions are the options of the replay command
+type replayOptions struct {
+	rate        float64
+	concurrency int
+	timeout     time.Duration
+	component   string
+}
+
+// replayResult is the result of the replay of the captured record
+type replayResult struct {
+	err             error
+	skipped         bool
+	statusMismatch  bool
+	payloadMismatch bool
+	// countMismatch is set, if the number of the responses differs from the recorded one
+	countMismatch bool
+	statusCode    int
+	payload       []byte
+	responses     int
+}
+
+func replay(args []string, stdout io.Writer, stderr io.Writer) int {
+	var options replayOptions
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Float64Var(&options.rate, "rate", 0, "requests per second to replay the records at, unlimited if zero")
+	flags.IntVar(&options.concurrency, "concurrency", 10, "maximum number of the requests in flight")
+	flags.DurationVar(&options.timeout, "timeout", 20*time.Second, "timeout of the replayed requests")
+	flags.StringVar(&options.component, "component", "",
+		"path of the component, whose captured records are replayed, the root components' records by default")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fiber replay [flags] <config> <capture file>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 2 || options.concurrency < 1 || options.rate < 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	records, err := readCapture(flags.Arg(1), options.component)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", flags.Arg(1), err)
+		return exitFailure
+	}
+	component, err := config.InitComponentFromConfig(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", flags.Arg(0), err)
+		return exitFailure
+	}
+	if closer, ok := component.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	results := replayRecords(component, records, options)
+	if report(stdout, records, results) {
+		return exitOK
+	}
+	return exitFailure
+}
+
+// readCapture reads the records of the component from the capture file
+func readCapture(path string, component string) ([]interceptor.CaptureRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	records, err := interceptor.ReadCaptureRecords(file)
+	if err != nil {
+		return nil, err
+	}
+	var filtered []interceptor.CaptureRecord
+	for _, record := range records {
+		if component == record.Component || (component == "" && !strings.Contains(record.Component, "/")) {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered, nil
+}
+
+// replayRecords dispatches the requests of the records by the component at the rate of the options,
+// with at most options.concurrency requests in flight
+func replayRecords(
+	component fiber.Component,
+	records []interceptor.CaptureRecord,
+	options replayOptions,
+) []replayResult {
+	var ticks <-chan time.Time
+	if options.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / options.rate))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	results := make([]replayResult, len(records))
+	inFlight := make(chan struct{}, options.concurrency)
+	var wg sync.WaitGroup
+	for idx := range records {
+		if ticks != nil && idx > 0 {
+			<-ticks
+		}
+		inFlight <- struct{}{}
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			results[idx] = replayRecord(component, &records[idx], options.timeout)
+		}(idx)
+	}
+	wg.Wait()
+	return results
+}
+
+func replayRecord(component fiber.Component, record *interceptor.CaptureRecord, timeout time.Duration) replayResult {
+	if record.Response == nil {
+		return replayResult{skipped: true}
+	}
+	req, err := record.NewRequest()
+	if err != nil {
+		return replayResult{err: err}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	// the queue is drained, so the component completes the dispatch, and the responses are counted
+	var result replayResult
+	for resp := range component.Dispatch(ctx, req).Iter() {
+		if result.responses == 0 {
+			result.statusCode, result.payload = resp.StatusCode(), resp.Payload()
+		}
+		result.responses++
+	}
+	if result.responses == 0 {
+		return replayResult{err: errors.New("no response")}
+	}
+
+	if result.statusCode != record.Response.StatusCode {
+		result.statusMismatch = true
+	} else if !equalPayloads(result.payload, record.Response.Payload) {
+		result.payloadMismatch = true
+	} else if record.Responses > 0 && result.responses != record.Responses {
+		result.countMismatch = true
+	}
+	return result
+}
+
+// equalPayloads compares the JSON payloads semantically, and the other payloads byte by byte
+func equalPayloads(a []byte, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var aValue, bValue interface{}
+	if json.Unmarshal(a, &aValue) != nil || json.Unmarshal(b, &bValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// report writes the mismatches and the summary of the replay, and returns true, if all the responses matched
+func report(w io.Writer, records []interceptor.CaptureRecord, results []replayResult) bool {
+	var matched, skipped, statusMismatches, payloadMismatches, countMismatches, failed int
+	for idx, result := range results {
+		record := &records[idx]
+		name := fmt.Sprintf("record %d (%s)", idx+1, strings.TrimSpace(record.Method+" "+record.URL))
+		switch {
+		case result.skipped:
+			skipped++
+		case result.err != nil:
+			failed++
+			fmt.Fprintf(w, "%s: %v\n", name, result.err)
+		case result.statusMismatch:
+			statusMismatches++
+			fmt.Fprintf(w, "%s: status %d, recorded %d\n", name, result.statusCode, record.Response.StatusCode)
+		case result.payloadMismatch:
+			payloadMismatches++
+			fmt.Fprintf(w, "%s: payload %s, recorded %s\n", name,
+				truncate(result.payload), truncate(record.Response.Payload))
+		case result.countMismatch:
+			countMismatches++
+			fmt.Fprintf(w, "%s: %d responses, recorded %d\n", name, result.responses, record.Responses)
+		default:
+			matched++
+		}
+	}
+	fmt.Fprintf(w, "replayed %d records: %d matched, %d status mismatches, %d payload mismatches, %d errors",
+		len(results)-skipped, matched, statusMismatches, payloadMismatches, failed)
+	if countMismatches > 0 {
+		fmt.Fprintf(w, ", %d response count mismatches", countMismatches)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(w, ", %d skipped without a recorded response", skipped)
+	}
+	fmt.Fprintln(w)
+	return matched+skipped == len(results)
+}
+
+func truncate(payload []byte) string {
+	if len(payload) > maxReportedPayload {
+		return fmt.Sprintf("%q...", payload[:maxReportedPayload])
+	}
+	return fmt.Sprintf("%q", payload)
+}
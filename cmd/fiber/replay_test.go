@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	fiberErrors "github.com/gojek/fiber/errors"
+	"github.com/gojek/fiber/extras/interceptor"
+	fiberhttp "github.com/gojek/fiber/http"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/gojek/fiber/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPayloadBackend(t *testing.T, payloads map[string]string) *httptest.Server {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, ok := payloads[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(payload))
+	}))
+	t.Cleanup(backend.Close)
+	return backend
+}
+
+func TestReplay(t *testing.T) {
+	recorded := newPayloadBackend(t, map[string]string{
+		"/users":  `{"name": "John", "age": 30}`,
+		"/orders": "orders",
+	})
+	capturePath := filepath.Join(t.TempDir(), "capture.jsonl")
+	captureConfig := writeConfig(t, "capture.yaml", `
+type: LAZY_ROUTER
+id: router
+strategy:
+  type: fiber.RandomRoutingStrategy
+interceptors:
+  - type: fiber.CaptureInterceptor
+    recursive: true
+    properties:
+      path: `+capturePath+`
+      labels: [idx]
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: `+recorded.URL)
+
+	component, err := config.InitComponentFromConfig(captureConfig)
+	require.NoError(t, err)
+	for idx, path := range []string{"/users", "/orders", "/missing"} {
+		httpReq, err := http.NewRequest(http.MethodGet, "http://localhost"+path, http.NoBody)
+		require.NoError(t, err)
+		req, err := fiberhttp.NewHTTPRequest(httpReq)
+		require.NoError(t, err)
+		_, ok := <-component.Dispatch(context.Background(), req).Iter()
+		require.True(t, ok)
+
+		// the router and its route write the records, once their dispatches are completed
+		require.Eventually(t, func() bool {
+			content, err := os.ReadFile(capturePath)
+			return err == nil && bytes.Count(content, []byte("\n")) == 2*(idx+1)
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	content, err := os.ReadFile(capturePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"component":"router/route_a"`)
+	assert.Contains(t, string(content), `"labels":{"idx":["0"]}`)
+
+	replayed := newPayloadBackend(t, map[string]string{
+		"/users":   `{"age": 30, "name": "John"}`,
+		"/orders":  "orders v2",
+		"/missing": "found",
+	})
+	replayConfig := writeConfig(t, "replay.yaml", `
+type: PROXY
+id: route_b
+endpoint: `+replayed.URL)
+
+	var stdout, stderr strings.Builder
+	code := run([]string{"replay", "-rate", "100", replayConfig, capturePath}, &stdout, &stderr)
+	assert.Equal(t, exitFailure, code, stderr.String())
+	assert.Equal(t, `record 2 (GET http://localhost/orders): payload "orders v2", recorded "orders"
+record 3 (GET http://localhost/missing): status 200, recorded 502
+replayed 3 records: 1 matched, 1 status mismatches, 1 payload mismatches, 0 errors
+`, stdout.String())
+
+	// the records of the route are replayed against the same backend
+	routeConfig := writeConfig(t, "route.yaml", `
+type: PROXY
+id: route_a
+endpoint: `+recorded.URL)
+	stdout.Reset()
+	code = run([]string{"replay", "-component", "router/route_a", routeConfig, capturePath}, &stdout, &stderr)
+	assert.Equal(t, exitOK, code, stderr.String())
+	assert.Equal(t, "replayed 3 records: 3 matched, 0 status mismatches, 0 payload mismatches, 0 errors\n",
+		stdout.String())
+}
+
+// replayDispatcher replies to the replayed requests with the responses in their order.
+// The nil response isn't sent, until the request times out
+type replayDispatcher struct {
+	mu        sync.Mutex
+	responses []fiber.Response
+}
+
+func (d *replayDispatcher) Do(req fiber.Request) fiber.Response {
+	return d.DoContext(context.Background(), req)
+}
+
+func (d *replayDispatcher) DoContext(ctx context.Context, req fiber.Request) fiber.Response {
+	d.mu.Lock()
+	resp := d.responses[0]
+	d.responses = d.responses[1:]
+	d.mu.Unlock()
+
+	if resp == nil {
+		<-ctx.Done()
+		return fiber.NewErrorResponse(fiberErrors.ErrRequestTimeout(req.Protocol()))
+	}
+	return resp
+}
+
+func TestReplayRecord(t *testing.T) {
+	route, err := fiber.NewCaller("route_a", &replayDispatcher{responses: []fiber.Response{
+		testUtilsHttp.MockResp(http.StatusOK, `{"name": "John", "age": 30}`, nil, nil),
+		testUtilsHttp.MockResp(http.StatusOK, strings.Repeat("a", maxReportedPayload+1), nil, nil),
+		testUtilsHttp.MockResp(http.StatusNotFound, "not found", nil, nil),
+		nil,
+	}})
+	require.NoError(t, err)
+	records := []interceptor.CaptureRecord{
+		{Method: http.MethodGet, URL: "http://localhost/users", Protocol: protocol.HTTP,
+			Response: &interceptor.CapturedResponse{
+				StatusCode: http.StatusOK,
+				Payload:    []byte(`{"age":30,"name":"John"}`),
+			}},
+		{Method: http.MethodGet, URL: "http://localhost/orders", Protocol: protocol.HTTP,
+			Response: &interceptor.CapturedResponse{StatusCode: http.StatusOK, Payload: []byte("b")}},
+		{Method: http.MethodGet, URL: "http://localhost/missing", Protocol: protocol.HTTP,
+			Response: &interceptor.CapturedResponse{StatusCode: http.StatusOK}},
+		{Method: http.MethodGet, URL: "http://localhost/slow", Protocol: protocol.HTTP,
+			Response: &interceptor.CapturedResponse{StatusCode: http.StatusOK}},
+		{Method: http.MethodGet, URL: "http://localhost/skipped", Protocol: protocol.HTTP},
+		{Method: http.MethodGet, URL: "http://localhost/unknown", Protocol: "UNKNOWN",
+			Response: &interceptor.CapturedResponse{StatusCode: http.StatusOK}},
+	}
+
+	// the records are replayed one by one, so that the replies of the route are in their order
+	results := replayRecords(route, records, replayOptions{concurrency: 1, timeout: 100 * time.Millisecond})
+	var stdout strings.Builder
+	assert.False(t, report(&stdout, records, results))
+	assert.Equal(t, `record 2 (GET http://localhost/orders): payload "`+strings.Repeat("a", maxReportedPayload)+
+		`"..., recorded "b"
+record 3 (GET http://localhost/missing): status 404, recorded 200
+record 4 (GET http://localhost/slow): status 408, recorded 200
+record 6 (GET http://localhost/unknown): unknown protocol: UNKNOWN
+replayed 5 records: 1 matched, 2 status mismatches, 1 payload mismatches, 1 errors, `+
+		"1 skipped without a recorded response\n", stdout.String())
+}
+
+func TestReplayRecord_ResponseCount(t *testing.T) {
+	newRoute := func(id string) fiber.Component {
+		route, err := fiber.NewCaller(id, &replayDispatcher{responses: []fiber.Response{
+			testUtilsHttp.MockResp(http.StatusOK, "ok", nil, nil),
+		}})
+		require.NoError(t, err)
+		return route
+	}
+	fanOut := fiber.NewFanOut("fan_out")
+	fanOut.SetRoutes(map[string]fiber.Component{"route_a": newRoute("route_a"), "route_b": newRoute("route_b")})
+	record := interceptor.CaptureRecord{Method: http.MethodGet, URL: "http://localhost/", Protocol: protocol.HTTP,
+		Response:  &interceptor.CapturedResponse{StatusCode: http.StatusOK, Payload: []byte("ok")},
+		Responses: 3,
+	}
+
+	result := replayRecord(fanOut, &record, time.Second)
+	assert.True(t, result.countMismatch)
+	assert.Equal(t, 2, result.responses)
+
+	var stdout strings.Builder
+	assert.False(t, report(&stdout, []interceptor.CaptureRecord{record}, []replayResult{result}))
+	assert.Equal(t, `record 1 (GET http://localhost/): 2 responses, recorded 3
+replayed 1 records: 0 matched, 0 status mismatches, 0 payload mismatches, 0 errors, 1 response count mismatches
+`, stdout.String())
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/gojek/fiber/config"
+)
+
+func schema(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fiber schema")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	data, err := config.JSONSchema()
+	if err != nil {
+		fmt.Fprintf(stderr, "fiber: %v\n", err)
+		return exitFailure
+	}
+	fmt.Fprintln(stdout, string(data))
+	return exitOK
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/gojek/fiber/admin"
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/extras/interceptor"
+	fibergrpc "github.com/gojek/fiber/grpc"
+	fiberhttp "github.com/gojek/fiber/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// envPrefix is the prefix of the environment variables, that the flags of the serve command are read from
+const envPrefix = "FIBER_"
+
+// serverOptions are the options of the serve command
+type serverOptions struct {
+	configPath string
+
+	httpAddr   string
+	grpcAddr   string
+	healthAddr string
+	adminAddr  string
+	// adminPublic allows serving the admin API, that has no authentication, on a non-loopback address
+	adminPublic bool
+
+	timeout        time.Duration
+	drainTimeout   time.Duration
+	reloadInterval time.Duration
+	h2c            bool
+
+	tlsCert     string
+	tlsKey      string
+	tlsClientCA string
+
+	logLevel  string
+	logFormat string
+}
+
+func serve(args []string, _ io.Writer, stderr io.Writer) int {
+	var options serverOptions
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&options.configPath, "config", "", "path to the fiber config")
+	flags.StringVar(&options.httpAddr, "http-addr", ":8080", "address to serve http requests on, disabled if empty")
+	flags.StringVar(&options.grpcAddr, "grpc-addr", "", "address to serve grpc requests on, disabled if empty")
+	flags.StringVar(&options.healthAddr, "health-addr", ":8081",
+		"address to serve the /healthz, /readyz and /metrics endpoints on, disabled if empty")
+	flags.StringVar(&options.adminAddr, "admin-addr", "",
+		"loopback address to serve the admin API on, to inspect and change the live routing graph, disabled if empty")
+	flags.BoolVar(&options.adminPublic, "admin-public", false,
+		"allow serving the admin API, that has no authentication, on a non-loopback admin-addr")
+	flags.DurationVar(&options.timeout, "timeout", 20*time.Second,
+		"timeout of the requests, that don't have a deadline set by the client")
+	flags.DurationVar(&options.drainTimeout, "drain-timeout", 30*time.Second,
+		"time to wait for the requests in flight to complete on shutdown")
+	flags.DurationVar(&options.reloadInterval, "reload-interval", 0,
+		"interval to check the config for changes at, the config is only reloaded on SIGHUP if zero")
+	flags.BoolVar(&options.h2c, "h2c", false, "accept cleartext HTTP/2 (h2c) requests on the http address")
+	flags.StringVar(&options.tlsCert, "tls-cert", "", "PEM encoded server certificate, enables TLS")
+	flags.StringVar(&options.tlsKey, "tls-key", "", "PEM encoded server key")
+	flags.StringVar(&options.tlsClientCA, "tls-client-ca", "",
+		"PEM encoded CA bundle to verify client certificates with, enables mTLS")
+	flags.StringVar(&options.logLevel, "log-level", "info", "log level: debug, info, warn or error")
+	flags.StringVar(&options.logFormat, "log-format", "json", "log format: json or console")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fiber serve [flags] [<config>]")
+		fmt.Fprintf(stderr, "\nEvery flag can also be set with the %s<FLAG> environment variable, e.g. %sHTTP_ADDR\n\n",
+			envPrefix, envPrefix)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if err := flagsFromEnv(flags); err != nil {
+		fmt.Fprintf(stderr, "fiber: %v\n", err)
+		return exitUsage
+	}
+	if flags.NArg() > 1 || (flags.NArg() == 1 && options.configPath != "") {
+		flags.Usage()
+		return exitUsage
+	}
+	if flags.NArg() == 1 {
+		options.configPath = flags.Arg(0)
+	}
+
+	logger, err := newLogger(stderr, options.logLevel, options.logFormat)
+	if err != nil {
+		fmt.Fprintf(stderr, "fiber: %v\n", err)
+		return exitUsage
+	}
+	defer func() { _ = logger.Sync() }()
+
+	s, err := newServer(options, logger)
+	if err == nil {
+		err = s.listen()
+	}
+	if err != nil {
+		logger.Errorw("unable to start the server", "error", err)
+		return exitFailure
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	defer signal.Stop(reload)
+	go func() {
+		for range reload {
+			// the result is logged by the component's OnReload callback
+			_ = s.component.Reload()
+		}
+	}()
+
+	if err := s.serve(ctx); err != nil {
+		logger.Errorw("server failed", "error", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// flagsFromEnv sets the flags, that are not set on the command line, from the environment
+// variables, named after the flags: e.g. -http-addr is read from FIBER_HTTP_ADDR
+func flagsFromEnv(flags *flag.FlagSet) error {
+	set := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+	flags.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || err != nil {
+			return
+		}
+		name := envPrefix + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if value, ok := os.LookupEnv(name); ok {
+			if setErr := flags.Set(f.Name, value); setErr != nil {
+				err = fmt.Errorf("invalid value %q of %s: %w", value, name, setErr)
+			}
+		}
+	})
+	return err
+}
+
+func newLogger(w io.Writer, level string, format string) (*zap.SugaredLogger, error) {
+	logLevel, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	switch format {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("unknown log format: %s", format)
+	}
+	return zap.New(zapcore.NewCore(encoder, zapcore.Lock(zapcore.AddSync(w)), logLevel)).Sugar(), nil
+}
+
+// server serves the fiber component, created from the config, over http and grpc
+type server struct {
+	options   serverOptions
+	logger    *zap.SugaredLogger
+	component *config.ReloadableComponent
+	tlsConfig *tls.Config
+
+	httpServer   *http.Server
+	grpcServer   *grpc.Server
+	healthServer *http.Server
+	adminServer  *http.Server
+	grpcHealth   *health.Server
+
+	httpListener   net.Listener
+	grpcListener   net.Listener
+	healthListener net.Listener
+	adminListener  net.Listener
+
+	// ready is set, when the server accepts requests, and unset, when it starts draining them
+	ready atomic.Bool
+}
+
+func newServer(options serverOptions, logger *zap.SugaredLogger) (*server, error) {
+	if options.configPath == "" {
+		return nil, errors.New("config is required")
+	}
+	if options.httpAddr == "" && options.grpcAddr == "" {
+		return nil, errors.New("either http or grpc address is required")
+	}
+	s := &server{options: options, logger: logger}
+
+	if options.tlsCert != "" || options.tlsKey != "" {
+		tlsConfig, err := serverTLSConfig(options.tlsCert, options.tlsKey, options.tlsClientCA)
+		if err != nil {
+			return nil, err
+		}
+		s.tlsConfig = tlsConfig
+	} else if options.tlsClientCA != "" {
+		return nil, errors.New("tls-client-ca requires tls-cert and tls-key")
+	}
+	if options.adminAddr != "" && !options.adminPublic && !isLoopbackAddr(options.adminAddr) {
+		return nil, fmt.Errorf(
+			"admin-addr %s is not a loopback address, set admin-public to serve the admin API on it", options.adminAddr)
+	}
+
+	component, err := config.NewReloadableComponent(options.configPath, config.ReloadOptions{
+		WatchInterval: options.reloadInterval,
+		OnReload: func(err error) {
+			if err != nil {
+				logger.Errorw("unable to reload the config", "config", options.configPath, "error", err)
+			} else {
+				logger.Infow("config reloaded", "config", options.configPath)
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.component = component
+
+	if options.httpAddr != "" {
+		var handler http.Handler = fiberhttp.NewHandler(component, fiberhttp.Options{Timeout: options.timeout})
+		if options.h2c {
+			handler = fiberhttp.NewH2CHandler(handler, fiberhttp.H2COptions{})
+		}
+		s.httpServer = &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+		if s.tlsConfig != nil {
+			s.httpServer.TLSConfig = s.tlsConfig.Clone()
+		}
+	}
+	if options.grpcAddr != "" {
+		handler := fibergrpc.NewHandler(component, fibergrpc.Options{Timeout: options.timeout})
+		serverOptions := handler.ServerOptions()
+		if s.tlsConfig != nil {
+			serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(s.tlsConfig.Clone())))
+		}
+		s.grpcServer = grpc.NewServer(serverOptions...)
+		// the health service is registered, so it's not dispatched on the fiber component
+		s.grpcHealth = health.NewServer()
+		healthpb.RegisterHealthServer(s.grpcServer, s.grpcHealth)
+	}
+	if options.healthAddr != "" {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})
+		mux.HandleFunc("/readyz", func(w http.ResponseWriter, _ *http.Request) {
+			if !s.ready.Load() {
+				http.Error(w, "not ready", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		})
+		// the metrics of the fiber.PrometheusInterceptor(s), declared in the config
+		mux.Handle("/metrics", interceptor.NewPrometheusHandler(prometheus.DefaultGatherer))
+		s.healthServer = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	}
+	if options.adminAddr != "" {
+		s.adminServer = &http.Server{Handler: admin.NewHandler(component), ReadHeaderTimeout: 10 * time.Second}
+	}
+	return s, nil
+}
+
+// isLoopbackAddr returns true, if the host of the address is localhost or a loopback IP,
+// e.g. 127.0.0.1:8082, but not :8082, that listens on all the interfaces
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func serverTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tls client ca: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("invalid tls client ca: no certificates found in %s", clientCAFile)
+		}
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// listen opens the listeners of the server. The component is closed, if it fails
+func (s *server) listen() (err error) {
+	defer func() {
+		if err != nil {
+			s.closeListeners()
+			_ = s.component.Close()
+		}
+	}()
+
+	if s.healthServer != nil {
+		if s.healthListener, err = net.Listen("tcp", s.options.healthAddr); err != nil {
+			return err
+		}
+	}
+	if s.adminServer != nil {
+		if s.adminListener, err = net.Listen("tcp", s.options.adminAddr); err != nil {
+			return err
+		}
+	}
+	if s.httpServer != nil {
+		if s.httpListener, err = net.Listen("tcp", s.options.httpAddr); err != nil {
+			return err
+		}
+	}
+	if s.grpcServer != nil {
+		if s.grpcListener, err = net.Listen("tcp", s.options.grpcAddr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *server) closeListeners() {
+	for _, listener := range []net.Listener{s.healthListener, s.adminListener, s.httpListener, s.grpcListener} {
+		if listener != nil {
+			_ = listener.Close()
+		}
+	}
+}
+
+// serve serves the requests on the listeners (see listen), until the context is done or one of the servers
+// fails. The requests in flight are then drained for up to the drain timeout, before the server is stopped
+func (s *server) serve(ctx context.Context) error {
+	errs := make(chan error, 4)
+	var wg sync.WaitGroup
+	start := func(name string, addr net.Addr, serveFn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.logger.Infow("serving "+name, "address", addr.String())
+			if err := serveFn(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("%s server: %w", name, err)
+			}
+		}()
+	}
+
+	if s.healthServer != nil {
+		start("health", s.healthListener.Addr(), func() error { return s.healthServer.Serve(s.healthListener) })
+	}
+	if s.adminServer != nil {
+		start("admin", s.adminListener.Addr(), func() error { return s.adminServer.Serve(s.adminListener) })
+	}
+	if s.httpServer != nil {
+		start("http", s.httpListener.Addr(), func() error {
+			if s.tlsConfig != nil {
+				return s.httpServer.ServeTLS(s.httpListener, "", "")
+			}
+			return s.httpServer.Serve(s.httpListener)
+		})
+	}
+	if s.grpcServer != nil {
+		s.grpcHealth.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		start("grpc", s.grpcListener.Addr(), func() error { return s.grpcServer.Serve(s.grpcListener) })
+	}
+	s.ready.Store(true)
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errs:
+	}
+
+	s.shutdown()
+	wg.Wait()
+	return err
+}
+
+// shutdown stops accepting new requests and waits for the requests in flight to complete,
+// for up to the drain timeout. The component is closed afterwards
+func (s *server) shutdown() {
+	s.ready.Store(false)
+	s.logger.Infow("draining requests", "timeout", s.options.drainTimeout.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.options.drainTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	if s.httpServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.httpServer.Shutdown(ctx); err != nil {
+				s.logger.Warnw("http requests were not drained", "error", err)
+				_ = s.httpServer.Close()
+			}
+		}()
+	}
+	if s.grpcServer != nil {
+		s.grpcHealth.Shutdown()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stopped := make(chan struct{})
+			go func() {
+				s.grpcServer.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+				s.logger.Warnw("grpc requests were not drained", "error", ctx.Err())
+				s.grpcServer.Stop()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.healthServer != nil {
+		_ = s.healthServer.Close()
+	}
+	if s.adminServer != nil {
+		_ = s.adminServer.Close()
+	}
+	if err := s.component.Close(); err != nil {
+		s.logger.Warnw("unable to close the component", "error", err)
+	}
+	s.logger.Infow("server stopped")
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestServer(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("route_a"))
+	}))
+	defer backend.Close()
+
+	configPath := writeConfig(t, "fiber.yaml", `
+type: PROXY
+id: route_a
+endpoint: `+backend.URL+`
+interceptors:
+  - type: fiber.PrometheusInterceptor`)
+
+	var logs bytes.Buffer
+	logger, err := newLogger(&logs, "info", "json")
+	require.NoError(t, err)
+
+	s, err := newServer(serverOptions{
+		configPath:   configPath,
+		httpAddr:     "127.0.0.1:0",
+		grpcAddr:     "127.0.0.1:0",
+		healthAddr:   "127.0.0.1:0",
+		adminAddr:    "127.0.0.1:0",
+		timeout:      time.Second,
+		drainTimeout: time.Second,
+	}, logger)
+	require.NoError(t, err)
+	require.NoError(t, s.listen())
+
+	healthURL := "http://" + s.healthListener.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() {
+		served <- s.serve(ctx)
+	}()
+
+	require.Eventually(t, s.ready.Load, time.Second, 10*time.Millisecond)
+	code, _ := get(t, healthURL+"/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = get(t, healthURL+"/readyz")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body := get(t, "http://"+s.httpListener.Addr().String()+"/")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "route_a", body)
+
+	code, body = get(t, "http://"+s.adminListener.Addr().String()+"/graph")
+	assert.Equal(t, http.StatusOK, code)
+	assert.JSONEq(t, `{"id": "route_a", "kind": "Caller", "type": "PROXY",
+		"interceptors": ["fiber.PrometheusInterceptor"]}`, body)
+
+	assert.Eventually(t, func() bool {
+		code, body := get(t, healthURL+"/metrics")
+		return code == http.StatusOK && strings.Contains(body,
+			`fiber_requests_total{code="200",component="route_a",kind="Caller",protocol="HTTP",success="true"} 1`)
+	}, time.Second, 10*time.Millisecond)
+
+	conn, err := grpc.Dial(s.grpcListener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, health.Status)
+
+	cancel()
+	select {
+	case err := <-served:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server is not stopped")
+	}
+	assert.False(t, s.ready.Load())
+	assert.Contains(t, logs.String(), `"msg":"server stopped"`)
+}
+
+func TestFlagsFromEnv(t *testing.T) {
+	t.Setenv("FIBER_HTTP_ADDR", ":9090")
+	t.Setenv("FIBER_DRAIN_TIMEOUT", "5s")
+	t.Setenv("FIBER_GRPC_ADDR", ":50051")
+
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	httpAddr := flags.String("http-addr", ":8080", "")
+	grpcAddr := flags.String("grpc-addr", "", "")
+	drainTimeout := flags.Duration("drain-timeout", time.Second, "")
+	require.NoError(t, flags.Parse([]string{"-grpc-addr", ":50052"}))
+	require.NoError(t, flagsFromEnv(flags))
+
+	assert.Equal(t, ":9090", *httpAddr)
+	// the flags, set on the command line, take precedence
+	assert.Equal(t, ":50052", *grpcAddr)
+	assert.Equal(t, 5*time.Second, *drainTimeout)
+
+	t.Setenv("FIBER_DRAIN_TIMEOUT", "forever")
+	flags = flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Duration("drain-timeout", time.Second, "")
+	assert.EqualError(t, flagsFromEnv(flags),
+		`invalid value "forever" of FIBER_DRAIN_TIMEOUT: parse error`)
+}
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := map[string]bool{
+		"127.0.0.1:8082": true,
+		"localhost:8082": true,
+		"[::1]:8082":     true,
+		":8082":          false,
+		"0.0.0.0:8082":   false,
+		"10.0.0.1:8082":  false,
+		"localhost":      false,
+	}
+	for addr, expected := range tests {
+		assert.Equal(t, expected, isLoopbackAddr(addr), addr)
+	}
+}
+
+func TestRun_Serve(t *testing.T) {
+	configPath := writeConfig(t, "fiber.yaml", routerConfig)
+
+	tests := []struct {
+		name         string
+		args         []string
+		expectedCode int
+	}{
+		{
+			name:         "several configs",
+			args:         []string{"serve", configPath, configPath},
+			expectedCode: exitUsage,
+		},
+		{
+			name:         "unknown log format",
+			args:         []string{"serve", "-log-format", "xml", configPath},
+			expectedCode: exitUsage,
+		},
+		{
+			name:         "missing config",
+			args:         []string{"serve", "-http-addr", "127.0.0.1:0", "-health-addr", ""},
+			expectedCode: exitFailure,
+		},
+		{
+			name:         "no addresses",
+			args:         []string{"serve", "-http-addr", "", configPath},
+			expectedCode: exitFailure,
+		},
+		{
+			name:         "public admin address",
+			args:         []string{"serve", "-http-addr", "127.0.0.1:0", "-admin-addr", ":0", configPath},
+			expectedCode: exitFailure,
+		},
+		{
+			name:         "invalid tls certificate",
+			args:         []string{"serve", "-tls-cert", "server.crt", "-tls-key", "server.key", configPath},
+			expectedCode: exitFailure,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			assert.Equal(t, tt.expectedCode, run(tt.args, &stdout, &stderr), stderr.String())
+		})
+	}
+}
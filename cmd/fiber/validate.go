@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/gojek/fiber/config"
+)
+
+func validate(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: fiber validate <config>...")
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	code := exitOK
+	for _, configPath := range flags.Args() {
+		problems, err := config.ValidateFile(configPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", configPath, err)
+			code = exitFailure
+			continue
+		}
+		if len(problems) == 0 {
+			fmt.Fprintf(stdout, "%s: OK\n", configPath)
+			continue
+		}
+		for _, problem := range problems {
+			fmt.Fprintln(stdout, problem)
+		}
+		code = exitFailure
+	}
+	return code
+}
@@ -2,6 +2,8 @@ package fiber
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/gojek/fiber/util"
 )
@@ -13,7 +15,7 @@ type Combiner struct {
 	BaseComponent
 	FanOut
 
-	fanIn FanIn
+	fanIn atomic.Pointer[FanIn]
 }
 
 // NewCombiner is a factory for the Combiner type.
@@ -38,26 +40,65 @@ func (c *Combiner) Kind() ComponentKind {
 	return c.BaseComponent.kind
 }
 
-// WithFanIn is a Setter for the FanIn (aggregation strategy) on the given Combiner
+// WithFanIn is a Setter for the FanIn (aggregation strategy) on the given Combiner. The fan-in can be
+// replaced, while requests are dispatched: the requests in flight keep using the previous fan-in
 func (c *Combiner) WithFanIn(fanIn FanIn) *Combiner {
-	c.fanIn = fanIn
+	c.fanIn.Store(&fanIn)
 	return c
 }
 
+// getFanIn returns the current FanIn of the Combiner
+func (c *Combiner) getFanIn() FanIn {
+	if fanIn := c.fanIn.Load(); fanIn != nil {
+		return *fanIn
+	}
+	return nil
+}
+
+// DisableRoute drains the route with the given ID, see RouteController
+func (c *Combiner) DisableRoute(id string) error {
+	controller, ok := c.FanOut.(RouteController)
+	if !ok {
+		return fmt.Errorf("routes of %s can't be disabled", c.ID())
+	}
+	return controller.DisableRoute(id)
+}
+
+// EnableRoute enables the route with the given ID, see RouteController
+func (c *Combiner) EnableRoute(id string) error {
+	controller, ok := c.FanOut.(RouteController)
+	if !ok {
+		return fmt.Errorf("routes of %s can't be enabled", c.ID())
+	}
+	return controller.EnableRoute(id)
+}
+
+// DisabledRoutes returns the disabled routes of the Combiner, see RouteController
+func (c *Combiner) DisabledRoutes() map[string]Component {
+	if controller, ok := c.FanOut.(RouteController); ok {
+		return controller.DisabledRoutes()
+	}
+	return map[string]Component{}
+}
+
 // Dispatch method on the Combiner will ask its embedded dispatcher to simultaneously
 // dispatch the incoming request by all of its nested components. After that, Combiner's FanIn
 // listens to responseQueue and aggregate them into a single response, that is being sent to output
 func (c *Combiner) Dispatch(ctx context.Context, req Request) ResponseQueue {
-	ctx = c.beforeDispatch(ctx, req)
+	ctx, resp := c.beforeDispatch(ctx, req)
+	if resp != nil {
+		return c.shortCircuit(ctx, req, resp)
+	}
 	out := make(chan Response, 1)
 
 	queue := NewResponseQueue(out, 1)
 	defer c.afterDispatch(ctx, req, queue)
+	ctx, out = c.withTimeout(ctx, req, out)
 
 	go func() {
 		defer c.afterCompletion(ctx, req, queue)
 
-		out <- c.fanIn.Aggregate(ctx, req, c.FanOut.Dispatch(ctx, req))
+		out <- c.getFanIn().Aggregate(ctx, req, c.FanOut.Dispatch(ctx, req))
 		close(out)
 	}()
 
@@ -72,3 +113,8 @@ func (c *Combiner) AddInterceptor(recursive bool, interceptor ...Interceptor) {
 	}
 	c.BaseComponent.AddInterceptor(recursive, interceptor...)
 }
+
+// Close releases the resources held by the nested components of the Combiner
+func (c *Combiner) Close() error {
+	return closeComponent(c.FanOut)
+}
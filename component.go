@@ -1,6 +1,13 @@
 package fiber
 
-import "context"
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/gojek/fiber/errors"
+)
 
 // ComponentKind can be used to define the types of Fiber components
 // that support the Component interface
@@ -44,6 +51,8 @@ type BaseComponent struct {
 	kind ComponentKind
 
 	interceptors []Interceptor
+
+	timeout atomic.Int64
 }
 
 // ID is the getter for the BaseComponent's unique ID
@@ -56,14 +65,37 @@ func (c *BaseComponent) Kind() ComponentKind {
 	return c.kind
 }
 
-func (c *BaseComponent) beforeDispatch(ctx context.Context, req Request) context.Context {
-	// Add component id and type to the context
+// beforeDispatch runs the interceptors before the dispatch of the request. The response is returned,
+// if one of the interceptors (see ShortCircuitInterceptor) answers the request, so the component must
+// respond with it, without dispatching the request (see shortCircuit)
+func (c *BaseComponent) beforeDispatch(ctx context.Context, req Request) (context.Context, Response) {
+	// Add component id, type and path to the context
 	ctx = context.WithValue(ctx, CtxComponentIDKey, c.ID())
 	ctx = context.WithValue(ctx, CtxComponentKindKey, c.Kind())
+	path := c.ID()
+	if parent, ok := ctx.Value(CtxComponentPathKey).(string); ok {
+		path = parent + "/" + path
+	}
+	ctx = context.WithValue(ctx, CtxComponentPathKey, path)
+	var resp Response
 	for _, i := range c.interceptors {
-		ctx = i.BeforeDispatch(ctx, req)
+		// once the request is answered, the other interceptors only see it dispatched as usual
+		if shortCircuit, ok := i.(ShortCircuitInterceptor); ok && resp == nil {
+			ctx, resp = shortCircuit.ShortCircuit(ctx, req)
+		} else {
+			ctx = i.BeforeDispatch(ctx, req)
+		}
 	}
-	return ctx
+	return ctx, resp
+}
+
+// shortCircuit answers the request with the response of the interceptor, instead of dispatching it
+// by the component. The response is passed to the interceptors, as the response of the component
+func (c *BaseComponent) shortCircuit(ctx context.Context, req Request, resp Response) ResponseQueue {
+	queue := NewResponseQueueFromResponses(resp)
+	c.afterDispatch(ctx, req, queue)
+	c.afterCompletion(ctx, req, queue)
+	return queue
 }
 
 func (c *BaseComponent) afterDispatch(ctx context.Context, req Request, queue ResponseQueue) {
@@ -78,11 +110,82 @@ func (c *BaseComponent) afterCompletion(ctx context.Context, req Request, queue
 	}
 }
 
+// withTimeout applies the timeout of the component to its dispatch. The returned context has the deadline
+// of the timeout, and the responses, sent into the returned channel, are forwarded into out, which is closed
+// once the returned channel is closed. If the timeout is exceeded first, the timeout error is sent into out
+// instead, and the remaining responses are discarded. The context and out are returned as they are, if the
+// component has no timeout
+func (c *BaseComponent) withTimeout(ctx context.Context, req Request, out chan Response) (context.Context, chan Response) {
+	timeout := c.Timeout()
+	if timeout <= 0 {
+		return ctx, out
+	}
+	parent := ctx
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	in := make(chan Response, cap(out))
+
+	go func() {
+		defer cancel()
+		defer close(out)
+		for {
+			select {
+			case resp, ok := <-in:
+				if !ok {
+					return
+				}
+				out <- resp
+			case <-ctx.Done():
+				if parent.Err() != nil {
+					// the request itself is done, the dispatch responds to it as it does without the timeout
+					for resp := range in {
+						out <- resp
+					}
+					return
+				}
+				out <- NewErrorResponse(errors.ErrRequestTimeout(req.Protocol()))
+				go func() {
+					for range in {
+					}
+				}()
+				return
+			}
+		}
+	}()
+	return ctx, in
+}
+
+// SetTimeout sets the timeout of the component's dispatches: the routes of the component (or its Dispatcher)
+// receive the context with the deadline of the timeout, and the timeout error of the request's protocol is
+// returned, once it's exceeded. The timeout applies to every request dispatched after it's set. There's
+// no timeout, if it's not positive
+func (c *BaseComponent) SetTimeout(timeout time.Duration) {
+	c.timeout.Store(int64(timeout))
+}
+
+// Timeout returns the timeout of the component's dispatches, see SetTimeout
+func (c *BaseComponent) Timeout() time.Duration {
+	return time.Duration(c.timeout.Load())
+}
+
 // AddInterceptor can be used to add one or more interceptors to the BaseComponent
 func (c *BaseComponent) AddInterceptor(recursive bool, interceptors ...Interceptor) {
 	c.interceptors = append(c.interceptors, interceptors...)
 }
 
+// Interceptors returns the interceptors, added to the BaseComponent
+func (c *BaseComponent) Interceptors() []Interceptor {
+	return c.interceptors
+}
+
+// closeComponent releases the resources held by the component, such as network connections,
+// if the component implements io.Closer
+func closeComponent(component Component) error {
+	if closer, ok := component.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func NewBaseComponent(id string, kind ComponentKind) *BaseComponent {
 	return &BaseComponent{
 		id:   id,
@@ -0,0 +1,81 @@
+package fiber_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/extras"
+	"github.com/gojek/fiber/fibertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestBaseComponent_Timeout(t *testing.T) {
+	primary := fibertest.NewComponent("route_a", fibertest.OK("a").After(time.Second))
+	primary.SetTimeout(50 * time.Millisecond)
+	fallback := fibertest.NewComponent("route_b", fibertest.OK("b"))
+
+	router := fiber.NewLazyRouter("router")
+	router.SetRoutes(fibertest.Routes(primary, fallback))
+	router.SetStrategy(&orderedRoutingStrategy{})
+	recorder := fibertest.NewRecorder()
+	router.AddInterceptor(true, recorder)
+
+	// the primary route gets 50ms, the fallback gets the rest
+	start := time.Now()
+	resp := fibertest.Dispatch(t, router, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "b")
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the timeout error is seen by the interceptors of the timed out route
+	recorder.Wait()
+	dispatches := recorder.Dispatches()
+	require.Len(t, dispatches, 3)
+	assert.Equal(t, "router/route_a", dispatches[1].ComponentPath)
+	require.Len(t, dispatches[1].Responses, 1)
+	assert.Equal(t, http.StatusRequestTimeout, dispatches[1].Responses[0].StatusCode())
+
+	// the timeout error has the protocol of the request
+	resp = fibertest.Dispatch(t, primary, fibertest.NewGRPCRequest("/test.Service/Echo", nil, nil))
+	require.NotNil(t, resp)
+	assert.Equal(t, int(codes.DeadlineExceeded), resp.StatusCode())
+}
+
+func TestBaseComponent_TimeoutOfMultiRouteComponents(t *testing.T) {
+	routeA := fibertest.NewComponent("route_a", fibertest.OK("a").After(time.Second))
+	routeB := fibertest.NewComponent("route_b", fibertest.OK("b").After(time.Second))
+
+	combiner := fiber.NewCombiner("combiner")
+	combiner.SetRoutes(fibertest.Routes(routeA, routeB))
+	combiner.WithFanIn(&extras.FastestResponseFanIn{})
+	combiner.SetTimeout(50 * time.Millisecond)
+	assert.Equal(t, 50*time.Millisecond, combiner.Timeout())
+
+	start := time.Now()
+	resp := fibertest.Dispatch(t, combiner, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode())
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the timeout is removed
+	combiner.SetTimeout(0)
+	combiner.SetRoutes(fibertest.Routes(fibertest.NewComponent("route_a", fibertest.OK("a").After(100*time.Millisecond))))
+	resp = fibertest.Dispatch(t, combiner, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "a")
+}
+
+func TestBaseComponent_TimeoutOfRequest(t *testing.T) {
+	route := fibertest.NewComponent("route_a", fibertest.OK("a").After(time.Second))
+	route.SetTimeout(time.Second)
+
+	// the request is done before the timeout of the component, the component responds as it does without it
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	resp, ok := <-route.Dispatch(ctx, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", "")).Iter()
+	require.True(t, ok)
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode())
+}
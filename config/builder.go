@@ -0,0 +1,383 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/gojek/fiber"
+	fiberHTTP "github.com/gojek/fiber/http"
+)
+
+// builder creates the components from their configs (see InitComponentFromConfig). In the dry run
+// (see Validate), the configs go through the same checks, but the problems are collected with the
+// paths of the config nodes, instead of the first one being returned, no grpc dispatchers are
+// created, so no connections are made to the backends, and the interceptors, that acquire resources,
+// are only validated (see types.PropertiesValidator)
+type builder struct {
+	dryRun   bool
+	problems []Problem
+
+	// transportConfigs are the shared transports, defined at the root of the config, by their names.
+	// The transports are created once, when they are first referenced
+	transportConfigs map[string]*TransportConfig
+	transports       map[string]*http.Transport
+
+	// closers are the interceptors, declared in the config, that hold resources (such as open files),
+	// which are released, when the routing graph is no longer used (see closeInterceptors)
+	closers []io.Closer
+}
+
+func newBuilder(dryRun bool) *builder {
+	return &builder{
+		dryRun:           dryRun,
+		transportConfigs: make(map[string]*TransportConfig),
+		transports:       make(map[string]*http.Transport),
+	}
+}
+
+// fieldError is the error in the field of the component config, such as "strategy.properties", so
+// that Validate reports it with the path of the field. The empty field is the component itself, the
+// error is reported without the context (such as the component ID), that it's wrapped with
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// report records the problem of the config node at the path, or of its field (see fieldError)
+func (b *builder) report(path string, err error) {
+	var fieldErr *fieldError
+	for errors.As(err, &fieldErr) {
+		if fieldErr.field != "" {
+			path = path + "." + fieldErr.field
+		}
+		err = fieldErr.err
+	}
+	b.problems = append(b.problems, Problem{Path: path, Message: err.Error()})
+}
+
+// fail returns the error of the config node at the path. In the dry run, the error is reported
+// instead, and nil is returned, so that the checks go on
+func (b *builder) fail(path string, err error) error {
+	if !b.dryRun {
+		return err
+	}
+	b.report(path, err)
+	return nil
+}
+
+// lint reports the problem, that doesn't prevent the component from being created, in the dry run
+func (b *builder) lint(path string, format string, args ...interface{}) {
+	if b.dryRun {
+		b.problems = append(b.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// closeInterceptors releases the resources of the interceptors, that were created from the config
+func (b *builder) closeInterceptors() {
+	for _, closer := range b.closers {
+		_ = closer.Close()
+	}
+	b.closers = nil
+}
+
+// build creates the root component from the config document. If it fails, the interceptors,
+// that were already created, are closed
+func (b *builder) build(data []byte) (fiber.Component, error) {
+	component, err := b.buildComponent(data)
+	if err != nil {
+		b.closeInterceptors()
+	}
+	return component, err
+}
+
+func (b *builder) buildComponent(data []byte) (fiber.Component, error) {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		cfg = newInvalidConfig(data, err)
+	}
+	if !b.dryRun {
+		// the parse errors are returned before any of the components is created
+		if err := parseError(cfg); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.sharedTransports(data); err != nil {
+		return nil, err
+	}
+	return b.component("$", cfg)
+}
+
+// sharedTransports parses and checks the shared transports, defined at the root of the config
+func (b *builder) sharedTransports(data []byte) error {
+	var root map[string]json.RawMessage
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		if b.dryRun {
+			// the document is not a mapping, which is reported as the problem of the root component
+			return nil
+		}
+		return err
+	}
+	if root[transportsKey] == nil {
+		return nil
+	}
+
+	path := "$." + transportsKey
+	var transports map[string]json.RawMessage
+	if err := json.Unmarshal(root[transportsKey], &transports); err != nil {
+		return b.fail(path, errors.New("transports must be a mapping"))
+	}
+	for _, name := range sortedKeys(transports) {
+		cfg := new(TransportConfig)
+		err := json.Unmarshal(transports[name], cfg)
+		if err == nil && cfg.Name != "" {
+			err = &fieldError{err: errors.New("shared transport must be a mapping")}
+		} else if err == nil {
+			_, err = cfg.options()
+		}
+		if err != nil {
+			if err := b.fail(path+"."+name, fmt.Errorf("transport %s: %w", name, err)); err != nil {
+				return err
+			}
+			if cfg.Name != "" {
+				continue
+			}
+			// the shared transport with the problems is replaced with the default one in the dry run,
+			// so that the proxies, which reference it, are still checked
+			b.transports[name] = fiberHTTP.NewTransport(nil, fiberHTTP.TransportOptions{})
+		}
+		b.transportConfigs[name] = cfg
+	}
+	return nil
+}
+
+// sharedTransport returns the shared transport, that the proxy references, creating it on the first reference
+func (b *builder) sharedTransport(c *ProxyConfig) (*http.Transport, error) {
+	name := c.Transport.Name
+	transportConfig, exist := b.transportConfigs[name]
+	if !exist {
+		return nil, &fieldError{field: "transport", err: fmt.Errorf("unknown transport: %s", name)}
+	}
+	if c.HTTP2 != nil || c.TLS != nil {
+		return nil, fmt.Errorf("proxy %s: %w", c.ID, &fieldError{
+			err: fmt.Errorf("http2 and tls can't be set on proxies with the shared transport %s", name),
+		})
+	}
+	if b.transports[name] == nil {
+		transport, err := transportConfig.transport(nil)
+		if err != nil {
+			return nil, fmt.Errorf("transport %s: %w", name, err)
+		}
+		b.transports[name] = transport
+	}
+	// the proxy is described with the settings of the shared transport
+	c.Transport = transportConfig
+	return b.transports[name], nil
+}
+
+// component creates the component from its config at the path, with the timeout and the interceptors declared
+// on it. In the dry run, the component with the problems is replaced with the placeholder
+func (b *builder) component(path string, cfg Config) (fiber.Component, error) {
+	if _, invalid := cfg.(*invalidConfig); !invalid && cfg.componentConfig().ID == "" {
+		b.lint(path+".id", "missing component id")
+	}
+	component, err := b.initComponent(path, cfg)
+	if err != nil {
+		if err := b.fail(path, err); err != nil {
+			return nil, err
+		}
+		return newPlaceholder(path, cfg), nil
+	}
+	return component, nil
+}
+
+func (b *builder) initComponent(path string, cfg Config) (fiber.Component, error) {
+	component, err := cfg.initComponent(b, path)
+	if err != nil {
+		return nil, err
+	}
+	if timeout, field := cfg.timeout(); timeout != 0 {
+		timed, ok := component.(interface{ SetTimeout(time.Duration) })
+		if timeout < 0 {
+			err = fmt.Errorf("%s must not be negative", field)
+		} else if !ok {
+			err = fmt.Errorf("%s is not supported", field)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", component.ID(), &fieldError{field: field, err: err})
+		}
+		timed.SetTimeout(time.Duration(timeout))
+	}
+
+	failed := false
+	for idx, interceptorConfig := range cfg.componentConfig().Interceptors {
+		var interceptor fiber.Interceptor
+		var err error
+		if b.dryRun {
+			interceptor, err = interceptorConfig.validate()
+		} else {
+			interceptor, err = interceptorConfig.Interceptor()
+		}
+		if err != nil {
+			err = fmt.Errorf("component %s: %w", component.ID(), &fieldError{
+				field: fmt.Sprintf("interceptors[%d]", idx),
+				err:   err,
+			})
+			if err := b.fail(path, err); err != nil {
+				return nil, err
+			}
+			failed = true
+			continue
+		}
+		if interceptor == nil {
+			// the interceptor is only validated
+			continue
+		}
+		if closer, ok := interceptor.(io.Closer); ok {
+			b.closers = append(b.closers, closer)
+		}
+		component.AddInterceptor(interceptorConfig.Recursive, interceptor)
+	}
+	if failed {
+		// the problems are already reported
+		return newPlaceholder(path, cfg), nil
+	}
+	return component, nil
+}
+
+// routes creates the routes of the multi-route component at the path
+func (b *builder) routes(path string, routes Routes) (map[string]fiber.Component, error) {
+	components := make(map[string]fiber.Component)
+	for idx, routeConfig := range routes {
+		routePath := fmt.Sprintf("%s.routes[%d]", path, idx)
+		route, err := b.component(routePath, routeConfig)
+		if err != nil {
+			return nil, err
+		}
+		if _, exist := components[route.ID()]; exist {
+			err := &fieldError{field: "id", err: fmt.Errorf("duplicate route id: %s", route.ID())}
+			if err := b.fail(routePath, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		components[route.ID()] = route
+	}
+	return components, nil
+}
+
+// checkRouteReferences reports the routes, that the strategy refers to, but which don't exist, and the routes,
+// that are never selected by the strategy, if it's a RouteReferrer
+func (b *builder) checkRouteReferences(path string, strategy fiber.RoutingStrategy, routes Routes) {
+	referrer, ok := strategy.(RouteReferrer)
+	if !ok || !b.dryRun {
+		return
+	}
+
+	paths := make(map[string]string)
+	for idx, route := range routes {
+		if id := route.componentConfig().ID; id != "" {
+			if _, exist := paths[id]; !exist {
+				paths[id] = fmt.Sprintf("%s.routes[%d]", path, idx)
+			}
+		}
+	}
+	referenced := make(map[string]bool)
+	for _, id := range referrer.RouteIDs() {
+		referenced[id] = true
+		if _, exist := paths[id]; !exist {
+			b.lint(path+".strategy.properties", "unknown route: %s", id)
+		}
+	}
+	for _, id := range sortedKeys(paths) {
+		if !referenced[id] {
+			b.lint(paths[id], "route %s is unreachable: it's never selected by the strategy", id)
+		}
+	}
+}
+
+// invalidConfig is the config of the component, that couldn't be parsed. The parse error is returned,
+// when the component is created. Its routes are still checked in the dry run, if they could be parsed
+type invalidConfig struct {
+	ComponentConfig
+	Routes Routes
+
+	err error
+}
+
+func newInvalidConfig(data []byte, err error) *invalidConfig {
+	cfg := &invalidConfig{err: err}
+	var partial struct {
+		ID     string            `json:"id"`
+		Routes []json.RawMessage `json:"routes"`
+	}
+	if yaml.Unmarshal(data, &partial) == nil {
+		cfg.ID = partial.ID
+		cfg.Routes = make(Routes, len(partial.Routes))
+		for idx, route := range partial.Routes {
+			cfg.Routes[idx] = parseRoute(route)
+		}
+	}
+	return cfg
+}
+
+func (c *invalidConfig) initComponent(b *builder, path string) (fiber.Component, error) {
+	if b.dryRun {
+		if _, err := b.routes(path, c.Routes); err != nil {
+			return nil, err
+		}
+	}
+	if errors.As(c.err, new(*fieldError)) {
+		return nil, c.err
+	}
+	return nil, fmt.Errorf("invalid config: %w", c.err)
+}
+
+func (c *invalidConfig) routes() Routes {
+	return c.Routes
+}
+
+// parseError returns the error of the first config in the tree, that couldn't be parsed
+func parseError(cfg Config) error {
+	if invalid, ok := cfg.(*invalidConfig); ok {
+		return invalid.err
+	}
+	for _, route := range cfg.routes() {
+		if err := parseError(route); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeholder stands in for the component, that isn't created in the dry run: the component with problems,
+// or the grpc proxy, that would connect to the backend, so that the checks of its parents go on
+type placeholder struct {
+	*fiber.BaseComponent
+}
+
+func newPlaceholder(path string, cfg Config) *placeholder {
+	id := cfg.componentConfig().ID
+	if id == "" {
+		// the components without IDs are told apart by their paths
+		id = path
+	}
+	return &placeholder{BaseComponent: fiber.NewBaseComponent(id, fiber.ComponentKind(cfg.componentConfig().Type))}
+}
+
+func (p *placeholder) Dispatch(context.Context, fiber.Request) fiber.ResponseQueue {
+	return fiber.NewResponseQueueFromResponses(fiber.NewErrorResponse(errors.New("component is not created in the dry run")))
+}
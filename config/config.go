@@ -1,10 +1,12 @@
 package config
 
 import (
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 	"strings"
 	"time"
 
@@ -14,6 +16,9 @@ import (
 	fiberHTTP "github.com/gojek/fiber/http"
 	"github.com/gojek/fiber/protocol"
 	"github.com/gojek/fiber/types"
+	"github.com/gojek/fiber/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 )
 
 // DefaultClientTimeout defines the default http client timeout to use,
@@ -22,13 +27,88 @@ const DefaultClientTimeout = time.Second
 
 // Config is the base interface to initialise a network from a config file
 type Config interface {
-	initComponent() (fiber.Component, error)
+	initComponent(b *builder, path string) (fiber.Component, error)
+	componentConfig() *ComponentConfig
+	// timeout is the timeout of the component (see ComponentConfig.Timeout) and the name of its field
+	timeout() (Duration, string)
+	routes() Routes
 }
 
 // ComponentConfig is used to parse the base properties for a component
 type ComponentConfig struct {
-	ID   string `json:"id" required:"true"`
+	ID           string              `json:"id" required:"true"`
+	Type         string              `json:"type" required:"true"`
+	Interceptors []InterceptorConfig `json:"interceptors,omitempty"`
+	// Timeout, if set, limits the time, that the component takes to dispatch a request, see
+	// fiber.BaseComponent.SetTimeout. The timeout of a proxy is the timeout of its dispatcher, the proxy
+	// component's one is ProxyConfig.ComponentTimeout
+	Timeout Duration `json:"timeout,omitempty"`
+}
+
+func (c *ComponentConfig) componentConfig() *ComponentConfig {
+	return c
+}
+
+func (c *ComponentConfig) timeout() (Duration, string) {
+	return c.Timeout, "timeout"
+}
+
+// InterceptorConfig is used to parse the configuration for an Interceptor
+type InterceptorConfig struct {
 	Type string `json:"type" required:"true"`
+	// Recursive, if set, adds the interceptor to the component's routes as well
+	Recursive  bool            `json:"recursive,omitempty"`
+	Properties json.RawMessage `json:"properties" yaml:"properties,omitempty"`
+}
+
+// Interceptor takes a reference to an InterceptorConfig and creates an initialized Interceptor
+func (c *InterceptorConfig) Interceptor() (fiber.Interceptor, error) {
+	interceptor, err := c.interceptor()
+	if err != nil {
+		return nil, err
+	}
+	if err := interceptor.Initialize(c.Properties); err != nil {
+		return nil, c.propertiesError(err)
+	}
+	return interceptor, nil
+}
+
+// validate checks the type and the properties of the interceptor. The interceptors, that implement
+// types.PropertiesValidator, are not initialized, so that no resources are acquired
+func (c *InterceptorConfig) validate() (fiber.Interceptor, error) {
+	interceptor, err := c.interceptor()
+	if err != nil {
+		return nil, err
+	}
+	if validator, ok := interceptor.(types.PropertiesValidator); ok {
+		if err := validator.ValidateProperties(c.Properties); err != nil {
+			return nil, c.propertiesError(err)
+		}
+		return nil, nil
+	}
+	if err := interceptor.Initialize(c.Properties); err != nil {
+		return nil, c.propertiesError(err)
+	}
+	return interceptor, nil
+}
+
+// interceptor creates the uninitialized interceptor of the type
+func (c *InterceptorConfig) interceptor() (types.ConfigurableInterceptor, error) {
+	if c.Type == "" {
+		return nil, &fieldError{field: "type", err: errors.New("missing interceptor type")}
+	}
+	interceptor, err := types.InterceptorByName(c.Type)
+	if err != nil {
+		return nil, &fieldError{field: "type", err: err}
+	}
+	return interceptor, nil
+}
+
+func (c *InterceptorConfig) propertiesError(err error) error {
+	return fmt.Errorf("interceptor %s: %w", c.Type, &fieldError{
+		field: "properties",
+		err:   fmt.Errorf("invalid interceptor properties: %w", err),
+	})
 }
 
 // Routes represent a collection of configurations.
@@ -42,15 +122,21 @@ func (r Routes) UnmarshalJSON(b []byte) error {
 	}
 
 	for idx, route := range data {
-		cfg, err := parseConfig(route)
-		if err != nil {
-			return err
-		}
-		r[idx] = cfg
+		r[idx] = parseRoute(route)
 	}
 	return nil
 }
 
+// parseRoute parses the config of the route. The route, that can't be parsed, fails to be created
+// with the parse error (see invalidConfig), so that the problems of the other routes are still found
+func parseRoute(data []byte) Config {
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return newInvalidConfig(data, err)
+	}
+	return cfg
+}
+
 // Duration is an alias for time.Duration (required since time.Duration Unmarshal is not defined)
 type Duration time.Duration
 
@@ -68,15 +154,7 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 
 // Routes takes in an object of type Routes and returns a map of each route's ID and the route
 func (r Routes) Routes() (map[string]fiber.Component, error) {
-	routes := make(map[string]fiber.Component)
-	for _, routeConfig := range r {
-		route, err := routeConfig.initComponent()
-		if err != nil {
-			return nil, err
-		}
-		routes[route.ID()] = route
-	}
-	return routes, nil
+	return newBuilder(false).routes("$", r)
 }
 
 // MultiRouteConfig is used to parse the configuration for a MultiRouteComponent
@@ -85,6 +163,18 @@ type MultiRouteConfig struct {
 	Routes Routes `json:"routes" required:"true"`
 }
 
+func (c *MultiRouteConfig) routes() Routes {
+	return c.Routes
+}
+
+// multiRoutes creates the routes of the multi-route component, that must have at least one of them
+func (c *MultiRouteConfig) multiRoutes(b *builder, path string) (map[string]fiber.Component, error) {
+	if len(c.Routes) == 0 {
+		b.lint(path+".routes", "no routes defined")
+	}
+	return b.routes(path, c.Routes)
+}
+
 // RouterConfig is used to parse the configuration for a Router
 type RouterConfig struct {
 	MultiRouteConfig
@@ -103,7 +193,22 @@ func (c *StrategyConfig) Strategy() (fiber.RoutingStrategy, error) {
 	return strategy, err
 }
 
-func (c *RouterConfig) initComponent() (fiber.Component, error) {
+// initStrategy creates the RoutingStrategy and initializes it with the properties
+func (c *StrategyConfig) initStrategy() (fiber.RoutingStrategy, error) {
+	if c.Type == "" {
+		return nil, &fieldError{field: "type", err: errors.New("missing strategy type")}
+	}
+	strategy, err := c.Strategy()
+	if err != nil {
+		return nil, &fieldError{field: "type", err: err}
+	}
+	if err := strategy.Initialize(c.Properties); err != nil {
+		return nil, &fieldError{field: "properties", err: fmt.Errorf("invalid strategy properties: %w", err)}
+	}
+	return strategy, nil
+}
+
+func (c *RouterConfig) initComponent(b *builder, path string) (fiber.Component, error) {
 	var router fiber.Router
 	switch c.Type {
 	case "LAZY_ROUTER":
@@ -113,22 +218,17 @@ func (c *RouterConfig) initComponent() (fiber.Component, error) {
 	default:
 		return nil, fmt.Errorf("unknown router type: [%s]", c.Type)
 	}
-	routes, err := c.Routes.Routes()
+	routes, err := c.multiRoutes(b, path)
 	if err != nil {
 		return nil, err
 	}
 	router.SetRoutes(routes)
 
-	strategy, err := c.Strategy.Strategy()
+	strategy, err := c.Strategy.initStrategy()
 	if err != nil {
-		return nil, err
-	}
-
-	// Initialize strategy
-	err = strategy.Initialize(c.Strategy.Properties)
-	if err != nil {
-		return nil, err
+		return nil, &fieldError{field: "strategy", err: err}
 	}
+	b.checkRouteReferences(path, strategy, c.Routes)
 	// Set the strategy on the router
 	router.SetStrategy(strategy)
 	return router, nil
@@ -152,27 +252,150 @@ func (c *FanInConfig) FanIn() (fiber.FanIn, error) {
 	return fanIn, err
 }
 
-func (c *CombinerConfig) initComponent() (fiber.Component, error) {
+// initFanIn creates the FanIn and initializes it with the properties
+func (c *FanInConfig) initFanIn() (fiber.FanIn, error) {
+	if c.Type == "" {
+		return nil, &fieldError{field: "type", err: errors.New("missing fan-in type")}
+	}
+	fanIn, err := c.FanIn()
+	if err != nil {
+		return nil, &fieldError{field: "type", err: err}
+	}
+	if err := fanIn.Initialize(c.Properties); err != nil {
+		return nil, &fieldError{field: "properties", err: fmt.Errorf("invalid fan-in properties: %w", err)}
+	}
+	return fanIn, nil
+}
+
+func (c *CombinerConfig) initComponent(b *builder, path string) (fiber.Component, error) {
 	combiner := fiber.NewCombiner(c.ID)
 
-	routes, err := c.Routes.Routes()
+	routes, err := c.multiRoutes(b, path)
 	if err != nil {
 		return nil, err
 	}
 	combiner.SetRoutes(routes)
 
-	fanIn, err := c.FanIn.FanIn()
+	fanIn, err := c.FanIn.initFanIn()
 	if err != nil {
-		return nil, err
+		return nil, &fieldError{field: "fan_in", err: err}
 	}
+	// Set the fanIn on the combiner
+	return combiner.WithFanIn(fanIn), nil
+}
+
+// FaultInjectorConfig is used to parse the configuration for a FaultInjector, with its single route
+type FaultInjectorConfig struct {
+	MultiRouteConfig
+	FaultsConfig
+	// Disabled makes the fault injector dispatch the requests as they are, until the injection
+	// is enabled at runtime (see admin.Handler)
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// FaultsConfig is used to parse the faults, that the FaultInjector injects, see fiber.Faults
+type FaultsConfig struct {
+	Delay    *DelayFaultConfig    `json:"delay,omitempty"`
+	Abort    *AbortFaultConfig    `json:"abort,omitempty"`
+	Corrupt  *CorruptFaultConfig  `json:"corrupt,omitempty"`
+	Truncate *TruncateFaultConfig `json:"truncate,omitempty"`
+	// Header is the name of the request header, that triggers the faults listed in its values
+	Header string `json:"header,omitempty"`
+	// HeaderOnly makes the faults be injected only into the requests, that trigger them with the header
+	HeaderOnly bool `json:"header_only,omitempty"`
+}
+
+// DelayFaultConfig is used to parse the delay: either fixed, or uniformly distributed between min and max,
+// or normally distributed with the mean and the stddev
+type DelayFaultConfig struct {
+	Percentage float64  `json:"percentage"`
+	Fixed      Duration `json:"fixed,omitempty"`
+	Min        Duration `json:"min,omitempty"`
+	Max        Duration `json:"max,omitempty"`
+	Mean       Duration `json:"mean,omitempty"`
+	StdDev     Duration `json:"stddev,omitempty"`
+}
+
+// AbortFaultConfig is used to parse the abort fault, with the status of the http and grpc error responses
+type AbortFaultConfig struct {
+	Percentage float64 `json:"percentage"`
+	HTTPStatus int     `json:"http_status,omitempty"`
+	GRPCStatus int     `json:"grpc_status,omitempty"`
+}
+
+// CorruptFaultConfig is used to parse the corruption of the payloads, with the number of the corrupted bytes
+type CorruptFaultConfig struct {
+	Percentage float64 `json:"percentage"`
+	Bytes      int     `json:"bytes,omitempty"`
+}
 
-	// Initialize fanIn
-	err = fanIn.Initialize(c.FanIn.Properties)
+// TruncateFaultConfig is used to parse the truncation of the payloads, with the length they are truncated to
+type TruncateFaultConfig struct {
+	Percentage float64 `json:"percentage"`
+	Length     int     `json:"length,omitempty"`
+}
+
+// Faults validates the config and creates the faults from it
+func (c *FaultsConfig) Faults() (fiber.Faults, error) {
+	faults := fiber.Faults{Header: c.Header, HeaderOnly: c.HeaderOnly}
+	if c.Delay != nil {
+		faults.Delay = &fiber.DelayFault{
+			Percentage: c.Delay.Percentage,
+			Fixed:      time.Duration(c.Delay.Fixed),
+			Min:        time.Duration(c.Delay.Min),
+			Max:        time.Duration(c.Delay.Max),
+			Mean:       time.Duration(c.Delay.Mean),
+			StdDev:     time.Duration(c.Delay.StdDev),
+		}
+	}
+	if c.Abort != nil {
+		if c.Abort.GRPCStatus < 0 {
+			return faults, fmt.Errorf("abort: unknown grpc status: %d", c.Abort.GRPCStatus)
+		}
+		faults.Abort = &fiber.AbortFault{
+			Percentage: c.Abort.Percentage,
+			HTTPStatus: c.Abort.HTTPStatus,
+			GRPCStatus: codes.Code(c.Abort.GRPCStatus),
+		}
+	}
+	if c.Corrupt != nil {
+		faults.Corrupt = &fiber.CorruptFault{Percentage: c.Corrupt.Percentage, Bytes: c.Corrupt.Bytes}
+	}
+	if c.Truncate != nil {
+		faults.Truncate = &fiber.TruncateFault{Percentage: c.Truncate.Percentage, Length: c.Truncate.Length}
+	}
+	return faults, faults.Validate()
+}
+
+func (c *FaultInjectorConfig) initComponent(b *builder, path string) (fiber.Component, error) {
+	routes, err := b.routes(path, c.Routes)
 	if err != nil {
 		return nil, err
 	}
-	// Set the fanIn on the combiner
-	return combiner.WithFanIn(fanIn), nil
+	faults, err := c.Faults()
+	if err != nil {
+		return nil, fmt.Errorf("fault injector %s: %w", c.ID, &fieldError{err: fmt.Errorf("invalid faults: %w", err)})
+	}
+	if len(c.Routes) != 1 {
+		return nil, &fieldError{
+			field: "routes",
+			err:   fmt.Errorf("fault injector %s must have exactly one route, got %d", c.ID, len(c.Routes)),
+		}
+	}
+
+	// the routes have the single route of the fault injector
+	var route fiber.Component
+	for _, r := range routes {
+		route = r
+	}
+	injector := fiber.NewFaultInjector(c.ID, route)
+	if err := injector.SetFaults(faults); err != nil {
+		return nil, fmt.Errorf("fault injector %s: %w", c.ID, err)
+	}
+	if c.Disabled {
+		injector.Disable()
+	}
+	return injector, nil
 }
 
 // ProxyConfig is used to parse the configuration for a Proxy
@@ -181,27 +404,352 @@ type ProxyConfig struct {
 	Endpoint string            `json:"endpoint" required:"true"`
 	Timeout  Duration          `json:"timeout"`
 	Protocol protocol.Protocol `json:"protocol"`
+	// ComponentTimeout, if set, limits the time, that the proxy takes to dispatch a request, the same way as
+	// the timeout of the other components does (see ComponentConfig.Timeout), while Timeout is the timeout
+	// of the dispatcher's calls to the backend, which fail with the transport error, once it's exceeded
+	ComponentTimeout Duration `json:"component_timeout,omitempty"`
+	// PreserveErrors makes the proxy keep unsuccessful upstream responses as they are
+	// (status, headers / metadata, body), instead of replacing them with fiber error responses
+	PreserveErrors bool `json:"preserve_errors,omitempty"`
+	// PropagateDeadline makes the http proxy send the remaining time until the request's
+	// deadline to the backend. The deadline is always propagated to grpc backends
+	PropagateDeadline bool `json:"propagate_deadline,omitempty"`
+	// HTTP2, if set, makes the http proxy talk HTTP/2 to the backend
+	HTTP2 *HTTP2Config `json:"http2,omitempty"`
+	// Transport, if set, configures the connections of the http proxy to the backend,
+	// or references one of the shared transports by its name
+	Transport *TransportConfig `json:"transport,omitempty"`
 	GrpcConfig
 }
 
+func (c *ProxyConfig) routes() Routes {
+	return nil
+}
+
+// HTTP2Config is used to parse the configuration of HTTP/2 connections to the backend
+type HTTP2Config struct {
+	// H2C enables HTTP/2 over cleartext TCP, for backends with the http:// scheme
+	H2C bool `json:"h2c,omitempty"`
+	// StrictMaxConcurrentStreams makes the proxy reuse connections up to the backend's limit
+	// of concurrent streams, instead of opening new connections when the limit is reached
+	StrictMaxConcurrentStreams bool     `json:"strict_max_concurrent_streams,omitempty"`
+	IdleConnTimeout            Duration `json:"idle_conn_timeout,omitempty"`
+	ReadIdleTimeout            Duration `json:"read_idle_timeout,omitempty"`
+	PingTimeout                Duration `json:"ping_timeout,omitempty"`
+}
+
+// TransportConfig is used to parse the configuration of the http connections to the backend. In place
+// of the transport config, proxies can reference one of the shared transports, defined at the root of the
+// config under the "transports" key, by its name. Proxies with the same shared transport share its connections
+type TransportConfig struct {
+	// Name is the name of the shared transport, that the proxy references
+	Name string `json:"-"`
+
+	MaxIdleConns        int `json:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost int `json:"max_idle_conns_per_host,omitempty"`
+	MaxConnsPerHost     int `json:"max_conns_per_host,omitempty"`
+
+	IdleConnTimeout       Duration `json:"idle_conn_timeout,omitempty"`
+	DialTimeout           Duration `json:"dial_timeout,omitempty"`
+	KeepAlive             Duration `json:"keep_alive,omitempty"`
+	TLSHandshakeTimeout   Duration `json:"tls_handshake_timeout,omitempty"`
+	ResponseHeaderTimeout Duration `json:"response_header_timeout,omitempty"`
+	// ProxyURL is the URL of the outbound proxy. If not set, the proxy is taken from
+	// the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	ProxyURL string `json:"proxy_url,omitempty"`
+	// TLS configures the TLS connections to the https:// backends
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// transportConfig has the fields of TransportConfig, without its custom JSON encoding
+type transportConfig TransportConfig
+
+// UnmarshalJSON parses either the transport config, or the name of the shared transport
+func (c *TransportConfig) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		*c = TransportConfig{}
+		return json.Unmarshal(b, &c.Name)
+	}
+	return json.Unmarshal(b, (*transportConfig)(c))
+}
+
+// MarshalJSON encodes the transport config, or the name of the shared transport, that it references
+func (c TransportConfig) MarshalJSON() ([]byte, error) {
+	if c.Name != "" {
+		return json.Marshal(c.Name)
+	}
+	return json.Marshal(transportConfig(c))
+}
+
+func (c *TransportConfig) options() (fiberHTTP.TransportOptions, error) {
+	options := fiberHTTP.TransportOptions{
+		MaxIdleConns:          c.MaxIdleConns,
+		MaxIdleConnsPerHost:   c.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       c.MaxConnsPerHost,
+		IdleConnTimeout:       time.Duration(c.IdleConnTimeout),
+		DialTimeout:           time.Duration(c.DialTimeout),
+		KeepAlive:             time.Duration(c.KeepAlive),
+		TLSHandshakeTimeout:   time.Duration(c.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: time.Duration(c.ResponseHeaderTimeout),
+	}
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil {
+			return options, &fieldError{field: "proxy_url", err: fmt.Errorf("invalid proxy url: %w", err)}
+		}
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return options, &fieldError{field: "proxy_url", err: fmt.Errorf("invalid proxy url: %s", c.ProxyURL)}
+		}
+		options.Proxy = proxyURL
+	}
+	return options, nil
+}
+
+// transport creates the http transport. The TLS config of the proxy is used, if the transport has none
+func (c *TransportConfig) transport(proxyTLS *TLSConfig) (*http.Transport, error) {
+	options, err := c.options()
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := c.TLS
+	if tlsConfig == nil {
+		tlsConfig = proxyTLS
+	} else if proxyTLS != nil {
+		return nil, &fieldError{field: "tls", err: errors.New("tls is set both on the proxy and its transport")}
+	}
+	var clientTLSConfig *tls.Config
+	if tlsConfig != nil {
+		if clientTLSConfig, err = tlsConfig.clientTLSConfig(); err != nil {
+			return nil, err
+		}
+	}
+	return fiberHTTP.NewTransport(clientTLSConfig, options), nil
+}
+
+// transportsKey is the key of the root config mapping, with the shared transports
+const transportsKey = "transports"
+
+func (c *ProxyConfig) timeout() (Duration, string) {
+	return c.ComponentTimeout, "component_timeout"
+}
+
+// httpClient creates the http client of the proxy, with the shared transport, if the proxy references one
+func (c *ProxyConfig) httpClient(shared *http.Transport) (*http.Client, error) {
+	httpClient := &http.Client{Timeout: time.Duration(c.Timeout)}
+	if shared != nil {
+		httpClient.Transport = shared
+		return httpClient, nil
+	}
+	if c.Transport == nil && c.HTTP2 == nil && c.TLS == nil {
+		return httpClient, nil
+	}
+
+	transportConfig := c.Transport
+	if transportConfig == nil {
+		transportConfig = &TransportConfig{}
+	}
+	transport, err := transportConfig.transport(c.TLS)
+	if err != nil {
+		return nil, &fieldError{field: "transport", err: err}
+	}
+
+	if c.HTTP2 != nil {
+		roundTripper, err := fiberHTTP.ConfigureHTTP2(transport, fiberHTTP.HTTP2Options{
+			Cleartext:                  c.HTTP2.H2C,
+			StrictMaxConcurrentStreams: c.HTTP2.StrictMaxConcurrentStreams,
+			IdleConnTimeout:            time.Duration(c.HTTP2.IdleConnTimeout),
+			ReadIdleTimeout:            time.Duration(c.HTTP2.ReadIdleTimeout),
+			PingTimeout:                time.Duration(c.HTTP2.PingTimeout),
+		})
+		if err != nil {
+			return nil, err
+		}
+		httpClient.Transport = roundTripper
+	} else {
+		httpClient.Transport = transport
+	}
+	return httpClient, nil
+}
+
+// transportProperties describe the settings of the http client's transport, so that they are
+// included into the description of the proxy (see Marshal)
+func (c *ProxyConfig) transportProperties() map[string]interface{} {
+	var properties map[string]interface{}
+	if c.TLS != nil || c.HTTP2 != nil || c.Transport != nil {
+		properties = make(map[string]interface{})
+	}
+	if c.TLS != nil {
+		properties["tls"] = c.TLS
+	}
+	if c.HTTP2 != nil {
+		properties["http2"] = c.HTTP2
+	}
+	if c.Transport != nil {
+		properties["transport"] = c.Transport
+	}
+	return properties
+}
+
 type GrpcConfig struct {
 	ServiceMethod string `json:"service_method,omitempty"`
+	// Streaming is the type of streaming RPC to perform (server / bidirectional),
+	// if not set, unary calls will be made
+	Streaming grpc.StreamType `json:"streaming,omitempty"`
+	// Transparent proxies call the service method of the incoming request, so a single
+	// proxy can front an entire gRPC service. service_method is not required then
+	Transparent bool `json:"transparent,omitempty"`
+	// AllowedMethods and DeniedMethods restrict the service methods, that a transparent
+	// proxy is allowed to call. path.Match patterns, such as "/mypackage.Greeter/*", are supported
+	AllowedMethods []string `json:"allowed_methods,omitempty"`
+	DeniedMethods  []string `json:"denied_methods,omitempty"`
+	// TLS, if set, configures the TLS connection of the proxy (grpc or https) to the server
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// Credentials, if set, are attached to every call made by the proxy
+	Credentials *CredentialsConfig `json:"credentials,omitempty"`
 }
 
-func (c *ProxyConfig) initComponent() (fiber.Component, error) {
+// TLSConfig is used to parse the configuration of a TLS connection to the backend
+type TLSConfig struct {
+	// CAFile is the path to the PEM encoded CA bundle, used to verify the server certificate
+	CAFile string `json:"ca_file,omitempty"`
+	// CertFile and KeyFile are the paths to the PEM encoded client certificate and key, for mTLS
+	CertFile string `json:"cert_file,omitempty"`
+	KeyFile  string `json:"key_file,omitempty"`
+	// ServerName overrides the server name used to verify the server certificate
+	ServerName string `json:"server_name,omitempty"`
+}
+
+func (c *TLSConfig) clientTLSConfig() (*tls.Config, error) {
+	tlsConfig, err := util.NewClientTLSConfig(c.CAFile, c.CertFile, c.KeyFile, c.ServerName)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tls config: %w", err)
+	}
+	return tlsConfig, nil
+}
+
+const (
+	// BearerTokenCredentials is the type of credentials with a static bearer token
+	BearerTokenCredentials = "bearer_token"
+	// TokenFileCredentials is the type of credentials with a bearer token read from a file,
+	// the token is reloaded whenever the file changes
+	TokenFileCredentials = "token_file"
+)
+
+// CredentialsConfig is used to parse the configuration of per-RPC credentials
+type CredentialsConfig struct {
+	Type      string `json:"type" required:"true"`
+	Token     string `json:"token,omitempty"`
+	TokenFile string `json:"token_file,omitempty"`
+}
+
+// PerRPCCredentials creates the per-RPC credentials from the configuration
+func (c *CredentialsConfig) PerRPCCredentials() (credentials.PerRPCCredentials, error) {
+	switch c.Type {
+	case BearerTokenCredentials:
+		if c.Token == "" {
+			return nil, errors.New("bearer token is not set")
+		}
+		return grpc.NewStaticTokenCredentials(c.Token), nil
+	case TokenFileCredentials:
+		return grpc.NewTokenFileCredentials(c.TokenFile)
+	default:
+		return nil, fmt.Errorf("unknown credentials type: %s", c.Type)
+	}
+}
+
+func (c *GrpcConfig) dispatcherConfig(endpoint string, timeout time.Duration) (grpc.DispatcherConfig, error) {
+	dispatcherConfig := grpc.DispatcherConfig{
+		ServiceMethod:  c.ServiceMethod,
+		Endpoint:       endpoint,
+		Timeout:        timeout,
+		Streaming:      c.Streaming,
+		Transparent:    c.Transparent,
+		AllowedMethods: c.AllowedMethods,
+		DeniedMethods:  c.DeniedMethods,
+	}
+	if c.TLS != nil {
+		dispatcherConfig.TLS = &grpc.TLSConfig{
+			CAFile:     c.TLS.CAFile,
+			CertFile:   c.TLS.CertFile,
+			KeyFile:    c.TLS.KeyFile,
+			ServerName: c.TLS.ServerName,
+		}
+	}
+	if c.Credentials != nil {
+		perRPCCredentials, err := c.Credentials.PerRPCCredentials()
+		if err != nil {
+			return dispatcherConfig, err
+		}
+		dispatcherConfig.PerRPCCredentials = perRPCCredentials
+	}
+	return dispatcherConfig, nil
+}
+
+// checkProtocolOptions reports the options of the proxy, that are not supported by its protocol, and so are ignored
+func (c *ProxyConfig) checkProtocolOptions(b *builder, path string, isGRPC bool) {
+	if isGRPC {
+		if c.HTTP2 != nil {
+			b.lint(path+".http2", "http2 is only supported by http proxies")
+		}
+		if c.Transport != nil {
+			b.lint(path+".transport", "transport is only supported by http proxies")
+		}
+		if c.PropagateDeadline {
+			b.lint(path+".propagate_deadline", "propagate_deadline is only supported by http proxies")
+		}
+		return
+	}
+	if c.ServiceMethod != "" || c.Streaming != grpc.Unary || c.Transparent {
+		b.lint(path, "service_method, streaming and transparent are only supported by grpc proxies")
+	}
+	if c.Credentials != nil {
+		b.lint(path+".credentials", "credentials are only supported by grpc proxies")
+	}
+}
+
+func (c *ProxyConfig) initComponent(b *builder, path string) (fiber.Component, error) {
+	if c.Endpoint == "" {
+		return nil, &fieldError{field: "endpoint", err: errors.New("missing endpoint")}
+	}
+	isGRPC := strings.EqualFold(string(c.Protocol), string(protocol.GRPC))
+	if !isGRPC && c.Protocol != "" && !strings.EqualFold(string(c.Protocol), string(protocol.HTTP)) {
+		b.lint(path+".protocol", "unknown protocol: %s, the proxy is an http proxy", c.Protocol)
+	}
+	c.checkProtocolOptions(b, path, isGRPC)
 
 	var dispatcher fiber.Dispatcher
 	var err error
 	var backend fiber.Backend
-	if strings.EqualFold(string(c.Protocol), string(protocol.GRPC)) {
-		dispatcher, err = grpc.NewDispatcher(grpc.DispatcherConfig{
-			ServiceMethod: c.ServiceMethod,
-			Endpoint:      c.Endpoint,
-			Timeout:       time.Duration(c.Timeout),
-		})
+	if isGRPC {
+		var dispatcherConfig grpc.DispatcherConfig
+		if dispatcherConfig, err = c.dispatcherConfig(c.Endpoint, time.Duration(c.Timeout)); err != nil {
+			return nil, &fieldError{field: "credentials", err: err}
+		}
+		dispatcherConfig.PreserveErrors = c.PreserveErrors
+		if b.dryRun {
+			// the grpc dispatcher connects to the backend, so it's only checked in the dry run
+			if err := dispatcherConfig.Validate(); err != nil {
+				return nil, err
+			}
+			return newPlaceholder(path, c), nil
+		}
+		dispatcher, err = grpc.NewDispatcher(dispatcherConfig)
 	} else {
-		httpClient := &http.Client{Timeout: time.Duration(c.Timeout)}
-		dispatcher, err = fiberHTTP.NewDispatcher(httpClient)
+		var shared *http.Transport
+		if c.Transport != nil && c.Transport.Name != "" {
+			if shared, err = b.sharedTransport(c); err != nil {
+				return nil, err
+			}
+		}
+		var httpClient *http.Client
+		if httpClient, err = c.httpClient(shared); err == nil {
+			dispatcher, err = fiberHTTP.NewDispatcherWithOptions(httpClient, fiberHTTP.DispatcherOptions{
+				PreserveErrors:      c.PreserveErrors,
+				PropagateDeadline:   c.PropagateDeadline,
+				TransportProperties: c.transportProperties(),
+			})
+		}
 		backend = fiber.NewBackend(c.ID, c.Endpoint)
 	}
 	if err != nil {
@@ -216,15 +764,11 @@ func (c *ProxyConfig) initComponent() (fiber.Component, error) {
 }
 
 // InitComponentFromConfig takes in the path to a config file, parses the contents
+// (resolving environment variables, includes and references, see LoadFile)
 // and if successful, constructs a fiber Component
 func InitComponentFromConfig(configPath string) (fiber.Component, error) {
-	if yamlFile, err := os.ReadFile(configPath); err != nil {
-		return nil, err
-	} else if cfg, err := parseConfig(yamlFile); err != nil {
-		return nil, err
-	} else {
-		return cfg.initComponent()
-	}
+	component, _, err := initComponentFromFile(configPath, newBuilder(false))
+	return component, err
 }
 
 func parseConfig(data []byte) (Config, error) {
@@ -234,11 +778,17 @@ func parseConfig(data []byte) (Config, error) {
 	}{}
 
 	if err := yaml.Unmarshal(data, &typez); err != nil {
+		var obj map[string]json.RawMessage
+		if yaml.Unmarshal(data, &obj) != nil {
+			return nil, &fieldError{err: errors.New("component must be a mapping")}
+		}
 		return nil, err
 	}
 
 	var dst Config
 	switch typez.Type {
+	case "":
+		return nil, &fieldError{field: "type", err: errors.New("missing component type")}
 	case "PROXY":
 		dst = &ProxyConfig{
 			// Set the default value here, can't find an easier way to supply defaults
@@ -253,8 +803,12 @@ func parseConfig(data []byte) (Config, error) {
 		dst = &CombinerConfig{
 			MultiRouteConfig: MultiRouteConfig{Routes: make(Routes, len(typez.Routes))},
 		}
+	case "FAULT_INJECTOR":
+		dst = &FaultInjectorConfig{
+			MultiRouteConfig: MultiRouteConfig{Routes: make(Routes, len(typez.Routes))},
+		}
 	default:
-		return nil, fmt.Errorf("unknown component type: %s", typez.Type)
+		return parseCustomConfig(data, typez.Type, len(typez.Routes))
 	}
 
 	if err := yaml.Unmarshal(data, dst); err != nil {
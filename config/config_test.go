@@ -1,9 +1,15 @@
 package config_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -124,12 +130,14 @@ func TestFromConfig(t *testing.T) {
 					cmp.Equal(tt.expectedComponent, got,
 						cmpopts.IgnoreUnexported(grpc.ClientConn{}, dynamicpb.Message{}),
 						cmpopts.IgnoreInterfaces(struct{ grpc.ClientConnInterface }{}),
+						cmpopts.IgnoreTypes(sync.Once{}),
 						cmp.AllowUnexported(
 							fiber.BaseComponent{},
 							fiber.Proxy{},
 							fiber.Caller{},
 							fibergrpc.Dispatcher{},
-							fiberhttp.Dispatcher{}),
+							fiberhttp.Dispatcher{},
+							atomic.Int64{}),
 					),
 					"config not equal to expected")
 			} else {
@@ -138,3 +146,393 @@ func TestFromConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestFromConfig_HTTP2(t *testing.T) {
+	backend := httptest.NewServer(fiberhttp.NewH2CHandler(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(r.Proto))
+		}), fiberhttp.H2COptions{}))
+	defer backend.Close()
+
+	tests := []struct {
+		name           string
+		config         string
+		expectedProto  string
+		expectedErrMsg string
+	}{
+		{
+			name: "h2c",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+http2:
+  h2c: true
+  strict_max_concurrent_streams: true
+  idle_conn_timeout: 30s
+  read_idle_timeout: 10s
+  ping_timeout: 5s`,
+			expectedProto: "HTTP/2.0",
+		},
+		{
+			name: "http/1.1",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL,
+			expectedProto: "HTTP/1.1",
+		},
+		{
+			name: "invalid tls config",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+http2: {}
+tls:
+  cert_file: client.crt`,
+			expectedErrMsg: "invalid tls config: both client certificate and key are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0600))
+
+			component, err := config.InitComponentFromConfig(configPath)
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodGet, backend.URL, http.NoBody)
+			require.NoError(t, err)
+			req, err := fiberhttp.NewHTTPRequest(httpReq)
+			require.NoError(t, err)
+
+			resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+			require.True(t, ok)
+			assert.True(t, resp.IsSuccess())
+			assert.Equal(t, tt.expectedProto, string(resp.Payload()))
+		})
+	}
+}
+
+func TestFromConfig_Transport(t *testing.T) {
+	// the outbound proxy answers the requests itself, with the host, that they are sent to
+	outboundProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("proxied:" + r.URL.Host))
+	}))
+	defer outboundProxy.Close()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("direct"))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name             string
+		config           string
+		expectedResponse string
+		expectedErrMsg   string
+	}{
+		{
+			name: "transport",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+transport:
+  max_idle_conns: 10
+  max_idle_conns_per_host: 5
+  max_conns_per_host: 5
+  idle_conn_timeout: 30s
+  dial_timeout: 1s
+  keep_alive: 15s
+  tls_handshake_timeout: 1s
+  response_header_timeout: 1s`,
+			expectedResponse: "direct",
+		},
+		{
+			name: "outbound proxy",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+transport:
+  proxy_url: ` + outboundProxy.URL,
+			expectedResponse: "proxied:" + backend.Listener.Addr().String(),
+		},
+		{
+			name: "shared transport",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+transport: outbound
+transports:
+  outbound:
+    proxy_url: ` + outboundProxy.URL,
+			expectedResponse: "proxied:" + backend.Listener.Addr().String(),
+		},
+		{
+			name: "unknown transport",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+transport: outbound`,
+			expectedErrMsg: "unknown transport: outbound",
+		},
+		{
+			name: "http2 with shared transport",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+transport: outbound
+http2: {}
+transports:
+  outbound:
+    dial_timeout: 1s`,
+			expectedErrMsg: "proxy proxy_name: http2 and tls can't be set on proxies with the shared transport outbound",
+		},
+		{
+			name: "invalid proxy url",
+			config: `
+type: PROXY
+id: proxy_name
+endpoint: ` + backend.URL + `
+transport:
+  proxy_url: localhost`,
+			expectedErrMsg: "invalid proxy url: localhost",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0600))
+
+			component, err := config.InitComponentFromConfig(configPath)
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodGet, backend.URL, http.NoBody)
+			require.NoError(t, err)
+			req, err := fiberhttp.NewHTTPRequest(httpReq)
+			require.NoError(t, err)
+
+			resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+			require.True(t, ok)
+			assert.True(t, resp.IsSuccess())
+			assert.Equal(t, tt.expectedResponse, string(resp.Payload()))
+		})
+	}
+}
+
+func TestFromConfig_FaultInjector(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer backend.Close()
+
+	dir := writeFiles(t, map[string]string{
+		"fiber.yaml": `
+type: FAULT_INJECTOR
+id: injector
+header: X-Fiber-Fault
+delay:
+  percentage: 0
+  min: 10ms
+  max: 20ms
+abort:
+  percentage: 0
+  http_status: 502
+  grpc_status: 14
+truncate:
+  percentage: 100
+  length: 2
+routes:
+  - id: route_a
+    type: PROXY
+    timeout: 1s
+    endpoint: ` + backend.URL,
+		"disabled.yaml": `
+type: FAULT_INJECTOR
+id: injector
+disabled: true
+truncate:
+  percentage: 100
+routes:
+  - id: route_a
+    type: PROXY
+    timeout: 1s
+    endpoint: ` + backend.URL,
+		"no_route.yaml": `
+type: FAULT_INJECTOR
+id: injector
+routes: []`,
+	})
+
+	component, err := config.InitComponentFromConfig(filepath.Join(dir, "fiber.yaml"))
+	require.NoError(t, err)
+	injector, ok := component.(*fiber.FaultInjector)
+	require.True(t, ok)
+	assert.True(t, injector.Enabled())
+	assert.Equal(t, fiber.Faults{
+		Delay:    &fiber.DelayFault{Min: 10 * time.Millisecond, Max: 20 * time.Millisecond},
+		Abort:    &fiber.AbortFault{HTTPStatus: http.StatusBadGateway, GRPCStatus: 14},
+		Truncate: &fiber.TruncateFault{Percentage: 100, Length: 2},
+		Header:   "X-Fiber-Fault",
+	}, injector.Faults())
+
+	req, err := fiberhttp.NewHTTPRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	resp := <-injector.Dispatch(context.Background(), req).Iter()
+	assert.Equal(t, "he", string(resp.Payload()))
+
+	// the exported config creates the same fault injector
+	data, err := config.Marshal(component)
+	require.NoError(t, err)
+	exportedPath := filepath.Join(dir, "exported.yaml")
+	require.NoError(t, os.WriteFile(exportedPath, data, 0600))
+	expected, err := config.ParseTreeFile(filepath.Join(dir, "fiber.yaml"))
+	require.NoError(t, err)
+	actual, err := config.ParseTreeFile(exportedPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	component, err = config.InitComponentFromConfig(filepath.Join(dir, "disabled.yaml"))
+	require.NoError(t, err)
+	assert.False(t, component.(*fiber.FaultInjector).Enabled())
+
+	_, err = config.InitComponentFromConfig(filepath.Join(dir, "no_route.yaml"))
+	assert.EqualError(t, err, "fault injector injector must have exactly one route, got 0")
+}
+
+func TestFromConfig_Timeout(t *testing.T) {
+	newBackend := func(body string, latency time.Duration) *httptest.Server {
+		backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+			}
+			_, _ = w.Write([]byte(body))
+		}))
+		t.Cleanup(backend.Close)
+		return backend
+	}
+
+	dir := writeFiles(t, map[string]string{
+		"fiber.yaml": `
+type: LAZY_ROUTER
+id: router
+timeout: 2s
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: primary
+    type: COMBINER
+    timeout: 50ms
+    fan_in:
+      type: fiber.FastestResponseFanIn
+    routes:
+      - id: route_a
+        type: PROXY
+        timeout: 5s
+        endpoint: ` + newBackend("a", time.Second).URL + `
+  - id: fallback
+    type: FAULT_INJECTOR
+    routes:
+      - id: route_b
+        type: PROXY
+        timeout: 5s
+        endpoint: ` + newBackend("b", 0).URL,
+	})
+
+	component, err := config.InitComponentFromConfig(filepath.Join(dir, "fiber.yaml"))
+	require.NoError(t, err)
+	router := component.(*fiber.LazyRouter)
+	assert.Equal(t, 2*time.Second, router.Timeout())
+	assert.Equal(t, 50*time.Millisecond, router.GetRoutes()["primary"].(*fiber.Combiner).Timeout())
+	assert.Equal(t, time.Duration(0), router.GetRoutes()["fallback"].(*fiber.FaultInjector).Timeout())
+
+	// the primary route times out, whichever route is selected first
+	req, err := fiberhttp.NewHTTPRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+	require.NoError(t, err)
+	start := time.Now()
+	resp := <-router.Dispatch(context.Background(), req).Iter()
+	assert.Equal(t, "b", string(resp.Payload()))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// the timeouts are exported
+	data, err := config.Marshal(component)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "timeout: 2s")
+	assert.Contains(t, string(data), "timeout: 50ms")
+}
+
+func TestFromConfig_ProxyTimeout(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		_, _ = w.Write([]byte("late"))
+	}))
+	t.Cleanup(backend.Close)
+
+	tests := map[string]struct {
+		timeouts                 string
+		expectedStatus           int
+		expectedTimeout          time.Duration
+		expectedClientTimeout    string
+		expectedComponentTimeout interface{}
+	}{
+		"timeout": {
+			// the call to the backend fails with the transport error
+			timeouts:              "timeout: 50ms",
+			expectedStatus:        http.StatusInternalServerError,
+			expectedClientTimeout: "50ms",
+		},
+		"component timeout": {
+			// the proxy times out as the other components do, its dispatcher has the default timeout
+			timeouts:                 "component_timeout: 50ms",
+			expectedStatus:           http.StatusRequestTimeout,
+			expectedTimeout:          50 * time.Millisecond,
+			expectedClientTimeout:    "1s",
+			expectedComponentTimeout: "50ms",
+		},
+		"both": {
+			timeouts:                 "timeout: 2s\ncomponent_timeout: 50ms",
+			expectedStatus:           http.StatusRequestTimeout,
+			expectedTimeout:          50 * time.Millisecond,
+			expectedClientTimeout:    "2s",
+			expectedComponentTimeout: "50ms",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"fiber.yaml": "type: PROXY\nid: proxy\nendpoint: " + backend.URL + "\n" + tt.timeouts,
+			})
+			component, err := config.InitComponentFromConfig(filepath.Join(dir, "fiber.yaml"))
+			require.NoError(t, err)
+			proxy := component.(*fiber.Proxy)
+			assert.Equal(t, tt.expectedTimeout, proxy.Timeout())
+			assert.Equal(t, tt.expectedClientTimeout, proxy.Describe().Properties["timeout"])
+			assert.Equal(t, tt.expectedComponentTimeout, proxy.Describe().Properties["component_timeout"])
+
+			req, err := fiberhttp.NewHTTPRequest(httptest.NewRequest(http.MethodGet, "/", nil))
+			require.NoError(t, err)
+			resp := <-proxy.Dispatch(context.Background(), req).Iter()
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode())
+		})
+	}
+}
@@ -0,0 +1,439 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/extras/interceptor"
+	fiberhttp "github.com/gojek/fiber/http"
+	"github.com/gojek/fiber/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	recordedMu sync.Mutex
+	// recorded are the IDs of the components, that the requests were dispatched by,
+	// by the names of the recording interceptors
+	recorded = make(map[string][]string)
+)
+
+// recordingInterceptor records the IDs of the components, that it intercepts the requests of
+type recordingInterceptor struct {
+	fiber.NoopAfterDispatchInterceptor
+	fiber.NoopAfterCompletionInterceptor
+	Name string `json:"name"`
+}
+
+func (i *recordingInterceptor) Initialize(properties json.RawMessage) error {
+	if err := json.Unmarshal(properties, i); err != nil {
+		return err
+	}
+	if i.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func (i *recordingInterceptor) BeforeDispatch(ctx context.Context, _ fiber.Request) context.Context {
+	recordedMu.Lock()
+	defer recordedMu.Unlock()
+	recorded[i.Name] = append(recorded[i.Name], ctx.Value(fiber.CtxComponentIDKey).(string))
+	return ctx
+}
+
+func TestFromConfig_Interceptors(t *testing.T) {
+	require.NoError(t, types.InstallType("test.RecordingInterceptor", &recordingInterceptor{}))
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name           string
+		config         string
+		expectedIDs    map[string][]string
+		expectedErrMsg string
+	}{
+		{
+			name: "interceptors",
+			config: `
+type: COMBINER
+id: combiner
+fan_in:
+  type: fiber.FastestResponseFanIn
+interceptors:
+  - type: test.RecordingInterceptor
+    recursive: true
+    properties:
+      name: recursive
+  - type: test.RecordingInterceptor
+    properties:
+      name: combiner
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: ` + backend.URL + `
+    interceptors:
+      - type: test.RecordingInterceptor
+        properties:
+          name: route_a
+  - id: route_b
+    type: PROXY
+    endpoint: ` + backend.URL,
+			expectedIDs: map[string][]string{
+				// the combiner dispatches the requests to its routes with the inner fan_out component
+				"recursive": {"combiner", "fan_out", "route_a", "route_b"},
+				"combiner":  {"combiner"},
+				"route_a":   {"route_a"},
+			},
+		},
+		{
+			name: "built-in interceptors",
+			config: `
+type: PROXY
+id: route_a
+endpoint: ` + backend.URL + `
+interceptors:
+  - type: fiber.LoggingInterceptor
+    properties:
+      level: error
+  - type: fiber.TracingInterceptor`,
+			expectedIDs: map[string][]string{},
+		},
+		{
+			name: "unknown interceptor",
+			config: `
+type: PROXY
+id: route_a
+endpoint: ` + backend.URL + `
+interceptors:
+  - type: test.UnknownInterceptor`,
+			expectedErrMsg: "component route_a: unknown INTERCEPTOR type: test.UnknownInterceptor",
+		},
+		{
+			name: "metrics interceptor",
+			config: `
+type: PROXY
+id: route_a
+endpoint: ` + backend.URL + `
+interceptors:
+  - type: fiber.MetricsInterceptor`,
+			// the interceptor needs a statsd client, so it can't be declared in the config
+			expectedErrMsg: "component route_a: unknown INTERCEPTOR type: fiber.MetricsInterceptor",
+		},
+		{
+			name: "invalid interceptor properties",
+			config: `
+type: PROXY
+id: route_a
+endpoint: ` + backend.URL + `
+interceptors:
+  - type: fiber.CaptureInterceptor`,
+			expectedErrMsg: "component route_a: interceptor fiber.CaptureInterceptor: " +
+				"invalid interceptor properties: path is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordedMu.Lock()
+			recorded = make(map[string][]string)
+			recordedMu.Unlock()
+
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0600))
+
+			component, err := config.InitComponentFromConfig(configPath)
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodGet, backend.URL, http.NoBody)
+			require.NoError(t, err)
+			req, err := fiberhttp.NewHTTPRequest(httpReq)
+			require.NoError(t, err)
+
+			resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+			require.True(t, ok)
+			assert.True(t, resp.IsSuccess())
+
+			// the routes, that haven't responded first, may still be dispatching the request
+			assert.Eventually(t, func() bool {
+				recordedMu.Lock()
+				defer recordedMu.Unlock()
+				for _, ids := range recorded {
+					sort.Strings(ids)
+				}
+				return assert.ObjectsAreEqual(tt.expectedIDs, recorded)
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestFromConfig_PrometheusInterceptor(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+type: PROXY
+id: route_a
+endpoint: `+backend.URL+`
+interceptors:
+  - type: fiber.PrometheusInterceptor
+    properties:
+      namespace: config_test`), 0600))
+
+	// the interceptors of the reinitialized components share the registered metrics
+	for i := 0; i < 2; i++ {
+		component, err := config.InitComponentFromConfig(configPath)
+		require.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodGet, backend.URL, http.NoBody)
+		require.NoError(t, err)
+		req, err := fiberhttp.NewHTTPRequest(httpReq)
+		require.NoError(t, err)
+
+		resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+		require.True(t, ok)
+		assert.True(t, resp.IsSuccess())
+	}
+
+	labels := map[string]string{"component": "route_a", "kind": "Caller", "protocol": "HTTP", "code": "200", "success": "true"}
+	assert.Eventually(t, func() bool {
+		count, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "config_test_requests_total")
+		return err == nil && count == 1 && gatheredCounter(t, "config_test_requests_total", labels) == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+// gatheredCounter returns the value of the counter with the labels, gathered from the prometheus.DefaultGatherer
+func gatheredCounter(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			metricLabels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				metricLabels[label.GetName()] = label.GetValue()
+			}
+			if assert.ObjectsAreEqual(labels, metricLabels) {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func TestFromConfig_OpenTelemetryInterceptor(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	traceParents := make(chan string, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents <- r.Header.Get("Traceparent")
+		if r.URL.Query().Get("fail") != "" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer backend.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+type: LAZY_ROUTER
+id: router
+strategy:
+  type: fiber.RandomRoutingStrategy
+interceptors:
+  - type: fiber.OpenTelemetryInterceptor
+    recursive: true
+    properties:
+      labels: [idx]
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: `+backend.URL), 0600))
+
+	// dispatch returns the ended spans of the router and its route, by the component IDs
+	dispatch := func(t *testing.T, query string) map[string]sdktrace.ReadOnlySpan {
+		recorder := tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+		component, err := config.InitComponentFromConfig(configPath)
+		require.NoError(t, err)
+
+		httpReq, err := http.NewRequest(http.MethodGet, backend.URL+query, http.NoBody)
+		require.NoError(t, err)
+		httpReq.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		req, err := fiberhttp.NewHTTPRequest(httpReq)
+		require.NoError(t, err)
+
+		_, ok := <-component.Dispatch(context.Background(), req).Iter()
+		require.True(t, ok)
+
+		require.Eventually(t, func() bool { return len(recorder.Ended()) == 2 }, time.Second, 10*time.Millisecond)
+		spans := make(map[string]sdktrace.ReadOnlySpan)
+		for _, span := range recorder.Ended() {
+			attributes := attribute.NewSet(span.Attributes()...)
+			id, _ := attributes.Value(interceptor.AttributeComponentID)
+			spans[id.AsString()] = span
+		}
+		require.Contains(t, spans, "router")
+		require.Contains(t, spans, "route_a")
+		return spans
+	}
+
+	t.Run("success", func(t *testing.T) {
+		spans := dispatch(t, "")
+
+		// the spans continue the trace of the incoming request, the caller's span is the parent of the backend's span
+		router, caller := spans["router"], spans["route_a"]
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", router.SpanContext().TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", router.Parent().SpanID().String())
+		assert.Equal(t, router.SpanContext().SpanID(), caller.Parent().SpanID())
+		assert.Equal(t, trace.SpanKindClient, caller.SpanKind())
+		assert.Equal(t, fmt.Sprintf("00-%s-%s-01", caller.SpanContext().TraceID(), caller.SpanContext().SpanID()),
+			<-traceParents)
+
+		attributes := attribute.NewSet(router.Attributes()...)
+		for key, expected := range map[attribute.Key]attribute.Value{
+			interceptor.AttributeComponentKind:       attribute.StringValue("MultiRouteComponent"),
+			interceptor.AttributeProtocol:            attribute.StringValue("HTTP"),
+			interceptor.AttributeRoute:               attribute.StringValue("route_a"),
+			interceptor.AttributeStatusCode:          attribute.IntValue(http.StatusOK),
+			interceptor.AttributeLabelPrefix + "idx": attribute.StringSliceValue([]string{"0"}),
+		} {
+			value, _ := attributes.Value(key)
+			assert.Equal(t, expected, value, key)
+		}
+		assert.Equal(t, codes.Unset, router.Status().Code)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		spans := dispatch(t, "?fail=true")
+		<-traceParents
+
+		for _, span := range spans {
+			assert.Equal(t, codes.Error, span.Status().Code)
+			require.NotEmpty(t, span.Events())
+			assert.Equal(t, "exception", span.Events()[0].Name)
+		}
+		attributes := attribute.NewSet(spans["route_a"].Attributes()...)
+		status, _ := attributes.Value(interceptor.AttributeStatusCode)
+		assert.Equal(t, attribute.IntValue(http.StatusBadGateway), status)
+	})
+}
+
+func TestFromConfig_AccessLogInterceptor(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"user": {"name": "John", "token": "secret"}, "items": [1, 2, 3]}`))
+	}))
+	defer backend.Close()
+
+	logPath := filepath.Join(t.TempDir(), "access.log")
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+type: LAZY_ROUTER
+id: router
+strategy:
+  type: fiber.RandomRoutingStrategy
+interceptors:
+  - type: fiber.AccessLogInterceptor
+    recursive: true
+    properties:
+      output: `+logPath+`
+      labels: [idx]
+      payloads:
+        success_sample_rate: 1
+        max_length: 50
+        redact_fields: [Token, password]
+        redact_headers: [x-api-key]
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: `+backend.URL), 0600))
+
+	component, err := config.InitComponentFromConfig(configPath)
+	require.NoError(t, err)
+
+	httpReq, err := http.NewRequest(http.MethodPost, backend.URL, strings.NewReader(`{"password": "secret"}`))
+	require.NoError(t, err)
+	httpReq.Header.Set("X-Request-Id", "request-1")
+	httpReq.Header.Set("X-Api-Key", "secret")
+	httpReq.Header.Set("Authorization", "Bearer secret")
+	req, err := fiberhttp.NewHTTPRequest(httpReq)
+	require.NoError(t, err)
+
+	_, ok := <-component.Dispatch(context.Background(), req).Iter()
+	require.True(t, ok)
+
+	// records are written by the router and its route, once their dispatches are completed
+	records := make(map[string]map[string]interface{})
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(logPath)
+		if err != nil {
+			return false
+		}
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		for _, line := range lines {
+			var record map[string]interface{}
+			if json.Unmarshal([]byte(line), &record) == nil {
+				records[record["component"].(string)] = record
+			}
+		}
+		return len(records) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, records, "router/route_a")
+
+	record := records["router"]
+	assert.Equal(t, "access", record["msg"])
+	assert.Equal(t, "request-1", record["request_id"])
+	assert.Equal(t, "MultiRouteComponent", record["kind"])
+	assert.Equal(t, "route_a", record["route"])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+	assert.Equal(t, true, record["success"])
+	assert.Equal(t, map[string]interface{}{"idx": []interface{}{"0"}}, record["labels"])
+	assert.Equal(t, float64(len(`{"password": "secret"}`)), record["request_size"])
+	assert.NotZero(t, record["latency"])
+
+	payloads := record["payloads"].(map[string]interface{})
+	assert.Equal(t, `{"password":"[REDACTED]"}`, payloads["request"])
+	assert.Equal(t, `{"items":[1,2,3],"user":{"name":"John","token":"[R...`, payloads["response"])
+	header := payloads["request_header"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"[REDACTED]"}, header["X-Api-Key"])
+	assert.Equal(t, []interface{}{"[REDACTED]"}, header["Authorization"])
+	assert.Equal(t, []interface{}{"request-1"}, header["X-Request-Id"])
+}
@@ -0,0 +1,376 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gojek/fiber"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// includeTag is the YAML tag of the nodes, that are replaced with the contents of the
+	// file at the path in the node value, relative to the including file
+	includeTag = "!include"
+	// refKey is the key of the mappings, that are replaced with the named definition
+	refKey = "$ref"
+	// definitionsKey is the key of the root config mapping, with the named reusable definitions
+	definitionsKey = "definitions"
+)
+
+// envPattern matches "${VAR}" and "${VAR:-default}" references to the environment variables,
+// as well as "$$", which is an escaped "$"
+var envPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// LocationError is an error in the config file, with the location of the config node, that caused it
+type LocationError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *LocationError) Error() string {
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *LocationError) Unwrap() error {
+	return e.Err
+}
+
+// LoadFile reads the fiber config from the file and returns the resolved config document, where:
+//   - "${VAR}" and "${VAR:-default}" in the values are replaced with the environment variables ("$$" is an escaped "$")
+//   - the nodes tagged with "!include path/to/file.yaml" are replaced with the contents of the file
+//   - the mappings with the "$ref: name" key are replaced with the named definition from the "definitions"
+//     mapping of the root config. Other keys of such mappings override the keys of the definition
+func LoadFile(configPath string) ([]byte, error) {
+	doc, err := loadDocument(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc.root)
+}
+
+// initComponentFromFile constructs the component from the config file with the builder, see InitComponentFromConfig.
+// It also returns the sources of the config (the config file and the files it includes), even if it couldn't be loaded
+func initComponentFromFile(configPath string, b *builder) (fiber.Component, map[string]fileState, error) {
+	doc, err := loadDocument(configPath)
+	if err != nil {
+		return nil, doc.sources, err
+	}
+	yamlFile, err := yaml.Marshal(doc.root)
+	if err != nil {
+		return nil, doc.sources, err
+	}
+	component, err := b.build(yamlFile)
+	return component, doc.sources, err
+}
+
+// document is the resolved config, with the files the config nodes originate from
+type document struct {
+	root  *yaml.Node
+	files map[*yaml.Node]string
+	// sources are the states of the config file and the files it includes (even if they
+	// couldn't be read), at the time they were read, by their paths
+	sources map[string]fileState
+
+	definitions map[string]*yaml.Node
+	// resolving is the stack of the definitions being resolved, to detect cyclic references
+	resolving []string
+}
+
+// fileState is the state of the config file, that tells if it has changed since it was read
+type fileState struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, modTime: info.ModTime(), size: info.Size()}
+}
+
+// loadDocument loads the config document from the file. The document is returned even if it couldn't
+// be loaded, with the sources of the config, that were read
+func loadDocument(configPath string) (*document, error) {
+	doc := &document{
+		files:   make(map[*yaml.Node]string),
+		sources: make(map[string]fileState),
+	}
+
+	root, err := doc.loadFile(configPath, nil)
+	if err != nil {
+		return doc, err
+	}
+	doc.root = root
+
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i < len(root.Content); i += 2 {
+			if root.Content[i].Value != definitionsKey {
+				continue
+			}
+			if err := doc.collectDefinitions(root.Content[i+1]); err != nil {
+				return doc, err
+			}
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			break
+		}
+	}
+
+	if doc.root, err = doc.resolveRefs(root); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
+
+// loadFile parses the config file into the YAML node and resolves its includes and environment variables
+func (d *document) loadFile(configPath string, including []string) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range including {
+		if path == absPath {
+			return nil, fmt.Errorf("cyclic include of %s", configPath)
+		}
+	}
+
+	// the file is stated before it's read, so that the changes made while it's read are not missed
+	d.sources[configPath] = statFile(configPath)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var file yaml.Node
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
+	}
+	if len(file.Content) == 0 {
+		return nil, fmt.Errorf("%s: empty config", configPath)
+	}
+
+	root := file.Content[0]
+	if err := d.resolveFile(root, configPath, append(including, absPath)); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func (d *document) resolveFile(node *yaml.Node, configPath string, including []string) error {
+	d.files[node] = configPath
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return d.errorf(node, "aliases are not supported, use %s instead", refKey)
+	case yaml.ScalarNode:
+		value, err := interpolate(node.Value)
+		if err != nil {
+			return d.errorf(node, "%v", err)
+		}
+		if value != node.Value {
+			node.Value = value
+			if node.Style == 0 && node.Tag == "!!str" {
+				// plain scalars are resolved again, so "${PORT:-8080}" can be a number
+				node.Tag = ""
+			}
+		}
+
+		if node.Tag == includeTag {
+			includePath := node.Value
+			if !filepath.IsAbs(includePath) {
+				includePath = filepath.Join(filepath.Dir(configPath), includePath)
+			}
+			included, err := d.loadFile(includePath, including)
+			if err != nil {
+				return d.errorf(node, "unable to include %s: %w", node.Value, err)
+			}
+			*node = *included
+			d.files[node] = d.files[included]
+		}
+	default:
+		for _, child := range node.Content {
+			if err := d.resolveFile(child, configPath, including); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *document) collectDefinitions(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return d.errorf(node, "%s must be a mapping", definitionsKey)
+	}
+	d.definitions = make(map[string]*yaml.Node)
+	for i := 0; i < len(node.Content); i += 2 {
+		d.definitions[node.Content[i].Value] = node.Content[i+1]
+	}
+	return nil
+}
+
+// resolveRefs returns the node with all the references to definitions replaced
+func (d *document) resolveRefs(node *yaml.Node) (*yaml.Node, error) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i < len(node.Content); i += 2 {
+			if node.Content[i].Value == refKey {
+				return d.resolveRef(node, i)
+			}
+		}
+	}
+
+	for i, child := range node.Content {
+		resolved, err := d.resolveRefs(child)
+		if err != nil {
+			return nil, err
+		}
+		node.Content[i] = resolved
+	}
+	return node, nil
+}
+
+// resolveRef replaces the mapping, that has the reference at the index refIdx, with the copy
+// of the referenced definition, that is merged with the other keys of the mapping
+func (d *document) resolveRef(node *yaml.Node, refIdx int) (*yaml.Node, error) {
+	refNode := node.Content[refIdx+1]
+	name := refNode.Value
+
+	definition, exist := d.definitions[name]
+	if !exist {
+		return nil, d.errorf(refNode, "unknown definition: %s", name)
+	}
+	for _, resolving := range d.resolving {
+		if resolving == name {
+			return nil, d.errorf(refNode, "cyclic reference to definition: %s", name)
+		}
+	}
+
+	d.resolving = append(d.resolving, name)
+	resolved, err := d.resolveRefs(d.copyNode(definition))
+	d.resolving = d.resolving[:len(d.resolving)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := &yaml.Node{Kind: yaml.MappingNode}
+	overrides.Content = append(append(overrides.Content, node.Content[:refIdx]...), node.Content[refIdx+2:]...)
+	if len(overrides.Content) == 0 {
+		return resolved, nil
+	}
+	if resolved.Kind != yaml.MappingNode {
+		return nil, d.errorf(refNode, "definition %s is not a mapping and can't be overridden", name)
+	}
+	if overrides, err = d.resolveRefs(overrides); err != nil {
+		return nil, err
+	}
+	merge(resolved, overrides)
+	return resolved, nil
+}
+
+// copyNode returns the deep copy of the node
+func (d *document) copyNode(node *yaml.Node) *yaml.Node {
+	copied := *node
+	copied.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		copied.Content[i] = d.copyNode(child)
+	}
+	d.files[&copied] = d.files[node]
+	return &copied
+}
+
+// merge merges the overrides into the mapping node: the nested mappings are merged recursively
+// and other values of the node are replaced with the values of the overrides
+func merge(node *yaml.Node, overrides *yaml.Node) {
+	for i := 0; i < len(overrides.Content); i += 2 {
+		key, value := overrides.Content[i], overrides.Content[i+1]
+
+		merged := false
+		for j := 0; j < len(node.Content); j += 2 {
+			if node.Content[j].Value != key.Value {
+				continue
+			}
+			if node.Content[j+1].Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				merge(node.Content[j+1], value)
+			} else {
+				node.Content[j+1] = value
+			}
+			merged = true
+			break
+		}
+		if !merged {
+			node.Content = append(node.Content, key, value)
+		}
+	}
+}
+
+func (d *document) errorf(node *yaml.Node, format string, args ...interface{}) error {
+	return &LocationError{File: d.files[node], Line: node.Line, Err: fmt.Errorf(format, args...)}
+}
+
+// locate returns the location of the config node at the path, such as "$.routes[1].endpoint".
+// If the node doesn't exist, the location of its closest existing parent is returned
+func (d *document) locate(path string) (string, int) {
+	node := d.root
+	for _, segment := range splitPath(path) {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if idx, err := strconv.Atoi(segment); err == nil && idx >= 0 && idx < len(node.Content) {
+				next = node.Content[idx]
+			}
+		}
+		if next == nil {
+			break
+		}
+		node = next
+	}
+	return d.files[node], node.Line
+}
+
+// splitPath splits the path, such as "$.routes[1].endpoint", into its segments: ["routes", "1", "endpoint"]
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return nil
+	}
+	return strings.FieldsFunc(strings.NewReplacer("[", ".", "]", "").Replace(path), func(r rune) bool {
+		return r == '.'
+	})
+}
+
+// interpolate replaces the references to the environment variables in the value
+func interpolate(value string) (string, error) {
+	var err error
+	result := envPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		groups := envPattern.FindStringSubmatch(match)
+		name, hasDefault, defaultValue := groups[1], groups[2] != "", groups[3]
+
+		envValue, isSet := os.LookupEnv(name)
+		switch {
+		case (!isSet || envValue == "") && hasDefault:
+			return defaultValue
+		case !isSet && err == nil:
+			err = fmt.Errorf("environment variable %s is not set", name)
+		}
+		return envValue
+	})
+	return result, err
+}
@@ -0,0 +1,258 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/gojek/fiber/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	return dir
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Setenv("FIBER_TEST_HOST", "backend.local")
+	t.Setenv("FIBER_TEST_EMPTY", "")
+
+	tests := []struct {
+		name        string
+		files       map[string]string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "environment variables",
+			files: map[string]string{
+				"fiber.yaml": `
+type: PROXY
+id: proxy
+endpoint: http://${FIBER_TEST_HOST}:${FIBER_TEST_PORT:-8080}/path
+timeout: ${FIBER_TEST_EMPTY:-20s}
+weight: ${FIBER_TEST_WEIGHT:-5}
+quoted: "${FIBER_TEST_WEIGHT:-5}"
+escaped: $${FIBER_TEST_HOST}`,
+			},
+			expected: `
+type: PROXY
+id: proxy
+endpoint: http://backend.local:8080/path
+timeout: 20s
+weight: 5
+quoted: "5"
+escaped: ${FIBER_TEST_HOST}`,
+		},
+		{
+			name: "unset environment variable",
+			files: map[string]string{
+				"fiber.yaml": `
+type: PROXY
+id: proxy
+endpoint: http://${FIBER_TEST_UNSET}/path`,
+			},
+			expectedErr: "fiber.yaml:4: environment variable FIBER_TEST_UNSET is not set",
+		},
+		{
+			name: "includes",
+			files: map[string]string{
+				"fiber.yaml": `
+type: EAGER_ROUTER
+id: router
+strategy: !include strategies/random.yaml
+routes:
+  - !include routes/route_a.yaml
+  - id: route_b
+    type: PROXY
+    endpoint: http://route-b`,
+				"strategies/random.yaml": `type: fiber.RandomRoutingStrategy`,
+				"routes/route_a.yaml": `
+id: route_a
+type: PROXY
+endpoint: http://${FIBER_TEST_HOST}`,
+			},
+			expected: `
+type: EAGER_ROUTER
+id: router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: http://backend.local
+  - id: route_b
+    type: PROXY
+    endpoint: http://route-b`,
+		},
+		{
+			name: "missing include",
+			files: map[string]string{
+				"fiber.yaml": `
+type: EAGER_ROUTER
+id: router
+routes:
+  - !include routes/unknown.yaml`,
+			},
+			expectedErr: "fiber.yaml:5: unable to include routes/unknown.yaml: open",
+		},
+		{
+			name: "cyclic include",
+			files: map[string]string{
+				"fiber.yaml": `
+type: COMBINER
+id: combiner
+routes:
+  - !include fiber.yaml`,
+			},
+			expectedErr: "fiber.yaml:5: unable to include fiber.yaml: cyclic include of",
+		},
+		{
+			name: "definitions",
+			files: map[string]string{
+				"fiber.yaml": `
+definitions:
+  random:
+    type: fiber.RandomRoutingStrategy
+  proxy:
+    type: PROXY
+    timeout: 20s
+    endpoint: http://route
+    http2:
+      h2c: true
+      ping_timeout: 5s
+  router:
+    type: LAZY_ROUTER
+    strategy:
+      $ref: random
+type: EAGER_ROUTER
+id: router
+strategy:
+  $ref: random
+routes:
+  - $ref: proxy
+    id: route_a
+  - $ref: proxy
+    id: route_b
+    endpoint: http://route-b
+    http2:
+      ping_timeout: 10s
+  - $ref: router
+    id: nested
+    routes:
+      - $ref: proxy
+        id: route_c`,
+			},
+			expected: `
+type: EAGER_ROUTER
+id: router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - type: PROXY
+    id: route_a
+    timeout: 20s
+    endpoint: http://route
+    http2:
+      h2c: true
+      ping_timeout: 5s
+  - type: PROXY
+    id: route_b
+    timeout: 20s
+    endpoint: http://route-b
+    http2:
+      h2c: true
+      ping_timeout: 10s
+  - type: LAZY_ROUTER
+    id: nested
+    strategy:
+      type: fiber.RandomRoutingStrategy
+    routes:
+      - type: PROXY
+        id: route_c
+        timeout: 20s
+        endpoint: http://route
+        http2:
+          h2c: true
+          ping_timeout: 5s`,
+		},
+		{
+			name: "unknown definition",
+			files: map[string]string{
+				"fiber.yaml": `
+type: LAZY_ROUTER
+id: router
+strategy:
+  $ref: unknown`,
+			},
+			expectedErr: "fiber.yaml:5: unknown definition: unknown",
+		},
+		{
+			name: "cyclic definition",
+			files: map[string]string{
+				"fiber.yaml": `
+definitions:
+  router:
+    type: COMBINER
+    routes:
+      - $ref: router
+$ref: router`,
+			},
+			expectedErr: "fiber.yaml:6: cyclic reference to definition: router",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+
+			data, err := config.LoadFile(filepath.Join(dir, "fiber.yaml"))
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), filepath.Join(dir, tt.expectedErr))
+				return
+			}
+			require.NoError(t, err)
+
+			var expected, actual interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.expected), &expected))
+			require.NoError(t, yaml.Unmarshal(data, &actual))
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestValidateFile_Location(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"fiber.yaml": `
+type: COMBINER
+id: combiner
+fan_in:
+  type: fiber.FastestResponseFanIn
+routes:
+  - !include route.yaml`,
+		"route.yaml": `
+id: route_a
+type: PROXY
+protocol: grpc`,
+	})
+
+	problems, err := config.ValidateFile(filepath.Join(dir, "fiber.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []config.Problem{
+		{
+			Path:    "$.routes[0].endpoint",
+			Message: "missing endpoint",
+			File:    filepath.Join(dir, "route.yaml"),
+			Line:    2,
+		},
+	}, problems)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/types"
+	"gopkg.in/yaml.v3"
+)
+
+// Marshal exports the component, with all of its routes, into the fiber config, that
+// InitComponentFromConfig creates an equivalent component from. The components must implement
+// fiber.Describer, as all the built-in components do, and their routing strategies and fan-ins
+// must be installed in the types registry. The properties of the strategies and fan-ins are
+// described by types.Properties
+func Marshal(component fiber.Component) ([]byte, error) {
+	node, err := componentNode(component)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(node)
+}
+
+func componentNode(component fiber.Component) (*yaml.Node, error) {
+	describer, ok := component.(fiber.Describer)
+	if !ok {
+		return nil, fmt.Errorf("component %s (%T) can not be described", component.ID(), component)
+	}
+	description := describer.Describe()
+	if description.Type == "" {
+		return nil, fmt.Errorf("component %s (%T) has no type in the fiber config", component.ID(), component)
+	}
+
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if err := appendValue(node, "id", description.ID); err != nil {
+		return nil, err
+	}
+	if err := appendValue(node, "type", description.Type); err != nil {
+		return nil, err
+	}
+	for _, key := range sortedKeys(description.Properties) {
+		if err := appendValue(node, key, description.Properties[key]); err != nil {
+			return nil, fmt.Errorf("component %s: invalid property %s: %w", description.ID, key, err)
+		}
+	}
+
+	if description.Strategy != nil {
+		strategy, err := typedNode(types.RoutingStrategy, description.Strategy)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", description.ID, err)
+		}
+		appendNode(node, "strategy", strategy)
+	}
+	if description.FanIn != nil {
+		fanIn, err := typedNode(types.FanIn, description.FanIn)
+		if err != nil {
+			return nil, fmt.Errorf("component %s: %w", description.ID, err)
+		}
+		appendNode(node, "fan_in", fanIn)
+	}
+
+	if len(description.Routes) > 0 {
+		routes := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, id := range sortedKeys(description.Routes) {
+			route, err := componentNode(description.Routes[id])
+			if err != nil {
+				return nil, err
+			}
+			routes.Content = append(routes.Content, route)
+		}
+		appendNode(node, "routes", routes)
+	}
+	return node, nil
+}
+
+// typedNode describes the routing strategy or fan-in, by the name of its type and its properties
+func typedNode(category types.Category, instance interface{}) (*yaml.Node, error) {
+	name, ok := types.NameOf(category, instance)
+	if !ok {
+		return nil, fmt.Errorf("%s type %T is not installed", category, instance)
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	if err := appendValue(node, "type", name); err != nil {
+		return nil, err
+	}
+
+	properties, err := types.Properties(instance)
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe %s properties: %w", name, err)
+	}
+	if properties != nil {
+		if err := appendValue(node, "properties", properties); err != nil {
+			return nil, fmt.Errorf("invalid %s properties: %w", name, err)
+		}
+	}
+	return node, nil
+}
+
+// appendValue adds the value to the mapping node. The value is converted through its JSON encoding,
+// so that it's represented the same way, as it's parsed from the config
+func appendValue(node *yaml.Node, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+
+	valueNode := &yaml.Node{}
+	if err := valueNode.Encode(generic); err != nil {
+		return err
+	}
+	appendNode(node, key, valueNode)
+	return nil
+}
+
+func appendNode(node *yaml.Node, key string, value *yaml.Node) {
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
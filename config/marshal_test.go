@@ -0,0 +1,146 @@
+package config_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/extras"
+	"github.com/gojek/fiber/grpc"
+	fiberHTTP "github.com/gojek/fiber/http"
+	"github.com/gojek/fiber/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshal(t *testing.T) {
+	httpDispatcher, err := fiberHTTP.NewDispatcherWithOptions(
+		&http.Client{Timeout: 2 * time.Second},
+		fiberHTTP.DispatcherOptions{PreserveErrors: true})
+	require.NoError(t, err)
+	httpCaller, err := fiber.NewCaller("route_a", httpDispatcher)
+	require.NoError(t, err)
+
+	grpcDispatcher, err := grpc.NewDispatcher(grpc.DispatcherConfig{
+		ServiceMethod: "mypackage.Greeter/SayHello",
+		Endpoint:      "localhost:50555",
+		Timeout:       time.Second,
+		Streaming:     grpc.ServerStreaming,
+	})
+	require.NoError(t, err)
+	grpcCaller, err := fiber.NewCaller("route_b", grpcDispatcher)
+	require.NoError(t, err)
+
+	httpProxy := fiber.NewProxy(fiber.NewBackend("route_a", "http://localhost:8080/route-a"), httpCaller)
+	httpProxy.SetTimeout(500 * time.Millisecond)
+
+	combiner := fiber.NewCombiner("combiner").WithFanIn(&extras.FastestResponseFanIn{})
+	combiner.SetRoutes(map[string]fiber.Component{
+		"route_b": fiber.NewProxy(nil, grpcCaller),
+		"route_a": httpProxy,
+	})
+
+	router := fiber.NewLazyRouter("router")
+	router.SetStrategy(&defaultRouteStrategy{DefaultRoute: "combiner"})
+	router.SetRoutes(map[string]fiber.Component{"combiner": combiner})
+
+	require.NoError(t, types.InstallType("test.DefaultRouteStrategy", &defaultRouteStrategy{}))
+	data, err := config.Marshal(router)
+	require.NoError(t, err)
+	assert.Equal(t, `id: router
+type: LAZY_ROUTER
+strategy:
+    type: test.DefaultRouteStrategy
+    properties:
+        default_route: combiner
+routes:
+    - id: combiner
+      type: COMBINER
+      fan_in:
+        type: fiber.FastestResponseFanIn
+      routes:
+        - id: route_a
+          type: PROXY
+          component_timeout: 500ms
+          endpoint: http://localhost:8080/route-a
+          preserve_errors: true
+          timeout: 2s
+        - id: route_b
+          type: PROXY
+          endpoint: localhost:50555
+          protocol: GRPC
+          service_method: mypackage.Greeter/SayHello
+          streaming: server
+          timeout: 1s
+`, string(data))
+
+	// the exported config creates the same graph
+	configPath := filepath.Join(t.TempDir(), "fiber.yaml")
+	require.NoError(t, os.WriteFile(configPath, data, 0600))
+	component, err := config.InitComponentFromConfig(configPath)
+	require.NoError(t, err)
+
+	exported, err := config.Marshal(component)
+	require.NoError(t, err)
+	assert.Equal(t, string(data), string(exported))
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"fiber.yaml": `
+type: EAGER_ROUTER
+id: router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: route_a
+    type: PROXY
+    timeout: 20s
+    endpoint: http://localhost:8080
+    propagate_deadline: true
+    http2:
+      h2c: true
+      ping_timeout: 5s
+  - id: route_b
+    type: PROXY
+    timeout: 10s
+    protocol: GRPC
+    endpoint: localhost:50555
+    transparent: true
+    allowed_methods:
+      - /mypackage.Greeter/*`,
+	})
+	configPath := filepath.Join(dir, "fiber.yaml")
+
+	component, err := config.InitComponentFromConfig(configPath)
+	require.NoError(t, err)
+	data, err := config.Marshal(component)
+	require.NoError(t, err)
+
+	exportedPath := filepath.Join(dir, "exported.yaml")
+	require.NoError(t, os.WriteFile(exportedPath, data, 0600))
+	expected, err := config.ParseTreeFile(configPath)
+	require.NoError(t, err)
+	actual, err := config.ParseTreeFile(exportedPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
+
+func TestMarshal_Errors(t *testing.T) {
+	router := fiber.NewEagerRouter("router")
+	// strategies are resolved by the type they are installed with
+	type unknownStrategy struct{ defaultRouteStrategy }
+	router.SetStrategy(&unknownStrategy{})
+
+	_, err := config.Marshal(router)
+	assert.EqualError(t, err, "component router: ROUTING_STRATEGY type *config_test.unknownStrategy is not installed")
+
+	caller, err := fiber.NewCaller("caller", &fiberHTTP.Dispatcher{})
+	require.NoError(t, err)
+	_, err = config.Marshal(caller)
+	assert.EqualError(t, err, "component caller (*fiber.Caller) has no type in the fiber config")
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/ghodss/yaml"
+	"github.com/gojek/fiber"
+)
+
+// ComponentFactory creates the component of a custom type (see RegisterComponentType). The config is
+// a pointer to the config struct, that the type is registered with, parsed from the component's config.
+// The routes are the components, created from the "routes" of the config (none, if they are not defined)
+type ComponentFactory func(id string, config interface{}, routes map[string]fiber.Component) (fiber.Component, error)
+
+type componentType struct {
+	config  reflect.Type
+	factory ComponentFactory
+}
+
+// builtinComponentTypes are the types of the components, created by the config package itself
+var builtinComponentTypes = map[string]bool{
+	"PROXY":          true,
+	"EAGER_ROUTER":   true,
+	"LAZY_ROUTER":    true,
+	"COMBINER":       true,
+	"FAULT_INJECTOR": true,
+}
+
+var customComponentTypes = map[string]componentType{}
+
+// RegisterComponentType registers the custom component type, so it can be used in the fiber config,
+// at any level of the routing graph, the same way as the built-in types. The config struct (or a pointer
+// to it) describes the properties of the component: it's parsed from the component's config with its
+// JSON tags, and included into the JSON Schema of the config (see JSONSchema). The "routes" of the
+// component are parsed as fiber components and passed to the factory. The built-in types can't be replaced
+func RegisterComponentType(name string, config interface{}, factory ComponentFactory) error {
+	if name == "" {
+		return errors.New("component type name is required")
+	}
+	if builtinComponentTypes[name] {
+		return fmt.Errorf("component type %s is built-in", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("component type %s: factory is required", name)
+	}
+	configType := reflect.TypeOf(config)
+	if configType != nil && configType.Kind() == reflect.Ptr {
+		configType = configType.Elem()
+	}
+	if configType == nil || configType.Kind() != reflect.Struct {
+		return fmt.Errorf("component type %s: config must be a struct, got %T", name, config)
+	}
+
+	customComponentTypes[name] = componentType{config: configType, factory: factory}
+	return nil
+}
+
+// ComponentTypes returns the sorted names of the component types, registered with RegisterComponentType
+func ComponentTypes() []string {
+	return sortedKeys(customComponentTypes)
+}
+
+// CustomConfig is used to parse the configuration for a component of a custom type (see RegisterComponentType)
+type CustomConfig struct {
+	ComponentConfig
+	Routes Routes `json:"routes"`
+	// Config is a pointer to the config struct of the custom type
+	Config interface{} `json:"-"`
+
+	factory ComponentFactory
+}
+
+func parseCustomConfig(data []byte, typeName string, routesCount int) (Config, error) {
+	componentType, exist := customComponentTypes[typeName]
+	if !exist {
+		return nil, &fieldError{field: "type", err: fmt.Errorf("unknown component type: %s", typeName)}
+	}
+
+	cfg := &CustomConfig{
+		Routes:  make(Routes, routesCount),
+		Config:  reflect.New(componentType.config).Interface(),
+		factory: componentType.factory,
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	// the properties of the component are parsed without its routes, that are already parsed
+	var properties map[string]interface{}
+	if err := yaml.Unmarshal(data, &properties); err != nil {
+		return nil, err
+	}
+	delete(properties, "routes")
+	propertiesData, err := yaml.Marshal(properties)
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(propertiesData, cfg.Config); err != nil {
+		return nil, fmt.Errorf("component %s: %w", cfg.ID, err)
+	}
+	return cfg, nil
+}
+
+func (c *CustomConfig) routes() Routes {
+	return c.Routes
+}
+
+func (c *CustomConfig) initComponent(b *builder, path string) (fiber.Component, error) {
+	routes, err := b.routes(path, c.Routes)
+	if err != nil {
+		return nil, err
+	}
+	component, err := c.factory(c.ID, c.Config, routes)
+	if err != nil {
+		return nil, fmt.Errorf("component %s: %w", c.ID, err)
+	}
+	return component, nil
+}
+
+// namedComponentType is the type of the components, that can be defined in the config, with its config struct
+type namedComponentType struct {
+	name   string
+	config reflect.Type
+}
+
+// componentTypes returns the types of the components, that can be defined in the config: the built-in
+// types, followed by the custom types sorted by their names
+func componentTypes() []namedComponentType {
+	types := []namedComponentType{
+		{name: "PROXY", config: reflect.TypeOf(ProxyConfig{})},
+		{name: "EAGER_ROUTER", config: reflect.TypeOf(RouterConfig{})},
+		{name: "LAZY_ROUTER", config: reflect.TypeOf(RouterConfig{})},
+		{name: "COMBINER", config: reflect.TypeOf(CombinerConfig{})},
+		{name: "FAULT_INJECTOR", config: reflect.TypeOf(FaultInjectorConfig{})},
+	}
+	for _, name := range ComponentTypes() {
+		types = append(types, namedComponentType{name: name, config: customComponentTypes[name].config})
+	}
+	return types
+}
@@ -0,0 +1,176 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	fiberhttp "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultRouterConfig is the config of the custom DEFAULT_ROUTER component type, a lazy router,
+// that always selects the default route
+type defaultRouterConfig struct {
+	DefaultRoute string `json:"default_route" required:"true"`
+}
+
+func newDefaultRouter(id string, cfg interface{}, routes map[string]fiber.Component) (fiber.Component, error) {
+	defaultRoute := cfg.(*defaultRouterConfig).DefaultRoute
+	if _, exist := routes[defaultRoute]; !exist {
+		return nil, errors.New("unknown default route: " + defaultRoute)
+	}
+	router := fiber.NewLazyRouter(id)
+	router.SetRoutes(routes)
+	router.SetStrategy(&defaultRouteStrategy{DefaultRoute: defaultRoute})
+	return router, nil
+}
+
+func TestRegisterComponentType(t *testing.T) {
+	tests := []struct {
+		name           string
+		typeName       string
+		config         interface{}
+		factory        config.ComponentFactory
+		expectedErrMsg string
+	}{
+		{
+			name:     "success",
+			typeName: "DEFAULT_ROUTER",
+			config:   defaultRouterConfig{},
+			factory:  newDefaultRouter,
+		},
+		{
+			name:           "built-in type",
+			typeName:       "PROXY",
+			config:         defaultRouterConfig{},
+			factory:        newDefaultRouter,
+			expectedErrMsg: "component type PROXY is built-in",
+		},
+		{
+			name:           "missing factory",
+			typeName:       "DEFAULT_ROUTER",
+			config:         &defaultRouterConfig{},
+			expectedErrMsg: "component type DEFAULT_ROUTER: factory is required",
+		},
+		{
+			name:           "invalid config",
+			typeName:       "DEFAULT_ROUTER",
+			config:         "default_route",
+			factory:        newDefaultRouter,
+			expectedErrMsg: "component type DEFAULT_ROUTER: config must be a struct, got string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := config.RegisterComponentType(tt.typeName, tt.config, tt.factory)
+			if tt.expectedErrMsg != "" {
+				assert.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, config.ComponentTypes(), tt.typeName)
+		})
+	}
+}
+
+func TestFromConfig_CustomComponentType(t *testing.T) {
+	require.NoError(t, config.RegisterComponentType("DEFAULT_ROUTER", &defaultRouterConfig{}, newDefaultRouter))
+
+	newBackend := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	backendA, backendB := newBackend("route_a"), newBackend("route_b")
+	defer backendA.Close()
+	defer backendB.Close()
+
+	tests := []struct {
+		name             string
+		config           string
+		expectedResponse string
+		expectedErrMsg   string
+	}{
+		{
+			name: "nested custom component",
+			config: `
+type: EAGER_ROUTER
+id: eager_router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: default_router
+    type: DEFAULT_ROUTER
+    default_route: route_b
+    routes:
+      - id: route_a
+        type: PROXY
+        endpoint: ` + backendA.URL + `
+      - id: route_b
+        type: PROXY
+        endpoint: ` + backendB.URL,
+			expectedResponse: "route_b",
+		},
+		{
+			name: "factory error",
+			config: `
+type: DEFAULT_ROUTER
+id: default_router
+default_route: route_c
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: ` + backendA.URL,
+			expectedErrMsg: "component default_router: unknown default route: route_c",
+		},
+		{
+			name: "invalid config",
+			config: `
+type: DEFAULT_ROUTER
+id: default_router
+default_route: [route_a]`,
+			expectedErrMsg: "component default_router: error unmarshaling JSON: " +
+				"json: cannot unmarshal array into Go struct field defaultRouterConfig.default_route of type string",
+		},
+		{
+			name: "unknown component type",
+			config: `
+type: UNKNOWN
+id: unknown`,
+			expectedErrMsg: "unknown component type: UNKNOWN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configPath := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(configPath, []byte(tt.config), 0600))
+
+			component, err := config.InitComponentFromConfig(configPath)
+			if tt.expectedErrMsg != "" {
+				require.EqualError(t, err, tt.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			httpReq, err := http.NewRequest(http.MethodGet, backendA.URL, http.NoBody)
+			require.NoError(t, err)
+			req, err := fiberhttp.NewHTTPRequest(httpReq)
+			require.NoError(t, err)
+
+			resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+			require.True(t, ok)
+			assert.True(t, resp.IsSuccess())
+			assert.Equal(t, tt.expectedResponse, string(resp.Payload()))
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gojek/fiber"
+)
+
+var errClosed = errors.New("fiber: reloadable component is closed")
+
+// ReloadOptions captures a set of options of the ReloadableComponent
+type ReloadOptions struct {
+	// WatchInterval is the interval, at which the config file and the files it includes are checked
+	// for changes. The config is only reloaded on demand (see ReloadableComponent.Reload), if zero
+	WatchInterval time.Duration
+	// OnReload, if set, is called after every reload attempt, with the error if the
+	// new config couldn't be loaded, in which case the previous routing graph remains in use
+	OnReload func(err error)
+}
+
+// ReloadableComponent is the root fiber component, that is initialised from the config file
+// and can be re-initialised from it, when the config changes. The new routing graph is swapped
+// in atomically: requests, that are in flight, are completed by the old graph, and the resources
+// of the old graph (such as grpc connections, and the interceptors declared in the config, that
+// implement io.Closer) are released once these requests are completed
+type ReloadableComponent struct {
+	fiber.BaseFiberType
+
+	configPath string
+	options    ReloadOptions
+
+	current atomic.Pointer[graph]
+
+	// mu serializes reloads and guards interceptors and the state of the config files
+	mu           sync.Mutex
+	interceptors []recursiveInterceptors
+	// sources are the states of the config file and the files it included, when the config was last loaded
+	sources map[string]fileState
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+type recursiveInterceptors struct {
+	recursive    bool
+	interceptors []fiber.Interceptor
+}
+
+// graph is a single generation of the routing graph, with the count of requests in flight
+type graph struct {
+	fiber.Component
+	// interceptors are the interceptors, declared in the config, that are closed with the graph
+	interceptors []io.Closer
+
+	mu       sync.Mutex
+	inFlight int
+	retired  bool
+	drained  chan struct{}
+}
+
+func newGraph(component fiber.Component, interceptors []io.Closer) *graph {
+	return &graph{
+		Component:    component,
+		interceptors: interceptors,
+		drained:      make(chan struct{}),
+	}
+}
+
+// acquire registers a new request in flight. It returns false, if the graph has already been retired
+func (g *graph) acquire() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.retired {
+		return false
+	}
+	g.inFlight++
+	return true
+}
+
+func (g *graph) release() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inFlight--
+	if g.retired && g.inFlight == 0 {
+		close(g.drained)
+	}
+}
+
+// retire stops the graph from accepting new requests and releases its resources,
+// once all the requests in flight are completed
+func (g *graph) retire() {
+	g.mu.Lock()
+	g.retired = true
+	if g.inFlight == 0 {
+		close(g.drained)
+	}
+	g.mu.Unlock()
+
+	go func() {
+		<-g.drained
+		if closer, ok := g.Component.(io.Closer); ok {
+			_ = closer.Close()
+		}
+		for _, interceptor := range g.interceptors {
+			_ = interceptor.Close()
+		}
+	}()
+}
+
+// NewReloadableComponent initialises the routing graph from the config file at configPath
+// and, if options.WatchInterval is set, starts watching the file for changes
+func NewReloadableComponent(configPath string, options ReloadOptions) (*ReloadableComponent, error) {
+	c := &ReloadableComponent{
+		configPath: configPath,
+		options:    options,
+		stop:       make(chan struct{}),
+	}
+
+	b := newBuilder(false)
+	component, sources, err := initComponentFromFile(configPath, b)
+	if err != nil {
+		return nil, err
+	}
+	c.sources = sources
+	c.current.Store(newGraph(component, b.closers))
+
+	if options.WatchInterval > 0 {
+		go c.watch()
+	}
+	return c, nil
+}
+
+// ID returns the id of the root component of the current routing graph
+func (c *ReloadableComponent) ID() string {
+	return c.current.Load().ID()
+}
+
+// Kind returns the kind of the root component of the current routing graph
+func (c *ReloadableComponent) Kind() fiber.ComponentKind {
+	return c.current.Load().Kind()
+}
+
+// Dispatch dispatches the request by the current routing graph
+func (c *ReloadableComponent) Dispatch(ctx context.Context, req fiber.Request) fiber.ResponseQueue {
+	current := c.current.Load()
+	for !current.acquire() {
+		if next := c.current.Load(); next != current {
+			// the graph has just been swapped, the new one is used instead
+			current = next
+			continue
+		}
+		return fiber.NewResponseQueueFromResponses(fiber.NewErrorResponse(errClosed))
+	}
+
+	queue := current.Dispatch(ctx, req)
+	go func() {
+		defer current.release()
+		for range queue.Iter() {
+		}
+	}()
+	return queue
+}
+
+// Current returns the root component of the current routing graph. The changes made to it
+// (such as disabled routes, see fiber.RouteController) are lost, when the graph is reloaded
+func (c *ReloadableComponent) Current() fiber.Component {
+	return c.current.Load().Component
+}
+
+// AddInterceptor adds the interceptors to the current routing graph. They are also
+// added to every graph, that is initialised on reload
+func (c *ReloadableComponent) AddInterceptor(recursive bool, interceptors ...fiber.Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interceptors = append(c.interceptors, recursiveInterceptors{recursive, interceptors})
+	c.current.Load().AddInterceptor(recursive, interceptors...)
+}
+
+// Reload re-initialises the routing graph from the config file and swaps it with the current one.
+// If the config is invalid, an error is returned and the current graph remains in use
+func (c *ReloadableComponent) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.reload()
+	if c.options.OnReload != nil {
+		c.options.OnReload(err)
+	}
+	return err
+}
+
+func (c *ReloadableComponent) reload() error {
+	select {
+	case <-c.stop:
+		return errClosed
+	default:
+	}
+
+	b := newBuilder(false)
+	component, sources, err := initComponentFromFile(c.configPath, b)
+	// the state of the files is recorded even if the config is invalid, so it's not reloaded
+	// by the watcher again, until they change
+	c.sources = sources
+	if err != nil {
+		return err
+	}
+
+	for _, i := range c.interceptors {
+		component.AddInterceptor(i.recursive, i.interceptors...)
+	}
+	c.current.Swap(newGraph(component, b.closers)).retire()
+	return nil
+}
+
+// watch reloads the config, whenever the modification time or the size of the config file,
+// or of any of the files it includes, changes
+func (c *ReloadableComponent) watch() {
+	ticker := time.NewTicker(c.options.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if c.configChanged() {
+				_ = c.Reload()
+			}
+		}
+	}
+}
+
+func (c *ReloadableComponent) configChanged() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for path, state := range c.sources {
+		current := statFile(path)
+		if !current.exists {
+			// the file is either still missing, or in the middle of being replaced, it's checked again on the next tick
+			continue
+		}
+		if !state.exists || !current.modTime.Equal(state.modTime) || current.size != state.size {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops watching the config file and releases the resources of the current
+// routing graph, once the requests in flight are completed
+func (c *ReloadableComponent) Close() error {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		close(c.stop)
+		c.current.Load().retire()
+	})
+	return nil
+}
@@ -0,0 +1,232 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	fiberhttp "github.com/gojek/fiber/http"
+	"github.com/gojek/fiber/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBackend(t *testing.T, name string, latency time.Duration) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(latency)
+		_, _ = w.Write([]byte(name))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeProxyConfig(t *testing.T, path string, endpoint string) {
+	cfg := "type: PROXY\nid: proxy_name\ntimeout: 5s\nendpoint: " + endpoint
+	require.NoError(t, os.WriteFile(path, []byte(cfg), 0600))
+}
+
+func dispatch(t *testing.T, component fiber.Component) fiber.Response {
+	httpReq, err := http.NewRequest(http.MethodGet, "http://localhost", http.NoBody)
+	require.NoError(t, err)
+	req, err := fiberhttp.NewHTTPRequest(httpReq)
+	require.NoError(t, err)
+
+	resp, ok := <-component.Dispatch(context.Background(), req).Iter()
+	require.True(t, ok)
+	return resp
+}
+
+func TestReloadableComponent_Reload(t *testing.T) {
+	first := newBackend(t, "first", 100*time.Millisecond)
+	second := newBackend(t, "second", 0)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeProxyConfig(t, configPath, first.URL)
+
+	var reloadErrs []error
+	component, err := config.NewReloadableComponent(configPath, config.ReloadOptions{
+		OnReload: func(err error) {
+			reloadErrs = append(reloadErrs, err)
+		},
+	})
+	require.NoError(t, err)
+	defer component.Close()
+	assert.Equal(t, "proxy_name", component.ID())
+
+	// the request in flight is completed by the old graph
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.Equal(t, "first", string(dispatch(t, component).Payload()))
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	writeProxyConfig(t, configPath, second.URL)
+	require.NoError(t, component.Reload())
+	assert.Equal(t, "second", string(dispatch(t, component).Payload()))
+	wg.Wait()
+
+	// invalid config is rejected, the current graph remains in use
+	require.NoError(t, os.WriteFile(configPath, []byte("type: UNKNOWN"), 0600))
+	assert.Error(t, component.Reload())
+	assert.Equal(t, "second", string(dispatch(t, component).Payload()))
+
+	require.Len(t, reloadErrs, 2)
+	assert.NoError(t, reloadErrs[0])
+	assert.Error(t, reloadErrs[1])
+
+	require.NoError(t, component.Close())
+	assert.False(t, dispatch(t, component).IsSuccess())
+	assert.Error(t, component.Reload())
+}
+
+func TestReloadableComponent_Watch(t *testing.T) {
+	first := newBackend(t, "first", 0)
+	second := newBackend(t, "second", 0)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeProxyConfig(t, configPath, first.URL)
+
+	reloaded := make(chan error, 1)
+	component, err := config.NewReloadableComponent(configPath, config.ReloadOptions{
+		WatchInterval: 10 * time.Millisecond,
+		OnReload: func(err error) {
+			reloaded <- err
+		},
+	})
+	require.NoError(t, err)
+	defer component.Close()
+	assert.Equal(t, "first", string(dispatch(t, component).Payload()))
+
+	// the trailing slash changes the size of the file, in case its modification time is not changed
+	writeProxyConfig(t, configPath, second.URL+"/")
+	select {
+	case err := <-reloaded:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "config was not reloaded")
+	}
+	assert.Equal(t, "second", string(dispatch(t, component).Payload()))
+}
+
+func TestReloadableComponent_WatchIncludes(t *testing.T) {
+	first := newBackend(t, "first", 0)
+	second := newBackend(t, "second", 0)
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+type: COMBINER
+id: combiner
+fan_in:
+  type: fiber.FastestResponseFanIn
+routes:
+  - !include route.yaml`), 0600))
+	writeProxyConfig(t, filepath.Join(dir, "route.yaml"), first.URL)
+
+	reloaded := make(chan error, 1)
+	component, err := config.NewReloadableComponent(configPath, config.ReloadOptions{
+		WatchInterval: 10 * time.Millisecond,
+		OnReload: func(err error) {
+			reloaded <- err
+		},
+	})
+	require.NoError(t, err)
+	defer component.Close()
+	assert.Equal(t, "first", string(dispatch(t, component).Payload()))
+
+	// the root config is not changed, only the file it includes
+	writeProxyConfig(t, filepath.Join(dir, "route.yaml"), second.URL+"/")
+	select {
+	case err := <-reloaded:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		require.Fail(t, "config was not reloaded")
+	}
+	assert.Equal(t, "second", string(dispatch(t, component).Payload()))
+}
+
+// closingInterceptor counts the interceptors, that are closed, by their names
+type closingInterceptor struct {
+	fiber.NoopBeforeDispatchInterceptor
+	fiber.NoopAfterDispatchInterceptor
+	fiber.NoopAfterCompletionInterceptor
+	Name string `json:"name"`
+}
+
+var (
+	closedMu sync.Mutex
+	closed   = make(map[string]int)
+)
+
+func (i *closingInterceptor) Initialize(properties json.RawMessage) error {
+	return json.Unmarshal(properties, i)
+}
+
+func (i *closingInterceptor) Close() error {
+	closedMu.Lock()
+	defer closedMu.Unlock()
+	closed[i.Name]++
+	return nil
+}
+
+func closedCount(name string) int {
+	closedMu.Lock()
+	defer closedMu.Unlock()
+	return closed[name]
+}
+
+func TestReloadableComponent_CloseInterceptors(t *testing.T) {
+	require.NoError(t, types.InstallType("test.ClosingInterceptor", &closingInterceptor{}))
+	closedMu.Lock()
+	closed = make(map[string]int)
+	closedMu.Unlock()
+	backend := newBackend(t, "backend", 0)
+
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig := func(name string) {
+		cfg := "type: PROXY\nid: proxy_name\nendpoint: " + backend.URL + "\ninterceptors:\n" +
+			"  - type: test.ClosingInterceptor\n    recursive: true\n    properties:\n      name: " + name
+		require.NoError(t, os.WriteFile(configPath, []byte(cfg), 0600))
+	}
+	writeConfig("first")
+
+	component, err := config.NewReloadableComponent(configPath, config.ReloadOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "backend", string(dispatch(t, component).Payload()))
+
+	// the interceptors of the retired graph are closed once
+	writeConfig("second")
+	require.NoError(t, component.Reload())
+	assert.Eventually(t, func() bool {
+		return closedCount("first") == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0, closedCount("second"))
+
+	// the interceptors of the config, that fails to load, are closed right away
+	require.NoError(t, os.WriteFile(configPath, []byte("type: PROXY\nid: proxy_name\nendpoint: "+backend.URL+
+		"\ninterceptors:\n  - type: test.ClosingInterceptor\n    properties:\n      name: invalid\n"+
+		"  - type: test.UnknownInterceptor"), 0600))
+	assert.Error(t, component.Reload())
+	assert.Equal(t, 1, closedCount("invalid"))
+
+	require.NoError(t, component.Close())
+	assert.Eventually(t, func() bool {
+		return closedCount("second") == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewReloadableComponent(t *testing.T) {
+	_, err := config.NewReloadableComponent(
+		filepath.Join(t.TempDir(), "unknown.yaml"), config.ReloadOptions{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/gojek/fiber/grpc"
+	"github.com/gojek/fiber/protocol"
+	"github.com/gojek/fiber/types"
+)
+
+// JSONSchema returns the JSON Schema (draft-07) of the fiber config. The schema is generated from the
+// config structs and the routing strategies, fan-ins and interceptors, installed in the types registry.
+// The types, that implement types.PropertiesSchemaProvider, contribute the schemas of their
+// properties. The schema describes the configs, where environment variables, includes and
+// references to definitions (see LoadFile) are used in place of whole components or strategies,
+// but not in place of the values of other types, such as booleans
+func JSONSchema() ([]byte, error) {
+	definitions := map[string]interface{}{
+		"component":   componentSchema(),
+		"strategy":    typedSchema(types.RoutingStrategy),
+		"fan_in":      typedSchema(types.FanIn),
+		"interceptor": interceptorSchema(),
+	}
+	for _, componentType := range componentTypes() {
+		if builtinComponentTypes[componentType.name] {
+			definitions[componentType.name] = structSchema(componentType.config)
+		} else {
+			definitions[componentType.name] = customSchema(componentType.config)
+		}
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "fiber config",
+		"allOf":       []interface{}{map[string]interface{}{"$ref": "#/definitions/component"}},
+		"definitions": definitions,
+	}, "", "  ")
+}
+
+// componentSchema is the schema of any component, that selects the schema of the specific component by its type
+func componentSchema() map[string]interface{} {
+	types := componentTypes()
+	names := make([]string, 0, len(types))
+	conditions := make([]interface{}, 0, len(types))
+	for _, componentType := range types {
+		names = append(names, componentType.name)
+		conditions = append(conditions, map[string]interface{}{
+			"if": map[string]interface{}{
+				"properties": map[string]interface{}{"type": map[string]interface{}{"const": componentType.name}},
+				"required":   []string{"type"},
+			},
+			"then": map[string]interface{}{"$ref": "#/definitions/" + componentType.name},
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type": map[string]interface{}{"enum": names},
+		},
+		// the components, that reference definitions, may have no id or type
+		"anyOf": []interface{}{
+			map[string]interface{}{"required": []string{refKey}},
+			map[string]interface{}{"required": []string{"id", "type"}},
+		},
+		"allOf": conditions,
+	}
+}
+
+// typedSchema is the schema of the strategy or fan-in config, with the properties described
+// by the schemas, contributed by the installed types
+func typedSchema(category types.Category) map[string]interface{} {
+	names := types.Names(category)
+	conditions := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		if properties, ok := types.PropertiesSchema(category, name); ok {
+			conditions = append(conditions, map[string]interface{}{
+				"if": map[string]interface{}{
+					"properties": map[string]interface{}{"type": map[string]interface{}{"const": name}},
+					"required":   []string{"type"},
+				},
+				"then": map[string]interface{}{
+					"properties": map[string]interface{}{"properties": properties},
+				},
+			})
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"type":       map[string]interface{}{"enum": names},
+			"properties": map[string]interface{}{},
+			refKey:       map[string]interface{}{"type": "string"},
+		},
+		"additionalProperties": false,
+		"anyOf": []interface{}{
+			map[string]interface{}{"required": []string{refKey}},
+			map[string]interface{}{"required": []string{"type"}},
+		},
+	}
+	if len(conditions) > 0 {
+		schema["allOf"] = conditions
+	}
+	return schema
+}
+
+// interceptorSchema is the schema of the interceptor config, with the properties described by the
+// schemas, contributed by the installed interceptor types
+func interceptorSchema() map[string]interface{} {
+	schema := typedSchema(types.Interceptor)
+	schema["properties"].(map[string]interface{})["recursive"] = map[string]interface{}{"type": "boolean"}
+	return schema
+}
+
+// structSchema is the schema of the component config struct, with the properties for its (and embedded
+// structs') JSON fields. Definitions and shared transports can only be declared in the root config, but
+// since any component may be the root, they are allowed in all of them
+func structSchema(t reflect.Type) map[string]interface{} {
+	schema := nestedSchema(t)
+	properties := schema["properties"].(map[string]interface{})
+	properties[definitionsKey] = map[string]interface{}{"type": "object"}
+	properties[transportsKey] = map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": nestedSchema(reflect.TypeOf(TransportConfig{})),
+	}
+	return schema
+}
+
+// customSchema is the schema of the custom component config struct (see RegisterComponentType),
+// with the properties, that all components have
+func customSchema(t reflect.Type) map[string]interface{} {
+	schema := structSchema(t)
+	properties := schema["properties"].(map[string]interface{})
+	var required []string
+	collectFields(reflect.TypeOf(ComponentConfig{}), properties, &required)
+	properties["routes"] = valueSchema(reflect.TypeOf(Routes{}))
+
+	if fieldsRequired, ok := schema["required"].([]string); ok {
+		for _, name := range fieldsRequired {
+			if name != "id" && name != "type" {
+				required = append(required, name)
+			}
+		}
+	}
+	schema["required"] = required
+	return schema
+}
+
+func collectFields(t reflect.Type, properties map[string]interface{}, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" {
+			collectFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		properties[name] = valueSchema(field.Type)
+		if field.Tag.Get("required") == "true" {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// valueSchema is the schema of the config value of the given type
+func valueSchema(t reflect.Type) map[string]interface{} {
+	switch t {
+	case reflect.TypeOf(Duration(0)):
+		return map[string]interface{}{"type": "string", "pattern": `^(0|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`}
+	case reflect.TypeOf(protocol.HTTP):
+		return map[string]interface{}{"type": "string", "pattern": `^([hH][tT][tT][pP]|[gG][rR][pP][cC])$`}
+	case reflect.TypeOf(grpc.Unary):
+		return map[string]interface{}{"enum": []string{string(grpc.ServerStreaming), string(grpc.BidiStreaming)}}
+	case reflect.TypeOf(Routes{}):
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/definitions/component"}}
+	case reflect.TypeOf(StrategyConfig{}):
+		return map[string]interface{}{"$ref": "#/definitions/strategy"}
+	case reflect.TypeOf(FanInConfig{}):
+		return map[string]interface{}{"$ref": "#/definitions/fan_in"}
+	case reflect.TypeOf(InterceptorConfig{}):
+		return map[string]interface{}{"$ref": "#/definitions/interceptor"}
+	case reflect.TypeOf(TransportConfig{}):
+		// either the transport config, or the name of the shared transport
+		return map[string]interface{}{
+			"oneOf": []interface{}{map[string]interface{}{"type": "string"}, nestedSchema(t)},
+		}
+	case reflect.TypeOf(CredentialsConfig{}):
+		schema := nestedSchema(t)
+		schema["properties"].(map[string]interface{})["type"] = map[string]interface{}{
+			"enum": []string{BearerTokenCredentials, TokenFileCredentials},
+		}
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return valueSchema(t.Elem())
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": valueSchema(t.Elem())}
+	case reflect.Struct:
+		return nestedSchema(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// nestedSchema is the schema of the config struct, nested into a component config
+func nestedSchema(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{
+		refKey: map[string]interface{}{"type": "string"},
+	}
+	var required []string
+	collectFields(t, properties, &required)
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
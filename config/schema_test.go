@@ -0,0 +1,106 @@
+package config_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func compactJSON(t *testing.T, data json.RawMessage) string {
+	var buf bytes.Buffer
+	require.NoError(t, json.Compact(&buf, data))
+	return buf.String()
+}
+
+func TestJSONSchema(t *testing.T) {
+	require.NoError(t, types.InstallType("test.DefaultRouteStrategy", &defaultRouteStrategy{}))
+	require.NoError(t, config.RegisterComponentType("DEFAULT_ROUTER", &defaultRouterConfig{}, newDefaultRouter))
+
+	data, err := config.JSONSchema()
+	require.NoError(t, err)
+
+	var schema struct {
+		Schema      string `json:"$schema"`
+		Definitions map[string]struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+			AllOf      []struct {
+				If struct {
+					Properties struct {
+						Type struct {
+							Const string `json:"const"`
+						} `json:"type"`
+					} `json:"properties"`
+				} `json:"if"`
+				Then map[string]json.RawMessage `json:"then"`
+			} `json:"allOf"`
+		} `json:"definitions"`
+	}
+	require.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema.Schema)
+
+	// components
+	component := schema.Definitions["component"]
+	assert.JSONEq(t, `{"enum": ["PROXY", "EAGER_ROUTER", "LAZY_ROUTER", "COMBINER", "FAULT_INJECTOR", "DEFAULT_ROUTER"]}`,
+		string(component.Properties["type"]))
+	require.Len(t, component.AllOf, 6)
+	assert.Equal(t, "PROXY", component.AllOf[0].If.Properties.Type.Const)
+	assert.JSONEq(t, `"#/definitions/PROXY"`, string(component.AllOf[0].Then["$ref"]))
+
+	proxy := schema.Definitions["PROXY"]
+	assert.Equal(t, []string{"id", "type", "endpoint"}, proxy.Required)
+	assert.JSONEq(t, `{"type": "boolean"}`, string(proxy.Properties["preserve_errors"]))
+	assert.JSONEq(t, `{"enum": ["server", "bidirectional"]}`, string(proxy.Properties["streaming"]))
+	assert.JSONEq(t, `{"type": "array", "items": {"type": "string"}}`, string(proxy.Properties["allowed_methods"]))
+	assert.Contains(t, compactJSON(t, proxy.Properties["timeout"]), `"pattern"`)
+	assert.Contains(t, compactJSON(t, proxy.Properties["component_timeout"]), `"pattern"`)
+	assert.Contains(t, compactJSON(t, proxy.Properties["http2"]), `"strict_max_concurrent_streams"`)
+	assert.Contains(t, compactJSON(t, proxy.Properties["credentials"]), `"enum":["bearer_token","token_file"]`)
+	assert.Contains(t, compactJSON(t, proxy.Properties["transport"]), `"oneOf":[{"type":"string"}`)
+	assert.Contains(t, compactJSON(t, proxy.Properties["transports"]), `"max_idle_conns_per_host"`)
+
+	router := schema.Definitions["EAGER_ROUTER"]
+	assert.Equal(t, []string{"id", "type", "routes", "strategy"}, router.Required)
+	assert.JSONEq(t, `{"type": "array", "items": {"$ref": "#/definitions/component"}}`,
+		string(router.Properties["routes"]))
+	assert.JSONEq(t, `{"$ref": "#/definitions/strategy"}`, string(router.Properties["strategy"]))
+
+	injector := schema.Definitions["FAULT_INJECTOR"]
+	assert.Equal(t, []string{"id", "type", "routes"}, injector.Required)
+	assert.Contains(t, compactJSON(t, injector.Properties["delay"]), `"stddev"`)
+	assert.JSONEq(t, `{"type": "boolean"}`, string(injector.Properties["header_only"]))
+
+	customRouter := schema.Definitions["DEFAULT_ROUTER"]
+	assert.Equal(t, []string{"id", "type", "default_route"}, customRouter.Required)
+	assert.JSONEq(t, `{"type": "string"}`, string(customRouter.Properties["default_route"]))
+	assert.JSONEq(t, `{"type": "array", "items": {"$ref": "#/definitions/component"}}`,
+		string(customRouter.Properties["routes"]))
+
+	// strategies and their properties
+	strategy := schema.Definitions["strategy"]
+	assert.Contains(t, compactJSON(t, strategy.Properties["type"]), `"fiber.RandomRoutingStrategy"`)
+	assert.Contains(t, compactJSON(t, strategy.Properties["type"]), `"test.DefaultRouteStrategy"`)
+	require.Len(t, strategy.AllOf, 1)
+	assert.Equal(t, "test.DefaultRouteStrategy", strategy.AllOf[0].If.Properties.Type.Const)
+	assert.JSONEq(t, `{
+		"properties": {
+			"type": "object",
+			"properties": {"default_route": {"type": "string"}},
+			"required": ["default_route"]
+		}
+	}`, string(strategy.AllOf[0].Then["properties"]))
+
+	interceptor := schema.Definitions["interceptor"]
+	assert.Contains(t, compactJSON(t, interceptor.Properties["type"]), `"fiber.LoggingInterceptor"`)
+	assert.JSONEq(t, `{"type": "boolean"}`, string(interceptor.Properties["recursive"]))
+	assert.JSONEq(t, `{"type": "array", "items": {"$ref": "#/definitions/interceptor"}}`,
+		string(proxy.Properties["interceptors"]))
+
+	fanIn := schema.Definitions["fan_in"]
+	assert.Contains(t, compactJSON(t, fanIn.Properties["type"]), `"fiber.FastestResponseFanIn"`)
+}
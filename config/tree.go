@@ -0,0 +1,102 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Node is a component of the routing graph, as it's defined in the fiber config.
+// Unlike the components created by InitComponentFromConfig, nodes only describe
+// the graph, so they can be inspected without connecting to the backends
+type Node struct {
+	ID   string
+	Type string
+	// Attributes are the other properties of the component, such as "endpoint", "strategy"
+	// or "fan_in". Nested values are represented by their JSON encoding
+	Attributes map[string]string
+	Routes     []*Node
+}
+
+// ParseTree parses the fiber config into the tree of its components
+func ParseTree(data []byte) (*Node, error) {
+	doc, err := parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+	return newNode("$", doc)
+}
+
+// ParseTreeFile loads the fiber config from the file (see LoadFile) and parses it into the tree of its components
+func ParseTreeFile(configPath string) (*Node, error) {
+	data, err := LoadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTree(data)
+}
+
+func newNode(path string, doc interface{}) (*Node, error) {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: component must be a mapping", path)
+	}
+
+	node := &Node{Attributes: make(map[string]string)}
+	node.ID, _ = obj["id"].(string)
+	node.Type, _ = obj["type"].(string)
+
+	for key, value := range obj {
+		switch key {
+		case "id", "type":
+		case "routes":
+			routes, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s.routes: routes must be a list", path)
+			}
+			for idx, route := range routes {
+				child, err := newNode(fmt.Sprintf("%s.routes[%d]", path, idx), route)
+				if err != nil {
+					return nil, err
+				}
+				node.Routes = append(node.Routes, child)
+			}
+		default:
+			node.Attributes[key] = attributeValue(value)
+		}
+	}
+	return node, nil
+}
+
+func attributeValue(value interface{}) string {
+	if str, ok := value.(string); ok {
+		return str
+	}
+	// maps are encoded with the sorted keys, so the values can be compared
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// Walk calls fn for the node and all of its descendants, depth-first. The path of each node
+// is the list of IDs from the root to the node
+func (n *Node) Walk(fn func(path []string, node *Node)) {
+	n.walk(nil, fn)
+}
+
+func (n *Node) walk(parent []string, fn func(path []string, node *Node)) {
+	path := append(append([]string{}, parent...), n.ID)
+	fn(path, n)
+	for _, route := range n.Routes {
+		route.walk(path, fn)
+	}
+}
+
+// AttributeKeys returns the sorted keys of the node attributes
+func (n *Node) AttributeKeys() []string {
+	keys := make([]string, 0, len(n.Attributes))
+	for key := range n.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	ghodss "github.com/ghodss/yaml"
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue with the fiber config, found by Validate
+type Problem struct {
+	// Path is the YAML path of the config node with the problem, e.g. "$.routes[1].strategy.type"
+	Path    string
+	Message string
+	// File and Line is the location of the config node (or its closest parent, if the node
+	// is missing), set when the config is validated with ValidateFile
+	File string
+	Line int
+}
+
+func (p Problem) String() string {
+	if p.File != "" {
+		return fmt.Sprintf("%s:%d: %s: %s", p.File, p.Line, p.Path, p.Message)
+	}
+	return p.Path + ": " + p.Message
+}
+
+// RouteReferrer can be implemented by the routing strategies, that only select the routes
+// with the IDs known from the strategy properties, e.g. a strategy with a fixed default route.
+// It allows Validate to report unreachable routes and references to routes, that don't exist
+type RouteReferrer interface {
+	RouteIDs() []string
+}
+
+// Validate checks the fiber config and reports every problem found in it. The components are
+// created the same way as with InitComponentFromConfig, but the checks don't stop at the first
+// problem, no grpc dispatchers are created (no connections are made to the backends), and the
+// interceptors, that implement types.PropertiesValidator, are not initialized (no files are opened
+// and no metrics are registered). An error is returned, if the config is not a valid YAML document
+func Validate(data []byte) ([]Problem, error) {
+	if _, err := parseDocument(data); err != nil {
+		return nil, err
+	}
+
+	b := newBuilder(true)
+	if _, err := b.build(data); err != nil {
+		return nil, err
+	}
+	b.closeInterceptors()
+	return b.problems, nil
+}
+
+// ValidateFile loads the fiber config from the file (see LoadFile) and validates it, see Validate.
+// The problems are reported with the location of the config nodes, in the file they originate from
+func ValidateFile(configPath string) ([]Problem, error) {
+	doc, err := loadDocument(configPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(doc.root)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := Validate(data)
+	for i := range problems {
+		problems[i].File, problems[i].Line = doc.locate(problems[i].Path)
+	}
+	return problems, err
+}
+
+// parseDocument parses the YAML document into the generic representation of its JSON equivalent
+func parseDocument(data []byte) (interface{}, error) {
+	jsonData, err := ghodss.YAMLToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
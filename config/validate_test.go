@@ -0,0 +1,413 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/config"
+	"github.com/gojek/fiber/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultRouteStrategy always selects the route, configured in its properties
+type defaultRouteStrategy struct {
+	DefaultRoute string `json:"default_route"`
+}
+
+func (s *defaultRouteStrategy) Initialize(properties json.RawMessage) error {
+	if err := json.Unmarshal(properties, s); err != nil {
+		return err
+	}
+	if s.DefaultRoute == "" {
+		return errors.New("default_route is required")
+	}
+	return nil
+}
+
+func (s *defaultRouteStrategy) SelectRoute(
+	_ context.Context,
+	_ fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	return routes[s.DefaultRoute], nil, fiber.NewLabelsMap(), nil
+}
+
+func (s *defaultRouteStrategy) RouteIDs() []string {
+	return []string{s.DefaultRoute}
+}
+
+func (s *defaultRouteStrategy) PropertiesSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"default_route": map[string]interface{}{"type": "string"},
+		},
+		"required": []string{"default_route"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, types.InstallType("test.DefaultRouteStrategy", &defaultRouteStrategy{}))
+	require.NoError(t, config.RegisterComponentType("DEFAULT_ROUTER", &defaultRouterConfig{}, newDefaultRouter))
+
+	tests := []struct {
+		name             string
+		config           string
+		expectedProblems []config.Problem
+		expectedErr      bool
+	}{
+		{
+			name: "valid config",
+			config: `
+type: EAGER_ROUTER
+id: eager_router
+strategy:
+  type: fiber.RandomRoutingStrategy
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: "http://localhost:8080/routes/route-a"
+  - id: route_b
+    type: PROXY
+    protocol: grpc
+    endpoint: "localhost:50555"
+    service_method: "mypackage.Greeter/SayHello"`,
+		},
+		{
+			name:        "malformed yaml",
+			config:      "type: [PROXY",
+			expectedErr: true,
+		},
+		{
+			name:   "not a mapping",
+			config: "- PROXY",
+			expectedProblems: []config.Problem{
+				{Path: "$", Message: "component must be a mapping"},
+			},
+		},
+		{
+			name: "multiple problems",
+			config: `
+type: COMBINER
+fan_in:
+  type: unknown.FanIn
+routes:
+  - id: route_a
+    type: PROXY
+  - id: route_a
+    type: PROXY
+    timeout: 10
+    endpoint: "http://localhost:8080"
+  - id: route_c
+    type: PROXY
+    protocol: grpc
+    endpoint: "localhost:50555"
+    streaming: client
+  - id: route_d
+    type: UNKNOWN
+  - id: router
+    type: LAZY_ROUTER
+    routes: []`,
+			expectedProblems: []config.Problem{
+				{Path: "$.id", Message: "missing component id"},
+				{Path: "$.routes[0].endpoint", Message: "missing endpoint"},
+				{Path: "$.routes[1]", Message: `invalid config: error unmarshaling JSON: time: missing unit in duration "10"`},
+				{Path: "$.routes[1].id", Message: "duplicate route id: route_a"},
+				{Path: "$.routes[2]", Message: "grpc dispatcher: missing config (endpoint/serviceMethod)"},
+				{Path: "$.routes[3].type", Message: "unknown component type: UNKNOWN"},
+				{Path: "$.routes[4].routes", Message: "no routes defined"},
+				{Path: "$.routes[4].strategy.type", Message: "missing strategy type"},
+				{Path: "$.fan_in.type", Message: "unknown FAN_IN type: unknown.FanIn"},
+			},
+		},
+		{
+			name: "invalid strategy properties",
+			config: `
+type: LAZY_ROUTER
+id: router
+strategy:
+  type: test.DefaultRouteStrategy
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: "http://localhost:8080"`,
+			expectedProblems: []config.Problem{
+				{Path: "$.strategy.properties", Message: "invalid strategy properties: unexpected end of JSON input"},
+			},
+		},
+		{
+			name: "unreachable routes",
+			config: `
+type: LAZY_ROUTER
+id: router
+strategy:
+  type: test.DefaultRouteStrategy
+  properties:
+    default_route: route_c
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: "http://localhost:8080"
+  - id: route_b
+    type: PROXY
+    endpoint: "http://localhost:8081"`,
+			expectedProblems: []config.Problem{
+				{Path: "$.strategy.properties", Message: "unknown route: route_c"},
+				{Path: "$.routes[0]", Message: "route route_a is unreachable: it's never selected by the strategy"},
+				{Path: "$.routes[1]", Message: "route route_b is unreachable: it's never selected by the strategy"},
+			},
+		},
+		{
+			name: "interceptors",
+			config: `
+type: PROXY
+id: proxy
+endpoint: "http://localhost:8080"
+interceptors:
+  - type: fiber.LoggingInterceptor
+    recursive: true
+    properties:
+      level: loud
+  - type: unknown.Interceptor
+  - recursive: true`,
+			expectedProblems: []config.Problem{
+				{Path: "$.interceptors[0].properties", Message: "invalid interceptor properties: " +
+					`invalid log level: unrecognized level: "loud"`},
+				{Path: "$.interceptors[1].type", Message: "unknown INTERCEPTOR type: unknown.Interceptor"},
+				{Path: "$.interceptors[2].type", Message: "missing interceptor type"},
+			},
+		},
+		{
+			name: "grpc proxy",
+			config: `
+type: PROXY
+id: proxy
+protocol: grpc
+endpoint: "localhost:50555"
+service_method: "mypackage.Greeter/SayHello"
+streaming: client
+http2: {}`,
+			expectedProblems: []config.Problem{
+				{Path: "$.http2", Message: "http2 is only supported by http proxies"},
+				{Path: "$", Message: "grpc dispatcher: unknown streaming type: client"},
+			},
+		},
+		{
+			name: "interceptor is not a mapping",
+			config: `
+type: PROXY
+id: proxy
+endpoint: "http://localhost:8080"
+interceptors:
+  - fiber.TracingInterceptor`,
+			expectedProblems: []config.Problem{
+				{Path: "$", Message: "invalid config: error unmarshaling JSON: " +
+					"json: cannot unmarshal string into ProxyConfig.interceptors.0 of type config.InterceptorConfig"},
+			},
+		},
+		{
+			name: "custom component type",
+			config: `
+type: DEFAULT_ROUTER
+id: default_router
+default_route: [route_a]
+routes:
+  - id: route_a
+    type: PROXY`,
+			expectedProblems: []config.Problem{
+				{Path: "$.routes[0].endpoint", Message: "missing endpoint"},
+				{Path: "$", Message: "invalid config: component default_router: error unmarshaling JSON: " +
+					"json: cannot unmarshal array into Go struct field defaultRouterConfig.default_route of type string"},
+			},
+		},
+		{
+			name: "transports",
+			config: `
+type: COMBINER
+id: combiner
+fan_in:
+  type: fiber.FastestResponseFanIn
+transports:
+  shared:
+    max_idle_conns_per_host: 100
+    proxy_url: "localhost"
+  named: shared
+routes:
+  - id: route_a
+    type: PROXY
+    endpoint: "http://localhost:8080"
+    transport: shared
+    http2: {}
+  - id: route_b
+    type: PROXY
+    endpoint: "http://localhost:8081"
+    transport: unknown
+  - id: route_c
+    type: PROXY
+    endpoint: "https://localhost:8082"
+    tls:
+      ca_file: ca.crt
+    transport:
+      dial_timeout: 1s
+      tls:
+        ca_file: ca.crt
+  - id: route_d
+    type: PROXY
+    protocol: grpc
+    endpoint: "localhost:50555"
+    service_method: "mypackage.Greeter/SayHello"
+    transport: shared`,
+			expectedProblems: []config.Problem{
+				{Path: "$.transports.named", Message: "shared transport must be a mapping"},
+				{Path: "$.transports.shared.proxy_url", Message: "invalid proxy url: localhost"},
+				{Path: "$.routes[0]", Message: "http2 and tls can't be set on proxies with the shared transport shared"},
+				{Path: "$.routes[1].transport", Message: "unknown transport: unknown"},
+				{Path: "$.routes[2].transport.tls", Message: "tls is set both on the proxy and its transport"},
+				{Path: "$.routes[3].transport", Message: "transport is only supported by http proxies"},
+			},
+		},
+		{
+			name: "timeouts",
+			config: `
+type: COMBINER
+id: combiner
+timeout: 10
+fan_in:
+  type: fiber.FastestResponseFanIn
+routes:
+  - id: router
+    type: LAZY_ROUTER
+    timeout: -1s
+    strategy:
+      type: fiber.RandomRoutingStrategy
+    routes:
+      - id: route_a
+        type: PROXY
+        timeout: 100ms
+        component_timeout: -1s
+        endpoint: "http://localhost:8080"`,
+			expectedProblems: []config.Problem{
+				{Path: "$.routes[0].routes[0].component_timeout", Message: "component_timeout must not be negative"},
+				{Path: "$.routes[0].timeout", Message: "timeout must not be negative"},
+				{Path: "$", Message: `invalid config: error unmarshaling JSON: time: missing unit in duration "10"`},
+			},
+		},
+		{
+			name: "fault injectors",
+			config: `
+type: COMBINER
+id: combiner
+fan_in:
+  type: fiber.FastestResponseFanIn
+routes:
+  - id: injector_a
+    type: FAULT_INJECTOR
+    delay:
+      percentage: 10
+      fixed: 10ms
+      mean: 20ms
+    routes:
+      - id: route_a
+        type: PROXY
+        endpoint: "http://localhost:8080"
+  - id: injector_b
+    type: FAULT_INJECTOR
+    abort:
+      percentage: 200
+    routes:
+      - id: route_b
+        type: PROXY
+        endpoint: "http://localhost:8081"
+      - id: route_c
+        type: PROXY
+        endpoint: "http://localhost:8082"
+  - id: injector_c
+    type: FAULT_INJECTOR
+    header_only: true
+  - id: injector_d
+    type: FAULT_INJECTOR
+    routes:
+      - id: route_d
+        type: PROXY
+        endpoint: "http://localhost:8083"
+      - id: route_e
+        type: PROXY
+        endpoint: "http://localhost:8084"`,
+			expectedProblems: []config.Problem{
+				{Path: "$.routes[0]", Message: "invalid faults: " +
+					"delay: exactly one of fixed, min and max, or mean and stddev is required"},
+				{Path: "$.routes[1]", Message: "invalid faults: abort: percentage must be between 0 and 100, got 200"},
+				{Path: "$.routes[2]", Message: "invalid faults: " +
+					"header is required, if faults are injected by the header only"},
+				{Path: "$.routes[3].routes", Message: "fault injector injector_d must have exactly one route, got 2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := config.Validate([]byte(tt.config))
+			if tt.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedProblems, problems)
+		})
+	}
+}
+
+func TestValidate_InterceptorResources(t *testing.T) {
+	dir := t.TempDir()
+	problems, err := config.Validate([]byte(`
+type: PROXY
+id: proxy
+endpoint: "http://localhost:8080"
+interceptors:
+  - type: fiber.CaptureInterceptor
+    properties:
+      path: ` + filepath.Join(dir, "missing", "capture.jsonl") + `
+  - type: fiber.AccessLogInterceptor
+    properties:
+      output: ` + filepath.Join(dir, "access.log") + `
+  - type: fiber.PrometheusInterceptor
+    properties:
+      namespace: validated
+  - type: fiber.CaptureInterceptor
+  - type: fiber.PrometheusInterceptor
+    properties:
+      namespace: "invalid-namespace"`))
+	require.NoError(t, err)
+	// the properties are checked, but the interceptors acquire no resources, e.g. the capture directory
+	// may exist only on the host, that the config is deployed to
+	require.Len(t, problems, 2)
+	assert.Equal(t, config.Problem{
+		Path:    "$.interceptors[3].properties",
+		Message: "invalid interceptor properties: path is required",
+	}, problems[0])
+	assert.Equal(t, "$.interceptors[4].properties", problems[1].Path)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	// the metrics aren't registered with the default registerer
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "validated",
+		Name:      "requests_total",
+		Help:      "Number of the requests, dispatched by the fiber components.",
+	}, []string{"component", "kind", "protocol", "code", "success"})
+	require.NoError(t, prometheus.Register(requests))
+	prometheus.Unregister(requests)
+}
+
+func TestRoutes_DuplicateIDs(t *testing.T) {
+	_, err := config.InitComponentFromConfig("../internal/testdata/config/duplicate_routes.yaml")
+	assert.EqualError(t, err, "duplicate route id: route_a")
+}
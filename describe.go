@@ -0,0 +1,181 @@
+package fiber
+
+import "time"
+
+// Description describes the configuration of a component or dispatcher, so that it can be exported
+// and created again (see config.Marshal)
+type Description struct {
+	// ID and Type of the component, where Type is the type of the component in the fiber config,
+	// such as "PROXY", "EAGER_ROUTER", "LAZY_ROUTER" or "COMBINER"
+	ID   string
+	Type string
+	// Properties are the other properties of the component, such as "endpoint" or "timeout",
+	// as they are defined in the fiber config
+	Properties map[string]interface{}
+	// Routes, Strategy and FanIn are the nested parts of the multi-route components
+	Routes   map[string]Component
+	Strategy RoutingStrategy
+	FanIn    FanIn
+}
+
+// Describer is implemented by the components and dispatchers, that can describe their configuration
+type Describer interface {
+	Describe() Description
+}
+
+// Describe describes the proxy, with the properties of its dispatcher
+func (p *Proxy) Describe() Description {
+	description := Description{ID: p.ID()}
+	if describer, ok := p.Component.(Describer); ok {
+		description = describer.Describe()
+	}
+	description.Type = "PROXY"
+	if description.Properties == nil {
+		description.Properties = make(map[string]interface{})
+	}
+	if b, ok := p.backend.(*backend); ok {
+		description.Properties["endpoint"] = b.Endpoint
+	}
+	// the timeout of a proxy in the fiber config is the timeout of its dispatcher
+	if timeout := p.Timeout(); timeout > 0 {
+		description.Properties["component_timeout"] = timeout.String()
+	}
+	return description
+}
+
+// Describe describes the caller, with the properties of its dispatcher
+func (c *Caller) Describe() Description {
+	description := Description{ID: c.ID()}
+	if describer, ok := c.dispatcher.(Describer); ok {
+		description.Properties = describer.Describe().Properties
+	}
+	return description
+}
+
+// Describe describes the combiner, with its routes and fan-in
+func (c *Combiner) Describe() Description {
+	return Description{
+		ID:         c.ID(),
+		Type:       "COMBINER",
+		Properties: timeoutProperties(c.Timeout()),
+		Routes:     allRoutes(c),
+		FanIn:      c.getFanIn(),
+	}
+}
+
+// Describe describes the router, with its routes and routing strategy
+func (router *EagerRouter) Describe() Description {
+	description := Description{
+		ID:         router.ID(),
+		Type:       "EAGER_ROUTER",
+		Properties: timeoutProperties(router.Timeout()),
+		Routes:     allRoutes(router),
+	}
+	if fanIn, ok := router.getFanIn().(*eagerRouterFanIn); ok {
+		description.Strategy = fanIn.strategy.RoutingStrategy
+	}
+	return description
+}
+
+// Describe describes the router, with its routes and routing strategy
+func (r *LazyRouter) Describe() Description {
+	description := Description{
+		ID:         r.ID(),
+		Type:       "LAZY_ROUTER",
+		Properties: timeoutProperties(r.Timeout()),
+		Routes:     allRoutes(r),
+	}
+	if strategy := r.strategy.Load(); strategy != nil {
+		description.Strategy = strategy.RoutingStrategy
+	}
+	return description
+}
+
+// timeoutProperties describe the timeout of the component (see BaseComponent.SetTimeout), if it's set
+func timeoutProperties(timeout time.Duration) map[string]interface{} {
+	if timeout <= 0 {
+		return nil
+	}
+	return map[string]interface{}{"timeout": timeout.String()}
+}
+
+// allRoutes returns all the routes of the multi-route component, including the disabled ones
+func allRoutes(component MultiRouteComponent) map[string]Component {
+	routes := make(map[string]Component)
+	for id, route := range component.GetRoutes() {
+		routes[id] = route
+	}
+	if controller, ok := component.(RouteController); ok {
+		for id, route := range controller.DisabledRoutes() {
+			routes[id] = route
+		}
+	}
+	return routes
+}
+
+// Describe describes the fault injector, with its route and faults
+func (f *FaultInjector) Describe() Description {
+	description := Description{
+		ID:         f.ID(),
+		Type:       "FAULT_INJECTOR",
+		Properties: f.Faults().properties(),
+		Routes:     map[string]Component{f.route.ID(): f.route},
+	}
+	if !f.Enabled() {
+		description.Properties["disabled"] = true
+	}
+	if timeout := f.Timeout(); timeout > 0 {
+		description.Properties["timeout"] = timeout.String()
+	}
+	return description
+}
+
+// properties describe the faults, as they are defined in the fiber config
+func (f Faults) properties() map[string]interface{} {
+	properties := make(map[string]interface{})
+	if f.Header != "" {
+		properties["header"] = f.Header
+	}
+	if f.HeaderOnly {
+		properties["header_only"] = true
+	}
+	if f.Delay != nil {
+		delay := map[string]interface{}{"percentage": f.Delay.Percentage}
+		for key, value := range map[string]time.Duration{
+			"fixed":  f.Delay.Fixed,
+			"min":    f.Delay.Min,
+			"max":    f.Delay.Max,
+			"mean":   f.Delay.Mean,
+			"stddev": f.Delay.StdDev,
+		} {
+			if value != 0 {
+				delay[key] = value.String()
+			}
+		}
+		properties["delay"] = delay
+	}
+	if f.Abort != nil {
+		abort := map[string]interface{}{"percentage": f.Abort.Percentage}
+		if f.Abort.HTTPStatus != 0 {
+			abort["http_status"] = f.Abort.HTTPStatus
+		}
+		if f.Abort.GRPCStatus != 0 {
+			abort["grpc_status"] = int(f.Abort.GRPCStatus)
+		}
+		properties["abort"] = abort
+	}
+	if f.Corrupt != nil {
+		corrupt := map[string]interface{}{"percentage": f.Corrupt.Percentage}
+		if f.Corrupt.Bytes != 0 {
+			corrupt["bytes"] = f.Corrupt.Bytes
+		}
+		properties["corrupt"] = corrupt
+	}
+	if f.Truncate != nil {
+		properties["truncate"] = map[string]interface{}{
+			"percentage": f.Truncate.Percentage,
+			"length":     f.Truncate.Length,
+		}
+	}
+	return properties
+}
@@ -1,6 +1,28 @@
 package fiber
 
-//
+import "context"
+
+// Dispatcher is the transport-specific abstraction, used by the Caller to send
+// the request to its backend and to receive the response
 type Dispatcher interface {
 	Do(request Request) Response
 }
+
+// ContextDispatcher is a Dispatcher that takes the context of the dispatched request
+// into account, e.g. to cancel the request or to propagate its deadline to the backend
+type ContextDispatcher interface {
+	Dispatcher
+
+	DoContext(ctx context.Context, request Request) Response
+}
+
+// StreamDispatcher is a Dispatcher that can produce more than one response
+// for a single request, such as a streaming RPC. The returned channel is closed
+// once the stream has ended.
+type StreamDispatcher interface {
+	Dispatcher
+
+	// IsStreaming reports whether DoStream should be used in place of Do
+	IsStreaming() bool
+	DoStream(ctx context.Context, request Request) <-chan Response
+}
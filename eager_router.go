@@ -17,6 +17,8 @@ import (
 // into a single response by selecting this response based on a provided RoutingStrategy
 type EagerRouter struct {
 	*Combiner
+
+	forced forcedRoute
 }
 
 // NewEagerRouter initializes new EagerRouter
@@ -29,7 +31,8 @@ func NewEagerRouter(id string) *EagerRouter {
 	}
 }
 
-// SetStrategy sets routing strategy for this router
+// SetStrategy sets routing strategy for this router. The strategy can be replaced, while
+// requests are dispatched: the requests in flight keep using the previous strategy
 func (router *EagerRouter) SetStrategy(strategy RoutingStrategy) {
 	router.WithFanIn(&eagerRouterFanIn{
 		BaseFanIn{},
@@ -37,6 +40,17 @@ func (router *EagerRouter) SetStrategy(strategy RoutingStrategy) {
 		router})
 }
 
+// ForceRoute forces the router to select the route with the given ID, see RouteForcer.
+// The request is still dispatched to all the routes
+func (router *EagerRouter) ForceRoute(id string) error {
+	return router.forced.force(id, router.GetRoutes())
+}
+
+// ForcedRoute returns the ID of the forced route, see RouteForcer
+func (router *EagerRouter) ForcedRoute() string {
+	return router.forced.get()
+}
+
 // EagerRouter's specific FanIn implementation
 // It receives the channel with responses from all possible router routes and asynchronously
 // retrieves information about primary route and the order of fallbacks to be used.
@@ -48,8 +62,9 @@ func (router *EagerRouter) SetStrategy(strategy RoutingStrategy) {
 // In case if the response from the primary route is not successful, then the first successful
 // response from fallback routes will be sent back.
 
-// If primary route AND all fallback routes responded with not non-successful responses, the error
-// response will be created and sent back.
+// If primary route AND all fallback routes responded with not non-successful responses, the last
+// unsuccessful upstream response (see isUpstreamError) will be sent back, or the error response
+// will be created and sent back, if there is none.
 type eagerRouterFanIn struct {
 	BaseFanIn
 	strategy *baseRoutingStrategy
@@ -63,7 +78,7 @@ func (fanIn *eagerRouterFanIn) Aggregate(
 ) Response {
 	// use routing strategy to fetch primary route and fallbacks
 	// publish the ordered routes into a channel
-	routesOrderCh := fanIn.strategy.getRoutesOrder(ctx, req, fanIn.router.GetRoutes())
+	routesOrderCh := fanIn.router.forced.routesOrder(ctx, req, fanIn.strategy, fanIn.router.GetRoutes())
 
 	out := make(chan Response, 1)
 	go func() {
@@ -92,7 +107,11 @@ func (fanIn *eagerRouterFanIn) Aggregate(
 			select {
 			case resp, ok := <-responseCh:
 				if ok {
-					responses[resp.BackendName()] = resp
+					// routes with streaming responses may produce more than one response,
+					// only the first one from each route is considered
+					if _, exist := responses[resp.BackendName()]; !exist {
+						responses[resp.BackendName()] = resp
+					}
 				} else {
 					responseCh = nil
 				}
@@ -136,7 +155,10 @@ func (fanIn *eagerRouterFanIn) Aggregate(
 					if len(routes) == 0 {
 						masterResponse = NewErrorResponse(errors.ErrRouterStrategyReturnedEmptyRoutes(req.Protocol()))
 					} else {
-						masterResponse = NewErrorResponse(errors.ErrNoValidResponseFromRoutes(req.Protocol()))
+						masterResponse = lastUpstreamError(routes, responses)
+						if masterResponse == nil {
+							masterResponse = NewErrorResponse(errors.ErrNoValidResponseFromRoutes(req.Protocol()))
+						}
 					}
 				}
 			}
@@ -146,3 +168,14 @@ func (fanIn *eagerRouterFanIn) Aggregate(
 
 	return <-out
 }
+
+// lastUpstreamError returns the unsuccessful upstream response of the last route in the given order,
+// that responded with one, or nil
+func lastUpstreamError(routes []Component, responses map[string]Response) Response {
+	for i := len(routes) - 1; i >= 0; i-- {
+		if resp, exist := responses[routes[i].ID()]; exist && isUpstreamError(resp) {
+			return resp
+		}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gojek/fiber/protocol"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // FiberError is used to capture the error resulting from a Fiber request
@@ -25,6 +26,46 @@ func (err *FiberError) ToJSON() ([]byte, error) {
 	return json.MarshalIndent(err, "", "  ")
 }
 
+// GRPCStatus converts the FiberError into a grpc status. Codes of the errors created for
+// the HTTP protocol are translated into the closest matching grpc codes.
+func (err *FiberError) GRPCStatus() *status.Status {
+	return status.New(grpcCode(err.Code), err.Message)
+}
+
+// grpcCode maps the given status code to a grpc code. Status codes that are already
+// valid grpc codes are returned as is, HTTP status codes are translated.
+func grpcCode(statusCode int) codes.Code {
+	if statusCode >= int(codes.OK) && statusCode <= int(codes.Unauthenticated) {
+		return codes.Code(statusCode)
+	}
+	switch statusCode {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	if statusCode/100 == 2 {
+		return codes.OK
+	}
+	return codes.Unknown
+}
+
 // NewFiberError returns an error of type FiberError from the input error object.
 // If the input error is already of the required type, it is returned as is.
 // If not, a generic request failed error is created from the given error.
@@ -124,4 +165,30 @@ var (
 			Message: fmt.Sprintf("fiber: %s", err.Error()),
 		}
 	}
+
+	// ErrUnauthenticated is a FiberError that's returned when the request fails the authentication,
+	// e.g. by the ShortCircuitInterceptor(s)
+	ErrUnauthenticated = func(protocol protocol.Protocol, err error) *FiberError {
+		statusCode := http.StatusUnauthorized
+		if protocol == "GRPC" {
+			statusCode = int(codes.Unauthenticated)
+		}
+		return &FiberError{
+			Code:    statusCode,
+			Message: fmt.Sprintf("fiber: %s", err.Error()),
+		}
+	}
+
+	// ErrTooManyRequests is a FiberError that's returned when the request exceeds the quota,
+	// e.g. by the ShortCircuitInterceptor(s)
+	ErrTooManyRequests = func(protocol protocol.Protocol, err error) *FiberError {
+		statusCode := http.StatusTooManyRequests
+		if protocol == "GRPC" {
+			statusCode = int(codes.ResourceExhausted)
+		}
+		return &FiberError{
+			Code:    statusCode,
+			Message: fmt.Sprintf("fiber: %s", err.Error()),
+		}
+	}
 )
@@ -2,6 +2,7 @@ package extras
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/gojek/fiber"
 	"github.com/gojek/fiber/errors"
@@ -32,3 +33,8 @@ func (r *FastestResponseFanIn) Aggregate(
 	}()
 	return <-out
 }
+
+// Properties returns no properties, the fan-in is not configurable
+func (r *FastestResponseFanIn) Properties() (json.RawMessage, error) {
+	return nil, nil
+}
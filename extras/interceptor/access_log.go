@@ -0,0 +1,387 @@
+package interceptor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/protocol"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// CtxAccessLogStartTimeKey is used to record the start time of a request, logged by the AccessLogInterceptor
+var CtxAccessLogStartTimeKey MetricsKey = "CTX_ACCESS_LOG_START_TIME"
+
+const (
+	// DefaultRequestIDHeader is the header (or the grpc metadata key) of the request ID, logged by
+	// the AccessLogInterceptor, unless another one is set in the AccessLogOptions
+	DefaultRequestIDHeader = "X-Request-Id"
+	// DefaultMaxPayloadLength is the maximum length of the logged payloads, unless another one is set
+	// in the PayloadLogOptions
+	DefaultMaxPayloadLength = 1024
+	// Redacted replaces the values of the redacted JSON fields and headers
+	Redacted = "[REDACTED]"
+)
+
+// defaultRedactedHeaders are the headers, that are always redacted
+var defaultRedactedHeaders = []string{"Authorization", "Cookie"}
+
+// AccessRecord is the record of the request, dispatched by the component
+type AccessRecord struct {
+	RequestID string
+	// ComponentPath is the path of the component in the graph, see fiber.CtxComponentPathKey
+	ComponentPath string
+	ComponentKind fiber.ComponentKind
+	Protocol      protocol.Protocol
+	Operation     string
+	// Route, StatusCode, Success and Labels are the ones of the first response
+	Route      string
+	StatusCode int
+	Success    bool
+	Labels     map[string][]string
+	// Responses is the number of the received responses
+	Responses    int
+	Latency      time.Duration
+	RequestSize  int
+	ResponseSize int
+	// Payloads are set, if the payloads of the request were sampled
+	Payloads *AccessPayloads
+}
+
+// AccessPayloads are the redacted and truncated payloads and headers of the request and its first response
+type AccessPayloads struct {
+	RequestHeader map[string][]string
+	Request       string
+	Response      string
+}
+
+// AccessLogger writes the access records, e.g. see NewZapAccessLogger
+type AccessLogger interface {
+	LogAccess(ctx context.Context, record AccessRecord)
+}
+
+// PayloadLogOptions configure the logging of the payloads. The payloads aren't logged by default
+type PayloadLogOptions struct {
+	// SuccessSampleRate and ErrorSampleRate are the fractions (from 0 to 1) of the successful
+	// and unsuccessful requests, whose payloads are logged
+	SuccessSampleRate float64 `json:"success_sample_rate"`
+	ErrorSampleRate   float64 `json:"error_sample_rate"`
+	// MaxLength is the length, that the payloads are truncated to, DefaultMaxPayloadLength if zero
+	MaxLength int `json:"max_length"`
+	// RedactFields are the names of the fields (case-insensitive, at any depth), whose values are
+	// redacted in the JSON payloads. Payloads, that aren't JSON, can't be redacted, so only their sizes
+	// are logged
+	RedactFields []string `json:"redact_fields"`
+	// RedactHeaders are the names of the request headers (case-insensitive), whose values are
+	// redacted, in addition to the Authorization and Cookie headers
+	RedactHeaders []string `json:"redact_headers"`
+}
+
+// AccessLogOptions captures a set of options of the AccessLogInterceptor
+type AccessLogOptions struct {
+	// RequestIDHeader is the header of the request ID, DefaultRequestIDHeader if empty
+	RequestIDHeader string `json:"request_id_header"`
+	// Labels are the keys of the response labels (such as the ones set by the routing strategies), that are logged
+	Labels   []string          `json:"labels"`
+	Payloads PayloadLogOptions `json:"payloads"`
+}
+
+// AccessLogInterceptor writes one access record per request, dispatched by the component, once all
+// of its responses are received. Unlike the ResponseLoggingInterceptor, the payloads are only logged
+// for the sampled requests, redacted and truncated
+type AccessLogInterceptor struct {
+	fiber.NoopAfterDispatchInterceptor
+
+	logger        AccessLogger
+	options       AccessLogOptions
+	redactFields  map[string]bool
+	redactHeaders map[string]bool
+	// closeLogger closes the log, opened by Initialize
+	closeLogger func()
+}
+
+// NewAccessLogInterceptor creates the AccessLogInterceptor, that writes the records with the logger
+func NewAccessLogInterceptor(logger AccessLogger, options AccessLogOptions) *AccessLogInterceptor {
+	i := &AccessLogInterceptor{}
+	i.init(logger, options)
+	return i
+}
+
+func (i *AccessLogInterceptor) init(logger AccessLogger, options AccessLogOptions) {
+	if options.RequestIDHeader == "" {
+		options.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if options.Payloads.MaxLength == 0 {
+		options.Payloads.MaxLength = DefaultMaxPayloadLength
+	}
+	i.logger = logger
+	i.options = options
+	i.redactFields = make(map[string]bool)
+	for _, field := range options.Payloads.RedactFields {
+		i.redactFields[strings.ToLower(field)] = true
+	}
+	i.redactHeaders = make(map[string]bool)
+	for _, headers := range [][]string{defaultRedactedHeaders, options.Payloads.RedactHeaders} {
+		for _, header := range headers {
+			i.redactHeaders[strings.ToLower(header)] = true
+		}
+	}
+}
+
+// BeforeDispatch records the start time of the dispatch
+func (i *AccessLogInterceptor) BeforeDispatch(ctx context.Context, _ fiber.Request) context.Context {
+	return context.WithValue(ctx, CtxAccessLogStartTimeKey, time.Now())
+}
+
+// AfterCompletion writes the access record of the request
+func (i *AccessLogInterceptor) AfterCompletion(ctx context.Context, req fiber.Request, queue fiber.ResponseQueue) {
+	record := AccessRecord{
+		RequestID:   headerCarrier(req.Header()).Get(i.options.RequestIDHeader),
+		Protocol:    req.Protocol(),
+		Operation:   req.OperationName(),
+		RequestSize: len(req.Payload()),
+	}
+	record.ComponentPath, _ = ctx.Value(fiber.CtxComponentPathKey).(string)
+	record.ComponentKind, _ = ctx.Value(fiber.CtxComponentKindKey).(fiber.ComponentKind)
+
+	var first fiber.Response
+	for resp := range queue.Iter() {
+		if first == nil {
+			first = resp
+		}
+		record.Responses++
+	}
+	if startTime, ok := ctx.Value(CtxAccessLogStartTimeKey).(time.Time); ok {
+		record.Latency = time.Since(startTime)
+	}
+
+	if first != nil {
+		record.Route = first.BackendName()
+		record.StatusCode = first.StatusCode()
+		record.Success = first.IsSuccess()
+		record.ResponseSize = len(first.Payload())
+		for _, key := range i.options.Labels {
+			if values := first.Label(key); len(values) > 0 {
+				if record.Labels == nil {
+					record.Labels = make(map[string][]string)
+				}
+				record.Labels[key] = values
+			}
+		}
+	}
+
+	if i.sampled(record.Success) {
+		record.Payloads = &AccessPayloads{
+			RequestHeader: i.redactHeader(req.Header()),
+			Request:       i.redactPayload(req.Payload()),
+		}
+		if first != nil {
+			record.Payloads.Response = i.redactPayload(first.Payload())
+		}
+	}
+	i.logger.LogAccess(ctx, record)
+}
+
+func (i *AccessLogInterceptor) sampled(success bool) bool {
+	rate := i.options.Payloads.ErrorSampleRate
+	if success {
+		rate = i.options.Payloads.SuccessSampleRate
+	}
+	return rate > 0 && rand.Float64() < rate
+}
+
+func (i *AccessLogInterceptor) redactHeader(header map[string][]string) map[string][]string {
+	redacted := make(map[string][]string, len(header))
+	for key, values := range header {
+		if i.redactHeaders[strings.ToLower(key)] {
+			values = []string{Redacted}
+		}
+		redacted[key] = values
+	}
+	return redacted
+}
+
+func (i *AccessLogInterceptor) redactPayload(payload []byte) string {
+	if len(i.redactFields) > 0 && len(payload) > 0 {
+		redacted, err := i.redactJSON(payload)
+		if err != nil {
+			// the payload, that can't be redacted, isn't logged, as it may hold the redacted fields
+			return fmt.Sprintf("<unredactable %d bytes>", len(payload))
+		}
+		payload = redacted
+	}
+	if len(payload) > i.options.Payloads.MaxLength {
+		// the payload is truncated at the start of a character, so the multi-byte characters aren't split
+		end := i.options.Payloads.MaxLength
+		for n := 1; n < utf8.UTFMax && end > 0 && !utf8.RuneStart(payload[end]); n++ {
+			end--
+		}
+		return string(payload[:end]) + "..."
+	}
+	return string(payload)
+}
+
+// redactJSON redacts the fields of the JSON payload. The payloads with anything, but a single JSON value
+// (such as the trailing data), are rejected
+func (i *AccessLogInterceptor) redactJSON(payload []byte) ([]byte, error) {
+	if !json.Valid(payload) {
+		return nil, errors.New("invalid JSON")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(i.redactValue(value))
+}
+
+func (i *AccessLogInterceptor) redactValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if i.redactFields[strings.ToLower(key)] {
+				value[key] = Redacted
+			} else {
+				value[key] = i.redactValue(field)
+			}
+		}
+	case []interface{}:
+		for idx, item := range value {
+			value[idx] = i.redactValue(item)
+		}
+	}
+	return value
+}
+
+// Initialize creates the interceptor, declared in the fiber config, that writes the records with
+// a zap production logger. The properties are the AccessLogOptions and the optional "output" path
+// of the log ("stderr" by default), e.g. {"labels": ["experiment"], "payloads": {"error_sample_rate": 0.1}}
+func (i *AccessLogInterceptor) Initialize(properties json.RawMessage) error {
+	var cfg accessLogProperties
+	if err := cfg.parse(properties); err != nil {
+		return err
+	}
+
+	if cfg.Output == "" {
+		cfg.Output = "stderr"
+	}
+	logger, closeLogger, err := newZapLogger(zap.NewAtomicLevelAt(zap.InfoLevel), cfg.Output, false)
+	if err != nil {
+		return err
+	}
+	i.init(NewZapAccessLogger(logger), cfg.AccessLogOptions)
+	i.closeLogger = closeLogger
+	return nil
+}
+
+// ValidateProperties checks the properties of the interceptor, declared in the fiber config,
+// without opening its output
+func (i *AccessLogInterceptor) ValidateProperties(properties json.RawMessage) error {
+	var cfg accessLogProperties
+	return cfg.parse(properties)
+}
+
+// Close flushes and closes the log, opened by Initialize
+func (i *AccessLogInterceptor) Close() error {
+	if i.closeLogger != nil {
+		i.closeLogger()
+	}
+	return nil
+}
+
+// accessLogProperties are the properties of the AccessLogInterceptor, declared in the fiber config
+type accessLogProperties struct {
+	AccessLogOptions
+	Output string `json:"output"`
+}
+
+func (p *accessLogProperties) parse(properties json.RawMessage) error {
+	if len(properties) == 0 {
+		return nil
+	}
+	return json.Unmarshal(properties, p)
+}
+
+// PropertiesSchema describes the properties of the interceptor, declared in the fiber config
+func (i *AccessLogInterceptor) PropertiesSchema() map[string]interface{} {
+	names := map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "string"},
+	}
+	rate := map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"output":            map[string]interface{}{"type": "string"},
+			"request_id_header": map[string]interface{}{"type": "string"},
+			"labels":            names,
+			"payloads": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"success_sample_rate": rate,
+					"error_sample_rate":   rate,
+					"max_length":          map[string]interface{}{"type": "integer", "minimum": 1},
+					"redact_fields":       names,
+					"redact_headers":      names,
+				},
+			},
+		},
+	}
+}
+
+// NewZapAccessLogger creates the AccessLogger, that writes the records with the zap logger, at the info
+// level for the successful requests and at the warn level otherwise
+func NewZapAccessLogger(logger *zap.Logger) AccessLogger {
+	return &zapAccessLogger{logger: logger}
+}
+
+type zapAccessLogger struct {
+	logger *zap.Logger
+}
+
+func (l *zapAccessLogger) LogAccess(_ context.Context, record AccessRecord) {
+	level := zapcore.InfoLevel
+	if !record.Success {
+		level = zapcore.WarnLevel
+	}
+	entry := l.logger.Check(level, "access")
+	if entry == nil {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("request_id", record.RequestID),
+		zap.String("component", record.ComponentPath),
+		zap.String("kind", string(record.ComponentKind)),
+		zap.String("protocol", string(record.Protocol)),
+		zap.String("operation", record.Operation),
+		zap.String("route", record.Route),
+		zap.Int("status", record.StatusCode),
+		zap.Bool("success", record.Success),
+		zap.Int("responses", record.Responses),
+		zap.Duration("latency", record.Latency),
+		zap.Int("request_size", record.RequestSize),
+		zap.Int("response_size", record.ResponseSize),
+	}
+	if len(record.Labels) > 0 {
+		fields = append(fields, zap.Any("labels", record.Labels))
+	}
+	if record.Payloads != nil {
+		fields = append(fields,
+			zap.Namespace("payloads"),
+			zap.Any("request_header", record.Payloads.RequestHeader),
+			zap.String("request", record.Payloads.Request),
+			zap.String("response", record.Payloads.Response),
+		)
+	}
+	entry.Write(fields...)
+}
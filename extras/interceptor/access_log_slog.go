@@ -0,0 +1,54 @@
+//go:build go1.21
+
+package interceptor
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogAccessLogger creates the AccessLogger, that writes the records with the slog logger, at the info
+// level for the successful requests and at the warn level otherwise
+func NewSlogAccessLogger(logger *slog.Logger) AccessLogger {
+	return &slogAccessLogger{logger: logger}
+}
+
+type slogAccessLogger struct {
+	logger *slog.Logger
+}
+
+func (l *slogAccessLogger) LogAccess(ctx context.Context, record AccessRecord) {
+	level := slog.LevelInfo
+	if !record.Success {
+		level = slog.LevelWarn
+	}
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("request_id", record.RequestID),
+		slog.String("component", record.ComponentPath),
+		slog.String("kind", string(record.ComponentKind)),
+		slog.String("protocol", string(record.Protocol)),
+		slog.String("operation", record.Operation),
+		slog.String("route", record.Route),
+		slog.Int("status", record.StatusCode),
+		slog.Bool("success", record.Success),
+		slog.Int("responses", record.Responses),
+		slog.Duration("latency", record.Latency),
+		slog.Int("request_size", record.RequestSize),
+		slog.Int("response_size", record.ResponseSize),
+	}
+	if len(record.Labels) > 0 {
+		attrs = append(attrs, slog.Any("labels", record.Labels))
+	}
+	if record.Payloads != nil {
+		attrs = append(attrs, slog.Group("payloads",
+			slog.Any("request_header", record.Payloads.RequestHeader),
+			slog.String("request", record.Payloads.Request),
+			slog.String("response", record.Payloads.Response),
+		))
+	}
+	l.logger.LogAttrs(ctx, level, "access", attrs...)
+}
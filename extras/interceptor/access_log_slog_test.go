@@ -0,0 +1,54 @@
+//go:build go1.21
+
+package interceptor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber/extras/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlogAccessLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := interceptor.NewSlogAccessLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	logger.LogAccess(context.Background(), interceptor.AccessRecord{
+		RequestID:     "request-1",
+		ComponentPath: "router/route_a",
+		Route:         "route_a",
+		StatusCode:    http.StatusBadGateway,
+		Responses:     1,
+		Latency:       time.Millisecond,
+		Labels:        map[string][]string{"experiment": {"a"}},
+		Payloads: &interceptor.AccessPayloads{
+			RequestHeader: map[string][]string{"Authorization": {interceptor.Redacted}},
+			Request:       "request",
+			Response:      "response",
+		},
+	})
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	// the unsuccessful requests are logged at the warn level
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "access", entry["msg"])
+	assert.Equal(t, "request-1", entry["request_id"])
+	assert.Equal(t, "router/route_a", entry["component"])
+	assert.Equal(t, "route_a", entry["route"])
+	assert.Equal(t, float64(http.StatusBadGateway), entry["status"])
+	assert.Equal(t, false, entry["success"])
+	assert.Equal(t, map[string]interface{}{"experiment": []interface{}{"a"}}, entry["labels"])
+	assert.Equal(t, map[string]interface{}{
+		"request_header": map[string]interface{}{"Authorization": []interface{}{interceptor.Redacted}},
+		"request":        "request",
+		"response":       "response",
+	}, entry["payloads"])
+}
@@ -0,0 +1,149 @@
+package interceptor_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/extras/interceptor"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingAccessLogger sends the access records to the channel
+type recordingAccessLogger chan interceptor.AccessRecord
+
+func (l recordingAccessLogger) LogAccess(_ context.Context, record interceptor.AccessRecord) {
+	l <- record
+}
+
+func (l recordingAccessLogger) next(t *testing.T) interceptor.AccessRecord {
+	t.Helper()
+	select {
+	case record := <-l:
+		return record
+	case <-time.After(time.Second):
+		require.FailNow(t, "access record is not logged")
+		return interceptor.AccessRecord{}
+	}
+}
+
+// accessLog dispatches the request to the route with the access log interceptor and returns its record
+func accessLog(
+	t *testing.T, options interceptor.AccessLogOptions, reply reply, req fiber.Request,
+) interceptor.AccessRecord {
+	t.Helper()
+	logger := make(recordingAccessLogger, 1)
+	route := newRoute(t, "route_a", reply)
+	route.AddInterceptor(false, interceptor.NewAccessLogInterceptor(logger, options))
+	dispatch(t, route, req)
+	return logger.next(t)
+}
+
+func TestAccessLogInterceptor(t *testing.T) {
+	req := testUtilsHttp.MockReq(http.MethodPost, "http://localhost/orders", `{"id":1}`)
+	req.Header()["X-Request-Id"] = []string{"request-1"}
+
+	record := accessLog(t, interceptor.AccessLogOptions{}, httpReply(http.StatusOK, "ok"), req)
+	assert.Equal(t, "request-1", record.RequestID)
+	assert.Equal(t, "route_a", record.ComponentPath)
+	assert.Equal(t, http.StatusOK, record.StatusCode)
+	assert.True(t, record.Success)
+	assert.Equal(t, 1, record.Responses)
+	assert.Equal(t, len(`{"id":1}`), record.RequestSize)
+	assert.Equal(t, len("ok"), record.ResponseSize)
+	// the payloads aren't sampled by default
+	assert.Nil(t, record.Payloads)
+}
+
+func TestAccessLogInterceptor_Sampling(t *testing.T) {
+	options := interceptor.AccessLogOptions{
+		Payloads: interceptor.PayloadLogOptions{SuccessSampleRate: 0, ErrorSampleRate: 1},
+	}
+	req := testUtilsHttp.MockReq(http.MethodGet, "http://localhost", "request")
+
+	record := accessLog(t, options, httpReply(http.StatusOK, "ok"), req)
+	assert.Nil(t, record.Payloads)
+
+	record = accessLog(t, options, httpReply(http.StatusBadGateway, "failed"), req)
+	require.NotNil(t, record.Payloads)
+	assert.Equal(t, "request", record.Payloads.Request)
+	assert.Contains(t, record.Payloads.Response, `"error": "failed"`)
+}
+
+func TestAccessLogInterceptor_Redaction(t *testing.T) {
+	tests := map[string]struct {
+		payload  string
+		expected string
+	}{
+		"json": {
+			payload:  `{"user":{"Password":"secret","name":"a"},"tokens":[{"token":"t"}],"amount":1.50}`,
+			expected: `{"amount":1.50,"tokens":[{"token":"[REDACTED]"}],"user":{"Password":"[REDACTED]","name":"a"}}`,
+		},
+		"not json": {
+			payload:  "password=secret",
+			expected: "<unredactable 15 bytes>",
+		},
+		"trailing data": {
+			payload:  `{"a":1}{"password":"secret"}`,
+			expected: "<unredactable 28 bytes>",
+		},
+		"empty": {
+			payload:  "",
+			expected: "",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			options := interceptor.AccessLogOptions{
+				Payloads: interceptor.PayloadLogOptions{
+					SuccessSampleRate: 1,
+					RedactFields:      []string{"password", "TOKEN"},
+					RedactHeaders:     []string{"X-Api-Key"},
+				},
+			}
+			req := testUtilsHttp.MockReq(http.MethodPost, "http://localhost", tt.payload)
+			req.Header()["Authorization"] = []string{"Bearer token"}
+			req.Header()["X-Api-Key"] = []string{"key"}
+			req.Header()["Accept"] = []string{"application/json"}
+
+			record := accessLog(t, options, httpReply(http.StatusOK, tt.payload), req)
+			require.NotNil(t, record.Payloads)
+			assert.Equal(t, tt.expected, record.Payloads.Request)
+			assert.Equal(t, tt.expected, record.Payloads.Response)
+			assert.Equal(t, []string{interceptor.Redacted}, record.Payloads.RequestHeader["Authorization"])
+			assert.Equal(t, []string{interceptor.Redacted}, record.Payloads.RequestHeader["X-Api-Key"])
+			assert.Equal(t, []string{"application/json"}, record.Payloads.RequestHeader["Accept"])
+		})
+	}
+}
+
+func TestAccessLogInterceptor_Truncation(t *testing.T) {
+	options := interceptor.AccessLogOptions{
+		Payloads: interceptor.PayloadLogOptions{SuccessSampleRate: 1, MaxLength: 4},
+	}
+	req := testUtilsHttp.MockReq(http.MethodPost, "http://localhost", "request")
+
+	record := accessLog(t, options, httpReply(http.StatusOK, "ok"), req)
+	require.NotNil(t, record.Payloads)
+	assert.Equal(t, "requ...", record.Payloads.Request)
+	assert.Equal(t, "ok", record.Payloads.Response)
+}
+
+func TestAccessLogInterceptor_TruncationUTF8(t *testing.T) {
+	options := interceptor.AccessLogOptions{
+		Payloads: interceptor.PayloadLogOptions{SuccessSampleRate: 1, MaxLength: 4},
+	}
+	// the first 4 bytes of the request end in the middle of the two-byte "é"
+	req := testUtilsHttp.MockReq(http.MethodPost, "http://localhost", "café")
+
+	record := accessLog(t, options, httpReply(http.StatusOK, "añob"), req)
+	require.NotNil(t, record.Payloads)
+	assert.Equal(t, "caf...", record.Payloads.Request)
+	assert.True(t, utf8.ValidString(record.Payloads.Request))
+	assert.Equal(t, "año...", record.Payloads.Response)
+}
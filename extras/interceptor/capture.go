@@ -0,0 +1,259 @@
+package interceptor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gojek/fiber"
+	fibergrpc "github.com/gojek/fiber/grpc"
+	fiberhttp "github.com/gojek/fiber/http"
+	"github.com/gojek/fiber/protocol"
+	"google.golang.org/grpc/metadata"
+)
+
+// CtxCaptureKey is used to keep the captured request, until its responses are received by the CaptureInterceptor
+var CtxCaptureKey MetricsKey = "CTX_CAPTURE"
+
+// CaptureRecord is the request, captured by the CaptureInterceptor, with its final response. The records
+// are written as JSON Lines and can be replayed with the 'fiber replay' command
+type CaptureRecord struct {
+	Time time.Time `json:"time"`
+	// Component is the path of the component, that dispatched the request, see fiber.CtxComponentPathKey
+	Component string            `json:"component"`
+	Protocol  protocol.Protocol `json:"protocol"`
+	// Method is the method of the http request or the full method name of the grpc request
+	Method string `json:"method"`
+	// URL is the URL of the http request
+	URL string `json:"url,omitempty"`
+	// Header is the header of the http request or the metadata of the grpc request
+	Header   map[string][]string `json:"header,omitempty"`
+	Payload  []byte              `json:"payload,omitempty"`
+	Duration time.Duration       `json:"duration"`
+	// Response is the first response of the request, nil if there was none
+	Response *CapturedResponse `json:"response,omitempty"`
+	// Responses is the number of the responses of the request, such as the messages of a grpc stream.
+	// It's zero in the records, written before it was captured
+	Responses int `json:"responses,omitempty"`
+}
+
+// CapturedResponse is the response of the captured request
+type CapturedResponse struct {
+	Route      string              `json:"route,omitempty"`
+	StatusCode int                 `json:"status_code"`
+	Success    bool                `json:"success"`
+	Labels     map[string][]string `json:"labels,omitempty"`
+	Payload    []byte              `json:"payload,omitempty"`
+}
+
+// NewRequest creates the fiber request, that is the same as the captured one
+func (r *CaptureRecord) NewRequest() (fiber.Request, error) {
+	header := make(map[string][]string, len(r.Header))
+	for key, values := range r.Header {
+		header[key] = append([]string(nil), values...)
+	}
+
+	switch r.Protocol {
+	case protocol.HTTP:
+		httpReq, err := http.NewRequest(r.Method, r.URL, bytes.NewReader(r.Payload))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header = header
+		return fiberhttp.NewHTTPRequest(httpReq)
+	case protocol.GRPC:
+		req := fibergrpc.NewRequest(metadata.MD(header), r.Payload, nil)
+		req.FullMethod = r.Method
+		return req, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol: %s", r.Protocol)
+	}
+}
+
+// ReadCaptureRecords reads the records, written by the CaptureInterceptor, from the reader
+func ReadCaptureRecords(r io.Reader) ([]CaptureRecord, error) {
+	var records []CaptureRecord
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var record CaptureRecord
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				return records, nil
+			}
+			return nil, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// CaptureOptions captures a set of options of the CaptureInterceptor
+type CaptureOptions struct {
+	// SampleRate is the fraction (from 0 to 1) of the requests, that are captured, all of them if zero
+	SampleRate float64 `json:"sample_rate"`
+	// Labels are the keys of the response labels (such as the ones set by the routing strategies), that are captured
+	Labels []string `json:"labels"`
+}
+
+// CaptureInterceptor records the requests, dispatched by the component, and their final responses,
+// as JSON Lines. The records can be replayed against another config with the 'fiber replay' command
+type CaptureInterceptor struct {
+	fiber.NoopAfterDispatchInterceptor
+
+	mu      sync.Mutex
+	writer  io.Writer
+	options CaptureOptions
+	// file is the capture file, opened by Initialize
+	file *CaptureFile
+}
+
+// NewCaptureInterceptor creates the CaptureInterceptor, that writes the records to the writer,
+// e.g. the CaptureFile
+func NewCaptureInterceptor(writer io.Writer, options CaptureOptions) *CaptureInterceptor {
+	return &CaptureInterceptor{writer: writer, options: options}
+}
+
+// BeforeDispatch captures the sampled request, before it's transformed by the routes
+func (i *CaptureInterceptor) BeforeDispatch(ctx context.Context, req fiber.Request) context.Context {
+	if i.options.SampleRate > 0 && rand.Float64() >= i.options.SampleRate {
+		// the nested components shouldn't capture the responses to the request of their parent
+		return context.WithValue(ctx, CtxCaptureKey, (*CaptureRecord)(nil))
+	}
+
+	record := &CaptureRecord{
+		Time:     time.Now(),
+		Protocol: req.Protocol(),
+		Header:   make(map[string][]string, len(req.Header())),
+		Payload:  req.Payload(),
+	}
+	record.Component, _ = ctx.Value(fiber.CtxComponentPathKey).(string)
+	for key, values := range req.Header() {
+		record.Header[key] = append([]string(nil), values...)
+	}
+	switch req := req.(type) {
+	case *fiberhttp.Request:
+		record.Method = req.Method
+		record.URL = req.URL.String()
+	case *fibergrpc.Request:
+		record.Method = req.FullMethod
+	default:
+		record.Method = req.OperationName()
+	}
+	return context.WithValue(ctx, CtxCaptureKey, record)
+}
+
+// AfterCompletion writes the record of the captured request with its first response
+func (i *CaptureInterceptor) AfterCompletion(ctx context.Context, _ fiber.Request, queue fiber.ResponseQueue) {
+	record, _ := ctx.Value(CtxCaptureKey).(*CaptureRecord)
+	if record == nil {
+		return
+	}
+
+	for resp := range queue.Iter() {
+		record.Responses++
+		if record.Response != nil {
+			continue
+		}
+		record.Response = &CapturedResponse{
+			Route:      resp.BackendName(),
+			StatusCode: resp.StatusCode(),
+			Success:    resp.IsSuccess(),
+			Payload:    resp.Payload(),
+		}
+		for _, key := range i.options.Labels {
+			if values := resp.Label(key); len(values) > 0 {
+				if record.Response.Labels == nil {
+					record.Response.Labels = make(map[string][]string)
+				}
+				record.Response.Labels[key] = values
+			}
+		}
+	}
+	record.Duration = time.Since(record.Time)
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	_, _ = i.writer.Write(append(line, '\n'))
+}
+
+// Initialize creates the interceptor, declared in the fiber config, that writes the records to the
+// CaptureFile. The properties are the CaptureOptions and the CaptureFileOptions, with the required path
+// of the file, e.g. {"path": "/var/log/fiber/capture.jsonl", "sample_rate": 0.01, "max_size": 104857600}
+func (i *CaptureInterceptor) Initialize(properties json.RawMessage) error {
+	var cfg captureProperties
+	if err := cfg.parse(properties); err != nil {
+		return err
+	}
+
+	file, err := NewCaptureFile(cfg.Path, cfg.CaptureFileOptions)
+	if err != nil {
+		return err
+	}
+	i.writer = file
+	i.options = cfg.CaptureOptions
+	i.file = file
+	return nil
+}
+
+// ValidateProperties checks the properties of the interceptor, declared in the fiber config,
+// without opening the capture file
+func (i *CaptureInterceptor) ValidateProperties(properties json.RawMessage) error {
+	var cfg captureProperties
+	return cfg.parse(properties)
+}
+
+// Close closes the capture file, opened by Initialize
+func (i *CaptureInterceptor) Close() error {
+	if i.file != nil {
+		return i.file.Close()
+	}
+	return nil
+}
+
+// captureProperties are the properties of the CaptureInterceptor, declared in the fiber config
+type captureProperties struct {
+	CaptureOptions
+	CaptureFileOptions
+	Path string `json:"path"`
+}
+
+func (p *captureProperties) parse(properties json.RawMessage) error {
+	if len(properties) > 0 {
+		if err := json.Unmarshal(properties, p); err != nil {
+			return err
+		}
+	}
+	if p.Path == "" {
+		return errors.New("path is required")
+	}
+	return nil
+}
+
+// PropertiesSchema describes the properties of the interceptor, declared in the fiber config
+func (i *CaptureInterceptor) PropertiesSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"path"},
+		"properties": map[string]interface{}{
+			"path":        map[string]interface{}{"type": "string"},
+			"sample_rate": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 1},
+			"labels": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+			"max_size":    map[string]interface{}{"type": "integer", "minimum": 0},
+			"max_backups": map[string]interface{}{"type": "integer", "minimum": 0},
+		},
+	}
+}
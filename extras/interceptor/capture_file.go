@@ -0,0 +1,104 @@
+package interceptor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DefaultCaptureBackups is the number of the rotated files, that the CaptureFile keeps, unless another one
+// is set in the CaptureFileOptions
+const DefaultCaptureBackups = 1
+
+// CaptureFileOptions configure the rotation of the CaptureFile
+type CaptureFileOptions struct {
+	// MaxSize is the size in bytes, that the file is rotated at, the file isn't rotated if zero
+	MaxSize int64 `json:"max_size"`
+	// MaxBackups is the number of the rotated files to keep, as <path>.1 (the newest) to <path>.<MaxBackups>,
+	// DefaultCaptureBackups if zero
+	MaxBackups int `json:"max_backups"`
+}
+
+// CaptureFile is the file, that the CaptureInterceptor writes its records to, rotated once it reaches
+// the maximum size. Every write is expected to be a whole record, the records aren't split between the files
+type CaptureFile struct {
+	mu      sync.Mutex
+	path    string
+	options CaptureFileOptions
+	file    *os.File
+	size    int64
+}
+
+// NewCaptureFile opens the file at the path, appending to it, if it already exists
+func NewCaptureFile(path string, options CaptureFileOptions) (*CaptureFile, error) {
+	if options.MaxBackups <= 0 {
+		options.MaxBackups = DefaultCaptureBackups
+	}
+	f := &CaptureFile{path: path, options: options}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *CaptureFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write writes the record to the file, rotating it first, if the record doesn't fit into it. If the rotation
+// fails, the record isn't written, and the rotation is retried with the next one
+func (f *CaptureFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.options.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.options.MaxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// rotate moves the file to the first backup and opens the new one. The old file is only closed, once the
+// new one is opened, so that the records are still written to it, if the rotation fails
+func (f *CaptureFile) rotate() error {
+	for n := f.options.MaxBackups - 1; n > 0; n-- {
+		if err := os.Rename(backupPath(f.path, n), backupPath(f.path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, backupPath(f.path, 1)); err != nil {
+		return err
+	}
+	old := f.file
+	if err := f.open(); err != nil {
+		// the file is moved back, as the records are still written to it
+		_ = os.Rename(backupPath(f.path, 1), f.path)
+		return err
+	}
+	_ = old.Close()
+	return nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// Close closes the file
+func (f *CaptureFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
@@ -0,0 +1,98 @@
+package interceptor_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gojek/fiber/extras/interceptor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertFile(t *testing.T, path string, expected string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(content))
+}
+
+func TestCaptureFile_Rotate(t *testing.T) {
+	tests := map[string]struct {
+		maxBackups int
+		expected   map[string]string
+	}{
+		"default backups": {
+			expected: map[string]string{"capture": "e\n", "capture.1": "c\nd\n"},
+		},
+		"max backups": {
+			maxBackups: 2,
+			expected:   map[string]string{"capture": "e\n", "capture.1": "c\nd\n", "capture.2": "a\nb\n"},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "capture")
+			file, err := interceptor.NewCaptureFile(path, interceptor.CaptureFileOptions{
+				MaxSize:    4,
+				MaxBackups: tt.maxBackups,
+			})
+			require.NoError(t, err)
+			defer file.Close()
+
+			// the records aren't split between the files
+			for _, record := range []string{"a\n", "b\n", "c\n", "d\n", "e\n"} {
+				_, err := file.Write([]byte(record))
+				require.NoError(t, err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, len(tt.expected))
+			for name, expected := range tt.expected {
+				assertFile(t, filepath.Join(dir, name), expected)
+			}
+		})
+	}
+}
+
+func TestCaptureFile_RotateFailed(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "capture")
+	file, err := interceptor.NewCaptureFile(path, interceptor.CaptureFileOptions{MaxSize: 4})
+	require.NoError(t, err)
+	defer file.Close()
+
+	_, err = file.Write([]byte("a\nb\n"))
+	require.NoError(t, err)
+
+	// the file can't be moved to the backup, which is a non-empty directory
+	require.NoError(t, os.MkdirAll(filepath.Join(path+".1", "dir"), 0700))
+	_, err = file.Write([]byte("c\n"))
+	assert.Error(t, err)
+
+	// the file is still open, and the rotation is retried with the next record
+	require.NoError(t, os.RemoveAll(path+".1"))
+	_, err = file.Write([]byte("d\n"))
+	require.NoError(t, err)
+	assertFile(t, path+".1", "a\nb\n")
+	assertFile(t, path, "d\n")
+}
+
+func TestCaptureFile_Append(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture")
+	require.NoError(t, os.WriteFile(path, []byte("a\n"), 0600))
+
+	file, err := interceptor.NewCaptureFile(path, interceptor.CaptureFileOptions{MaxSize: 4})
+	require.NoError(t, err)
+	_, err = file.Write([]byte("b\n"))
+	require.NoError(t, err)
+	// the size of the existing file counts towards the maximum one
+	_, err = file.Write([]byte("c\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assertFile(t, path+".1", "a\nb\n")
+	assertFile(t, path, "c\n")
+}
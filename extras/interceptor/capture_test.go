@@ -0,0 +1,143 @@
+package interceptor_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/extras/interceptor"
+	fiberhttp "github.com/gojek/fiber/http"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is the buffer, that the records are written to and read from concurrently
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []interceptor.CaptureRecord {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	records, err := interceptor.ReadCaptureRecords(bytes.NewReader(b.buf.Bytes()))
+	require.NoError(t, err)
+	return records
+}
+
+func TestCaptureInterceptor(t *testing.T) {
+	var buf syncBuffer
+	route := newRoute(t, "route_a", httpReply(http.StatusCreated, "created"))
+	route.AddInterceptor(false, interceptor.NewCaptureInterceptor(&buf, interceptor.CaptureOptions{}))
+
+	req := testUtilsHttp.MockReq(http.MethodPost, "http://localhost/orders", `{"id":1}`)
+	req.Header()["Accept"] = []string{"application/json"}
+	resp := dispatch(t, route, req)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode())
+	assert.Equal(t, "created", string(resp.Payload()))
+
+	var records []interceptor.CaptureRecord
+	require.Eventually(t, func() bool {
+		records = buf.records(t)
+		return len(records) == 1
+	}, time.Second, 10*time.Millisecond)
+	record := records[0]
+	assert.Equal(t, "route_a", record.Component)
+	assert.Equal(t, http.MethodPost, record.Method)
+	assert.Equal(t, "http://localhost/orders", record.URL)
+	assert.Equal(t, `{"id":1}`, string(record.Payload))
+	require.NotNil(t, record.Response)
+	assert.Equal(t, http.StatusCreated, record.Response.StatusCode)
+	assert.Equal(t, "created", string(record.Response.Payload))
+	assert.Equal(t, 1, record.Responses)
+
+	// the captured request is recreated for the replay
+	replayed, err := record.NewRequest()
+	require.NoError(t, err)
+	httpReq, ok := replayed.(*fiberhttp.Request)
+	require.True(t, ok)
+	assert.Equal(t, http.MethodPost, httpReq.Method)
+	assert.Equal(t, "http://localhost/orders", httpReq.URL.String())
+	assert.Equal(t, []string{"application/json"}, httpReq.Header()["Accept"])
+	assert.Equal(t, `{"id":1}`, string(httpReq.Payload()))
+}
+
+// sampledProbe counts the requests, that the CaptureInterceptor before it sampled
+type sampledProbe struct {
+	fiber.NoopAfterDispatchInterceptor
+	fiber.NoopAfterCompletionInterceptor
+	mu      sync.Mutex
+	sampled int
+}
+
+func (p *sampledProbe) BeforeDispatch(ctx context.Context, _ fiber.Request) context.Context {
+	if record, _ := ctx.Value(interceptor.CtxCaptureKey).(*interceptor.CaptureRecord); record != nil {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.sampled++
+	}
+	return ctx
+}
+
+func TestCaptureInterceptor_Sampling(t *testing.T) {
+	tests := map[string]struct {
+		sampleRate float64
+		min        int
+		max        int
+	}{
+		"all by default": {min: 100, max: 100},
+		"sampled":        {sampleRate: 0.5, min: 1, max: 99},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf syncBuffer
+			probe := &sampledProbe{}
+			route := newRoute(t, "route_a", httpReply(http.StatusOK, "ok"))
+			route.AddInterceptor(false, interceptor.NewCaptureInterceptor(&buf, interceptor.CaptureOptions{
+				SampleRate: tt.sampleRate,
+			}), probe)
+
+			for i := 0; i < 100; i++ {
+				dispatch(t, route, testUtilsHttp.MockReq(http.MethodGet, "http://localhost", ""))
+			}
+			assert.GreaterOrEqual(t, probe.sampled, tt.min)
+			assert.LessOrEqual(t, probe.sampled, tt.max)
+			// only the sampled requests are written
+			require.Eventually(t, func() bool {
+				return len(buf.records(t)) == probe.sampled
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestCaptureInterceptor_Close(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	i := &interceptor.CaptureInterceptor{}
+	require.NoError(t, i.Initialize(json.RawMessage(`{"path": "`+path+`"}`)))
+
+	route := newRoute(t, "route_a", httpReply(http.StatusOK, "ok"))
+	route.AddInterceptor(false, i)
+	dispatch(t, route, testUtilsHttp.MockReq(http.MethodGet, "http://localhost", ""))
+	require.Eventually(t, func() bool {
+		content, err := os.ReadFile(path)
+		return err == nil && bytes.Count(content, []byte("\n")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// the capture file, opened by Initialize, is closed with the interceptor
+	require.NoError(t, i.Close())
+	assert.Error(t, i.Close())
+}
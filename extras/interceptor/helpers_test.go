@@ -0,0 +1,57 @@
+package interceptor_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/stretchr/testify/require"
+)
+
+// reply creates the response of the fake route to the request
+type reply func() fiber.Response
+
+// httpReply replies with the http response with the status code and the body
+func httpReply(code int, body string) reply {
+	return func() fiber.Response {
+		return testUtilsHttp.MockResp(code, body, nil, nil)
+	}
+}
+
+// scriptedDispatcher replies to the requests with the replies in their order, repeating the last one
+type scriptedDispatcher struct {
+	mu      sync.Mutex
+	replies []reply
+	calls   int
+}
+
+func (d *scriptedDispatcher) Do(fiber.Request) fiber.Response {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	idx := d.calls
+	if idx >= len(d.replies) {
+		idx = len(d.replies) - 1
+	}
+	d.calls++
+	return d.replies[idx]()
+}
+
+// newRoute creates the caller, whose dispatcher replies with the replies
+func newRoute(t *testing.T, id string, replies ...reply) *fiber.Caller {
+	caller, err := fiber.NewCaller(id, &scriptedDispatcher{replies: replies})
+	require.NoError(t, err)
+	return caller
+}
+
+// dispatch dispatches the request by the component and returns its first response
+func dispatch(t *testing.T, component fiber.Component, req fiber.Request) fiber.Response {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, ok := <-component.Dispatch(ctx, req).Iter()
+	require.True(t, ok, "component %s returned no response", component.ID())
+	return resp
+}
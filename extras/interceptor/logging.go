@@ -2,10 +2,15 @@ package interceptor
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/gojek/fiber"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 // NewLoggingInterceptor is a creator factory for a ResponseLoggingInterceptor
@@ -20,6 +25,8 @@ type ResponseLoggingInterceptor struct {
 	fiber.NoopBeforeDispatchInterceptor
 	fiber.NoopAfterCompletionInterceptor
 	logger *zap.SugaredLogger
+	// closeLogger closes the output of the logger, created by Initialize
+	closeLogger func()
 }
 
 // AfterDispatch logs the success or failure information of a request
@@ -32,3 +39,83 @@ func (i *ResponseLoggingInterceptor) AfterDispatch(ctx context.Context, req fibe
 		}
 	}
 }
+
+// Initialize creates the logger of the interceptor, declared in the fiber config. The level
+// of the logged messages can be set in the properties, e.g. {"level": "warn"}, "info" by default
+func (i *ResponseLoggingInterceptor) Initialize(properties json.RawMessage) error {
+	level, err := parseLoggingProperties(properties)
+	if err != nil {
+		return err
+	}
+	logger, closeLogger, err := newZapLogger(level, "stderr", true)
+	if err != nil {
+		return err
+	}
+	i.logger, i.closeLogger = logger.Sugar(), closeLogger
+	return nil
+}
+
+// ValidateProperties checks the properties of the interceptor, declared in the fiber config,
+// without creating its logger
+func (i *ResponseLoggingInterceptor) ValidateProperties(properties json.RawMessage) error {
+	_, err := parseLoggingProperties(properties)
+	return err
+}
+
+// parseLoggingProperties parses the level of the ResponseLoggingInterceptor, declared in the fiber config
+func parseLoggingProperties(properties json.RawMessage) (zap.AtomicLevel, error) {
+	var cfg struct {
+		Level string `json:"level"`
+	}
+	if len(properties) > 0 {
+		if err := json.Unmarshal(properties, &cfg); err != nil {
+			return zap.AtomicLevel{}, err
+		}
+	}
+
+	if cfg.Level == "" {
+		return zap.NewAtomicLevelAt(zap.InfoLevel), nil
+	}
+	level, err := zap.ParseAtomicLevel(cfg.Level)
+	if err != nil {
+		return zap.AtomicLevel{}, fmt.Errorf("invalid log level: %w", err)
+	}
+	return level, nil
+}
+
+// Close flushes and closes the logger, created by Initialize
+func (i *ResponseLoggingInterceptor) Close() error {
+	if i.closeLogger != nil {
+		i.closeLogger()
+	}
+	return nil
+}
+
+// newZapLogger creates the production zap logger (see zap.NewProductionConfig), that writes to the output path,
+// and the function, that flushes and closes its output. The logged messages are sampled, if sampled is set
+func newZapLogger(level zap.AtomicLevel, output string, sampled bool) (*zap.Logger, func(), error) {
+	sink, closeSink, err := zap.Open(output)
+	if err != nil {
+		return nil, nil, err
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), sink, level)
+	if sampled {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+	}
+	logger := zap.New(core, zap.ErrorOutput(zapcore.Lock(zapcore.AddSync(os.Stderr))))
+	return logger, func() {
+		// the errors of syncing the standard streams are expected on some platforms
+		_ = logger.Sync()
+		closeSink()
+	}, nil
+}
+
+// PropertiesSchema describes the properties of the interceptor, declared in the fiber config
+func (i *ResponseLoggingInterceptor) PropertiesSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"level": map[string]interface{}{"enum": []string{"debug", "info", "warn", "error"}},
+		},
+	}
+}
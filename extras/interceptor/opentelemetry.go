@@ -0,0 +1,199 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gojek/fiber"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The attributes of the spans, started by the OpenTelemetryInterceptor
+const (
+	AttributeComponentID   = attribute.Key("fiber.component.id")
+	AttributeComponentKind = attribute.Key("fiber.component.kind")
+	AttributeProtocol      = attribute.Key("fiber.protocol")
+	AttributeOperation     = attribute.Key("fiber.operation")
+	AttributeRoute         = attribute.Key("fiber.route")
+	AttributeStatusCode    = attribute.Key("fiber.status_code")
+	// AttributeLabelPrefix is the prefix of the attributes, that the labels of the response are recorded with
+	AttributeLabelPrefix = "fiber.label."
+)
+
+const tracerName = "github.com/gojek/fiber"
+
+// maxErrorPayload is the maximum size of the payload of the unsuccessful response, recorded as the error
+const maxErrorPayload = 512
+
+// OpenTelemetryOptions captures a set of options of the OpenTelemetryInterceptor
+type OpenTelemetryOptions struct {
+	// TracerProvider creates the tracer of the interceptor, the global one is used (see otel.SetTracerProvider), if nil
+	TracerProvider trace.TracerProvider
+	// Propagator extracts the trace context of the incoming requests, and injects it into the calls
+	// of the dispatchers to the backends. The global one is used (see otel.SetTextMapPropagator), if nil
+	Propagator propagation.TextMapPropagator
+	// Labels are the keys of the response labels (such as the ones set by the routing strategies),
+	// that are recorded as the span attributes, prefixed with AttributeLabelPrefix
+	Labels []string
+}
+
+// OpenTelemetryInterceptor traces the requests with OpenTelemetry. Every component, that the interceptor
+// is added to, starts a span, that is a child of the span in the request's context or, if there is none,
+// continues the trace of the incoming request (e.g. its W3C traceparent header). The span is ended, once
+// all the responses are received, and is tagged with the component ID and kind, as well as the route,
+// status code and the labels of the first response. Unsuccessful responses are recorded as errors.
+//
+// The spans of the callers are the client spans of the calls to the backends: http.Dispatcher and
+// grpc.Dispatcher inject their context into the requests with the propagator of the interceptor
+// (see fiber.CtxTextMapPropagatorKey), so the spans of the backends join the trace
+type OpenTelemetryInterceptor struct {
+	fiber.NoopAfterDispatchInterceptor
+
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	labels     []string
+}
+
+// NewOpenTelemetryInterceptor creates the OpenTelemetryInterceptor with the given options
+func NewOpenTelemetryInterceptor(options OpenTelemetryOptions) *OpenTelemetryInterceptor {
+	i := &OpenTelemetryInterceptor{}
+	i.init(options)
+	return i
+}
+
+func (i *OpenTelemetryInterceptor) init(options OpenTelemetryOptions) {
+	if options.TracerProvider == nil {
+		options.TracerProvider = otel.GetTracerProvider()
+	}
+	if options.Propagator == nil {
+		options.Propagator = otel.GetTextMapPropagator()
+	}
+	i.tracer = options.TracerProvider.Tracer(tracerName)
+	i.propagator = options.Propagator
+	i.labels = options.Labels
+}
+
+// BeforeDispatch starts the span of the component
+func (i *OpenTelemetryInterceptor) BeforeDispatch(ctx context.Context, req fiber.Request) context.Context {
+	tracer, propagator := i.tracer, i.propagator
+	if tracer == nil {
+		tracer, propagator = otel.Tracer(tracerName), otel.GetTextMapPropagator()
+	}
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = propagator.Extract(ctx, headerCarrier(req.Header()))
+	}
+	ctx = context.WithValue(ctx, fiber.CtxTextMapPropagatorKey, propagator)
+
+	componentID, _ := ctx.Value(fiber.CtxComponentIDKey).(string)
+	kind, _ := ctx.Value(fiber.CtxComponentKindKey).(fiber.ComponentKind)
+	spanKind := trace.SpanKindInternal
+	if kind == fiber.CallerKind {
+		spanKind = trace.SpanKindClient
+	}
+
+	ctx, _ = tracer.Start(ctx, fmt.Sprintf("%s %s", componentID, req.OperationName()),
+		trace.WithSpanKind(spanKind),
+		trace.WithAttributes(
+			AttributeComponentID.String(componentID),
+			AttributeComponentKind.String(string(kind)),
+			AttributeProtocol.String(string(req.Protocol())),
+			AttributeOperation.String(req.OperationName()),
+		))
+	return ctx
+}
+
+// AfterCompletion tags the span with the first response, records the unsuccessful responses as errors
+// and ends the span
+func (i *OpenTelemetryInterceptor) AfterCompletion(ctx context.Context, _ fiber.Request, queue fiber.ResponseQueue) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+
+	first := true
+	for resp := range queue.Iter() {
+		if first {
+			first = false
+			span.SetAttributes(
+				AttributeRoute.String(resp.BackendName()),
+				AttributeStatusCode.Int(resp.StatusCode()),
+			)
+			for _, key := range i.labels {
+				if values := resp.Label(key); len(values) > 0 {
+					span.SetAttributes(attribute.StringSlice(AttributeLabelPrefix+key, values))
+				}
+			}
+			if !resp.IsSuccess() {
+				span.SetStatus(codes.Error, fmt.Sprintf("status code %d", resp.StatusCode()))
+			}
+		}
+		if !resp.IsSuccess() {
+			payload := resp.Payload()
+			if len(payload) > maxErrorPayload {
+				payload = payload[:maxErrorPayload]
+			}
+			span.RecordError(fmt.Errorf("%s: %s", resp.BackendName(), payload),
+				trace.WithAttributes(AttributeStatusCode.Int(resp.StatusCode())))
+		}
+	}
+	if first {
+		span.SetStatus(codes.Error, "no responses")
+	}
+}
+
+// Initialize creates the tracer of the interceptor, declared in the fiber config, with the global
+// tracer provider and propagator. The response labels to record can be set in the properties,
+// e.g. {"labels": ["experiment"]}
+func (i *OpenTelemetryInterceptor) Initialize(properties json.RawMessage) error {
+	var cfg struct {
+		Labels []string `json:"labels"`
+	}
+	if len(properties) > 0 {
+		if err := json.Unmarshal(properties, &cfg); err != nil {
+			return err
+		}
+	}
+	i.init(OpenTelemetryOptions{Labels: cfg.Labels})
+	return nil
+}
+
+// PropertiesSchema describes the properties of the interceptor, declared in the fiber config
+func (i *OpenTelemetryInterceptor) PropertiesSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"labels": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+}
+
+// headerCarrier extracts the trace context from the headers of the http request or the metadata
+// of the grpc request, whose keys are lower-cased
+type headerCarrier map[string][]string
+
+func (c headerCarrier) Get(key string) string {
+	for k, values := range c {
+		if strings.EqualFold(k, key) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// Set does nothing, the headers of the incoming request are not modified
+func (c headerCarrier) Set(string, string) {}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
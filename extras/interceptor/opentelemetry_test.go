@@ -0,0 +1,135 @@
+package interceptor_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/extras/interceptor"
+	fiberhttp "github.com/gojek/fiber/http"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOpenTelemetryInterceptor_Propagator(t *testing.T) {
+	traceparents := make(chan string, 1)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer backend.Close()
+
+	dispatcher, err := fiberhttp.NewDispatcher(http.DefaultClient)
+	require.NoError(t, err)
+	caller, err := fiber.NewCaller("route_a", dispatcher)
+	require.NoError(t, err)
+	proxy := fiber.NewProxy(fiber.NewBackend("route_a", backend.URL), caller)
+
+	// the global propagator is a no-op, so the trace context is injected with the interceptor's own one
+	recorder := tracetest.NewSpanRecorder()
+	proxy.AddInterceptor(true, interceptor.NewOpenTelemetryInterceptor(interceptor.OpenTelemetryOptions{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Propagator:     propagation.TraceContext{},
+	}))
+
+	resp := dispatch(t, proxy, testUtilsHttp.MockReq(http.MethodGet, backend.URL, ""))
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+	assert.Equal(t, "ok", string(resp.Payload()))
+
+	var clientSpan sdktrace.ReadOnlySpan
+	require.Eventually(t, func() bool {
+		for _, span := range recorder.Ended() {
+			if span.SpanKind() == trace.SpanKindClient {
+				clientSpan = span
+				return true
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	// the backend receives the context of the caller's client span
+	expected := fmt.Sprintf("00-%s-%s-01", clientSpan.SpanContext().TraceID(), clientSpan.SpanContext().SpanID())
+	assert.Equal(t, expected, <-traceparents)
+}
+
+func TestOpenTelemetryInterceptor_Spans(t *testing.T) {
+	routeA := newRoute(t, "route_a", httpReply(http.StatusBadGateway, "failed"))
+	routeB := newRoute(t, "route_b", func() fiber.Response {
+		return testUtilsHttp.MockResp(http.StatusOK, "ok", nil, nil).WithLabel("experiment", "a")
+	})
+	fanOut := fiber.NewFanOut("fan_out")
+	fanOut.SetRoutes(map[string]fiber.Component{"route_a": routeA, "route_b": routeB})
+
+	recorder := tracetest.NewSpanRecorder()
+	fanOut.AddInterceptor(true, interceptor.NewOpenTelemetryInterceptor(interceptor.OpenTelemetryOptions{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Propagator:     propagation.TraceContext{},
+		Labels:         []string{"experiment"},
+	}))
+
+	// the trace of the incoming request is continued
+	req := testUtilsHttp.MockReq(http.MethodGet, "http://localhost/users", "")
+	req.Header()["Traceparent"] = []string{"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"}
+	// all the responses are received, before the context of the dispatch is done
+	for range fanOut.Dispatch(context.Background(), req).Iter() {
+	}
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	require.Eventually(t, func() bool {
+		for _, span := range recorder.Ended() {
+			spans[span.Name()] = span
+		}
+		return len(spans) == 3
+	}, time.Second, 10*time.Millisecond)
+
+	root := spans["fan_out GET /users"]
+	require.NotNil(t, root)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", root.SpanContext().TraceID().String())
+	assert.Equal(t, "b7ad6b7169203331", root.Parent().SpanID().String())
+	assert.Equal(t, trace.SpanKindInternal, root.SpanKind())
+	assert.Contains(t, root.Attributes(), interceptor.AttributeComponentID.String("fan_out"))
+	assert.Contains(t, root.Attributes(), interceptor.AttributeProtocol.String("HTTP"))
+	assert.Contains(t, root.Attributes(), interceptor.AttributeOperation.String("GET /users"))
+
+	// the fan out is tagged with the route of its first response
+	attrs := attribute.NewSet(root.Attributes()...)
+	route, _ := attrs.Value(interceptor.AttributeRoute)
+	assert.Contains(t, []string{"route_a", "route_b"}, route.AsString())
+
+	// every unsuccessful response is recorded as the error
+	require.Len(t, root.Events(), 1)
+	assert.Equal(t, "exception", root.Events()[0].Name)
+
+	failed := spans["route_a GET /users"]
+	require.NotNil(t, failed)
+	assert.Equal(t, root.SpanContext().SpanID(), failed.Parent().SpanID())
+	assert.Equal(t, trace.SpanKindClient, failed.SpanKind())
+	assert.Contains(t, failed.Attributes(), interceptor.AttributeStatusCode.Int(http.StatusBadGateway))
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Equal(t, "status code 502", failed.Status().Description)
+	require.Len(t, failed.Events(), 1)
+	assert.Equal(t, "exception", failed.Events()[0].Name)
+	eventAttrs := attribute.NewSet(failed.Events()[0].Attributes...)
+	message, _ := eventAttrs.Value("exception.message")
+	assert.Contains(t, message.AsString(), "failed")
+	assert.Contains(t, failed.Events()[0].Attributes, interceptor.AttributeStatusCode.Int(http.StatusBadGateway))
+
+	succeeded := spans["route_b GET /users"]
+	require.NotNil(t, succeeded)
+	assert.Contains(t, succeeded.Attributes(), interceptor.AttributeStatusCode.Int(http.StatusOK))
+	assert.Contains(t, succeeded.Attributes(),
+		attribute.StringSlice(interceptor.AttributeLabelPrefix+"experiment", []string{"a"}))
+	assert.Equal(t, codes.Unset, succeeded.Status().Code)
+	assert.Empty(t, succeeded.Events())
+}
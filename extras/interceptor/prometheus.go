@@ -0,0 +1,240 @@
+package interceptor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// CtxPrometheusStartTimeKey is used to record the start time of a request, measured by the PrometheusInterceptor
+var CtxPrometheusStartTimeKey MetricsKey = "CTX_PROMETHEUS_START_TIME"
+
+// The labels of the metrics, collected by the PrometheusInterceptor
+const (
+	LabelComponent = "component"
+	LabelKind      = "kind"
+	LabelProtocol  = "protocol"
+	LabelCode      = "code"
+	LabelSuccess   = "success"
+)
+
+// PrometheusOptions captures a set of options of the PrometheusInterceptor
+type PrometheusOptions struct {
+	// Namespace is the prefix of the metric names, "fiber" by default
+	Namespace string `json:"namespace"`
+	// DurationBuckets are the buckets of the request duration histogram, in seconds.
+	// prometheus.DefBuckets are used, if empty
+	DurationBuckets []float64 `json:"duration_buckets"`
+	// SizeBuckets are the buckets of the request and response payload size histograms, in bytes.
+	// Exponential buckets from 64B to 4MB are used, if empty
+	SizeBuckets []float64 `json:"size_buckets"`
+}
+
+// PrometheusInterceptor collects the Prometheus metrics of the requests, dispatched by the components:
+//
+//	<namespace>_requests_total                   counter of the dispatched requests
+//	<namespace>_request_duration_seconds         histogram of the time until the dispatch is completed
+//	<namespace>_dispatches_in_flight             gauge of the requests, that are being dispatched
+//	<namespace>_request_size_bytes               histogram of the request payload sizes
+//	<namespace>_response_size_bytes              histogram of the response payload sizes
+//
+// The metrics are labeled with the component ID, kind and the protocol of the request. The requests
+// and their durations are also labeled with the status code and success of the first response, and
+// every response is labeled with its own status code and success
+type PrometheusInterceptor struct {
+	fiber.NoopAfterDispatchInterceptor
+
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// NewPrometheusInterceptor creates the PrometheusInterceptor and registers its metrics with the
+// registerer. The metrics, that are already registered (e.g. by another PrometheusInterceptor with
+// the same options), are shared
+func NewPrometheusInterceptor(registerer prometheus.Registerer, options PrometheusOptions) (*PrometheusInterceptor, error) {
+	i := &PrometheusInterceptor{}
+	if err := i.register(registerer, options); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func (i *PrometheusInterceptor) register(registerer prometheus.Registerer, options PrometheusOptions) error {
+	if options.Namespace == "" {
+		options.Namespace = "fiber"
+	}
+	if len(options.DurationBuckets) == 0 {
+		options.DurationBuckets = prometheus.DefBuckets
+	}
+	if len(options.SizeBuckets) == 0 {
+		options.SizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+	}
+
+	requestLabels := []string{LabelComponent, LabelKind, LabelProtocol}
+	responseLabels := []string{LabelComponent, LabelKind, LabelProtocol, LabelCode, LabelSuccess}
+
+	i.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: options.Namespace,
+		Name:      "requests_total",
+		Help:      "Number of the requests, dispatched by the fiber components.",
+	}, responseLabels)
+	i.duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: options.Namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Time until the dispatch of the request by the fiber component is completed.",
+		Buckets:   options.DurationBuckets,
+	}, responseLabels)
+	i.inFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: options.Namespace,
+		Name:      "dispatches_in_flight",
+		Help:      "Number of the requests, that are being dispatched by the fiber components.",
+	}, requestLabels)
+	i.requestSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: options.Namespace,
+		Name:      "request_size_bytes",
+		Help:      "Payload sizes of the requests, dispatched by the fiber components.",
+		Buckets:   options.SizeBuckets,
+	}, requestLabels)
+	i.responseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: options.Namespace,
+		Name:      "response_size_bytes",
+		Help:      "Payload sizes of the responses, returned by the fiber components.",
+		Buckets:   options.SizeBuckets,
+	}, responseLabels)
+
+	var err error
+	if i.requests, err = registerOrExisting(registerer, i.requests); err != nil {
+		return err
+	}
+	if i.duration, err = registerOrExisting(registerer, i.duration); err != nil {
+		return err
+	}
+	if i.inFlight, err = registerOrExisting(registerer, i.inFlight); err != nil {
+		return err
+	}
+	if i.requestSize, err = registerOrExisting(registerer, i.requestSize); err != nil {
+		return err
+	}
+	i.responseSize, err = registerOrExisting(registerer, i.responseSize)
+	return err
+}
+
+// registerOrExisting registers the collector, or returns the collector already registered
+// with the same description
+func registerOrExisting[T prometheus.Collector](registerer prometheus.Registerer, collector T) (T, error) {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing, nil
+			}
+		}
+		return collector, err
+	}
+	return collector, nil
+}
+
+func requestLabels(ctx context.Context, req fiber.Request) prometheus.Labels {
+	componentID, _ := ctx.Value(fiber.CtxComponentIDKey).(string)
+	kind, _ := ctx.Value(fiber.CtxComponentKindKey).(fiber.ComponentKind)
+	return prometheus.Labels{
+		LabelComponent: componentID,
+		LabelKind:      string(kind),
+		LabelProtocol:  string(req.Protocol()),
+	}
+}
+
+func responseLabels(labels prometheus.Labels, code string, success bool) prometheus.Labels {
+	withResponse := prometheus.Labels{LabelCode: code, LabelSuccess: strconv.FormatBool(success)}
+	for name, value := range labels {
+		withResponse[name] = value
+	}
+	return withResponse
+}
+
+// BeforeDispatch records the request in flight, its payload size and the start time of the dispatch
+func (i *PrometheusInterceptor) BeforeDispatch(ctx context.Context, req fiber.Request) context.Context {
+	labels := requestLabels(ctx, req)
+	i.inFlight.With(labels).Inc()
+	i.requestSize.With(labels).Observe(float64(len(req.Payload())))
+	return context.WithValue(ctx, CtxPrometheusStartTimeKey, time.Now())
+}
+
+// AfterCompletion records the request with its duration and the sizes of its responses, once all of
+// them are received. The requests without responses are labeled with an empty status code
+func (i *PrometheusInterceptor) AfterCompletion(ctx context.Context, req fiber.Request, queue fiber.ResponseQueue) {
+	labels := requestLabels(ctx, req)
+	defer i.inFlight.With(labels).Dec()
+
+	code, success := "", false
+	first := true
+	for resp := range queue.Iter() {
+		respCode, respSuccess := strconv.Itoa(resp.StatusCode()), resp.IsSuccess()
+		if first {
+			code, success, first = respCode, respSuccess, false
+		}
+		i.responseSize.With(responseLabels(labels, respCode, respSuccess)).Observe(float64(len(resp.Payload())))
+	}
+
+	i.requests.With(responseLabels(labels, code, success)).Inc()
+	if startTime, ok := ctx.Value(CtxPrometheusStartTimeKey).(time.Time); ok {
+		i.duration.With(responseLabels(labels, code, success)).Observe(time.Since(startTime).Seconds())
+	}
+}
+
+// Initialize registers the metrics of the interceptor, declared in the fiber config, with the
+// prometheus.DefaultRegisterer. The properties are the PrometheusOptions, e.g. {"namespace": "router"}
+func (i *PrometheusInterceptor) Initialize(properties json.RawMessage) error {
+	var options PrometheusOptions
+	if len(properties) > 0 {
+		if err := json.Unmarshal(properties, &options); err != nil {
+			return err
+		}
+	}
+	return i.register(prometheus.DefaultRegisterer, options)
+}
+
+// ValidateProperties checks the properties of the interceptor, declared in the fiber config, by
+// registering its metrics with a new registry, instead of the prometheus.DefaultRegisterer
+func (i *PrometheusInterceptor) ValidateProperties(properties json.RawMessage) error {
+	var options PrometheusOptions
+	if len(properties) > 0 {
+		if err := json.Unmarshal(properties, &options); err != nil {
+			return err
+		}
+	}
+	return (&PrometheusInterceptor{}).register(prometheus.NewRegistry(), options)
+}
+
+// PropertiesSchema describes the properties of the interceptor, declared in the fiber config
+func (i *PrometheusInterceptor) PropertiesSchema() map[string]interface{} {
+	buckets := map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "number"},
+	}
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"namespace":        map[string]interface{}{"type": "string"},
+			"duration_buckets": buckets,
+			"size_buckets":     buckets,
+		},
+	}
+}
+
+// NewPrometheusHandler creates the handler, that exposes the metrics of the gatherer (e.g. the
+// prometheus.DefaultGatherer), in the Prometheus text or the OpenMetrics format, as negotiated
+// with the scraper
+func NewPrometheusHandler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
+}
@@ -0,0 +1,94 @@
+package interceptor_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/extras/interceptor"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertMetrics waits for the metrics of the registry to match the expected ones, as the interceptors
+// record the completed dispatches concurrently
+func assertMetrics(t *testing.T, registry *prometheus.Registry, expected string, names ...string) {
+	t.Helper()
+	var err error
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if err = testutil.GatherAndCompare(registry, strings.NewReader(expected), names...); err == nil {
+			return
+		}
+	}
+	assert.NoError(t, err)
+}
+
+func TestPrometheusInterceptor(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	prometheusInterceptor, err := interceptor.NewPrometheusInterceptor(registry, interceptor.PrometheusOptions{
+		Namespace:   "test",
+		SizeBuckets: []float64{4, 16},
+	})
+	require.NoError(t, err)
+
+	routeA := newRoute(t, "route_a", httpReply(http.StatusOK, "ok"), httpReply(http.StatusBadGateway, "failed"))
+	fanOut := fiber.NewFanOut("fan_out")
+	fanOut.SetRoutes(map[string]fiber.Component{"route_a": routeA})
+	fanOut.AddInterceptor(true, prometheusInterceptor)
+
+	dispatch(t, fanOut, testUtilsHttp.MockReq(http.MethodPost, "http://localhost", "request"))
+	dispatch(t, fanOut, testUtilsHttp.MockReq(http.MethodPost, "http://localhost", "request"))
+
+	// the requests are labeled with the component and the first response
+	assertMetrics(t, registry, `
+# HELP test_requests_total Number of the requests, dispatched by the fiber components.
+# TYPE test_requests_total counter
+test_requests_total{code="200",component="fan_out",kind="MultiRouteComponent",protocol="HTTP",success="true"} 1
+test_requests_total{code="200",component="route_a",kind="Caller",protocol="HTTP",success="true"} 1
+test_requests_total{code="502",component="fan_out",kind="MultiRouteComponent",protocol="HTTP",success="false"} 1
+test_requests_total{code="502",component="route_a",kind="Caller",protocol="HTTP",success="false"} 1
+# HELP test_dispatches_in_flight Number of the requests, that are being dispatched by the fiber components.
+# TYPE test_dispatches_in_flight gauge
+test_dispatches_in_flight{component="fan_out",kind="MultiRouteComponent",protocol="HTTP"} 0
+test_dispatches_in_flight{component="route_a",kind="Caller",protocol="HTTP"} 0
+# HELP test_request_size_bytes Payload sizes of the requests, dispatched by the fiber components.
+# TYPE test_request_size_bytes histogram
+test_request_size_bytes_bucket{component="fan_out",kind="MultiRouteComponent",protocol="HTTP",le="4"} 0
+test_request_size_bytes_bucket{component="fan_out",kind="MultiRouteComponent",protocol="HTTP",le="16"} 2
+test_request_size_bytes_bucket{component="fan_out",kind="MultiRouteComponent",protocol="HTTP",le="+Inf"} 2
+test_request_size_bytes_sum{component="fan_out",kind="MultiRouteComponent",protocol="HTTP"} 14
+test_request_size_bytes_count{component="fan_out",kind="MultiRouteComponent",protocol="HTTP"} 2
+test_request_size_bytes_bucket{component="route_a",kind="Caller",protocol="HTTP",le="4"} 0
+test_request_size_bytes_bucket{component="route_a",kind="Caller",protocol="HTTP",le="16"} 2
+test_request_size_bytes_bucket{component="route_a",kind="Caller",protocol="HTTP",le="+Inf"} 2
+test_request_size_bytes_sum{component="route_a",kind="Caller",protocol="HTTP"} 14
+test_request_size_bytes_count{component="route_a",kind="Caller",protocol="HTTP"} 2
+`, "test_requests_total", "test_dispatches_in_flight", "test_request_size_bytes")
+
+	// every response is observed with its own status code
+	assert.Equal(t, 4, testutil.CollectAndCount(registry, "test_response_size_bytes"))
+	assert.Equal(t, 4, testutil.CollectAndCount(registry, "test_request_duration_seconds"))
+
+	// the metrics are shared by the interceptors with the same options
+	shared, err := interceptor.NewPrometheusInterceptor(registry, interceptor.PrometheusOptions{
+		Namespace:   "test",
+		SizeBuckets: []float64{4, 16},
+	})
+	require.NoError(t, err)
+	routeB := newRoute(t, "route_a", httpReply(http.StatusOK, "ok"))
+	routeB.AddInterceptor(false, shared)
+	dispatch(t, routeB, testUtilsHttp.MockReq(http.MethodGet, "http://localhost", ""))
+	assertMetrics(t, registry, `
+# HELP test_requests_total Number of the requests, dispatched by the fiber components.
+# TYPE test_requests_total counter
+test_requests_total{code="200",component="fan_out",kind="MultiRouteComponent",protocol="HTTP",success="true"} 1
+test_requests_total{code="200",component="route_a",kind="Caller",protocol="HTTP",success="true"} 2
+test_requests_total{code="502",component="fan_out",kind="MultiRouteComponent",protocol="HTTP",success="false"} 1
+test_requests_total{code="502",component="route_a",kind="Caller",protocol="HTTP",success="false"} 1
+`, "test_requests_total")
+}
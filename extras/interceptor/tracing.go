@@ -16,8 +16,10 @@ func NewTracingInterceptor(tracer opentracing.Tracer) fiber.Interceptor {
 	}
 }
 
-// TracingInterceptor allows for tracing requests
+// TracingInterceptor allows for tracing requests. The interceptors, declared in the fiber config,
+// use the global tracer (see opentracing.SetGlobalTracer)
 type TracingInterceptor struct {
+	fiber.BaseFiberType
 	fiber.NoopAfterDispatchInterceptor
 	tracer opentracing.Tracer
 }
@@ -29,7 +31,11 @@ func (i *TracingInterceptor) operationName(ctx context.Context, req fiber.Reques
 
 // BeforeDispatch starts and returns a span with the given operation name
 func (i *TracingInterceptor) BeforeDispatch(ctx context.Context, req fiber.Request) context.Context {
-	_, ctx = opentracing.StartSpanFromContextWithTracer(ctx, i.tracer, i.operationName(ctx, req))
+	tracer := i.tracer
+	if tracer == nil {
+		tracer = opentracing.GlobalTracer()
+	}
+	_, ctx = opentracing.StartSpanFromContextWithTracer(ctx, tracer, i.operationName(ctx, req))
 	return ctx
 }
 
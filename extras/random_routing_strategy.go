@@ -2,6 +2,7 @@ package extras
 
 import (
 	"context"
+	"encoding/json"
 	"math/rand"
 	"strconv"
 
@@ -35,3 +36,8 @@ func (s *RandomRoutingStrategy) SelectRoute(
 	}
 	return route, fallbacks, labels, nil
 }
+
+// Properties returns no properties, the strategy is not configurable
+func (s *RandomRoutingStrategy) Properties() (json.RawMessage, error) {
+	return nil, nil
+}
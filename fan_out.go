@@ -35,19 +35,24 @@ func NewFanOut(id string) *BaseFanOut {
 // these request by its children components and then merges response channels into a
 // single response channel with zero or more responseQueue in it
 func (fanOut *BaseFanOut) Dispatch(ctx context.Context, req Request) ResponseQueue {
-	ctx = fanOut.beforeDispatch(ctx, req)
-	out := make(chan Response, len(fanOut.routes))
+	ctx, resp := fanOut.beforeDispatch(ctx, req)
+	if resp != nil {
+		return fanOut.shortCircuit(ctx, req, resp)
+	}
+	routes := fanOut.GetRoutes()
+	out := make(chan Response, len(routes))
 
-	queue := NewResponseQueue(out, len(fanOut.routes))
+	queue := NewResponseQueue(out, len(routes))
 	defer fanOut.afterDispatch(ctx, req, queue)
+	ctx, out = fanOut.withTimeout(ctx, req, out)
 
 	go func() {
 		defer fanOut.afterCompletion(ctx, req, queue)
 
 		var wg sync.WaitGroup
-		wg.Add(len(fanOut.routes))
+		wg.Add(len(routes))
 
-		for _, route := range fanOut.routes {
+		for _, route := range routes {
 			go func(route Component) {
 				// Make a copy of incoming request for each sub-name
 				copyReq, _ := req.Clone()
@@ -0,0 +1,400 @@
+package fiber
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gojek/fiber/errors"
+	"github.com/gojek/fiber/protocol"
+	"github.com/gojek/fiber/util"
+	"google.golang.org/grpc/codes"
+)
+
+// FaultInjectorKind represents the FaultInjector type
+const FaultInjectorKind ComponentKind = "FaultInjector"
+
+// The names of the faults, that can be triggered by the request header (see Faults.Header)
+const (
+	DelayFaultName    = "delay"
+	AbortFaultName    = "abort"
+	CorruptFaultName  = "corrupt"
+	TruncateFaultName = "truncate"
+)
+
+// Faults are the faults, that the FaultInjector injects into the requests to its route. Every fault
+// is injected into the configured percentage of the requests, independently of the others
+type Faults struct {
+	Delay    *DelayFault
+	Abort    *AbortFault
+	Corrupt  *CorruptFault
+	Truncate *TruncateFault
+	// Header is the name of the request header, that triggers the faults, listed in its values by their
+	// names (e.g. "delay,abort"), regardless of their percentages. The faults must still be configured
+	Header string
+	// HeaderOnly makes the faults be injected only into the requests, that trigger them with the Header
+	HeaderOnly bool
+}
+
+// DelayFault delays the requests: by the Fixed delay, or by the delay, distributed uniformly
+// between Min and Max, or normally with the Mean and the StdDev
+type DelayFault struct {
+	Percentage float64
+	Fixed      time.Duration
+	Min        time.Duration
+	Max        time.Duration
+	Mean       time.Duration
+	StdDev     time.Duration
+}
+
+// AbortFault responds to the requests with the error, without dispatching them to the route. The error
+// has the HTTPStatus (503 by default) or the GRPCStatus (codes.Unavailable by default), depending on
+// the protocol of the request
+type AbortFault struct {
+	Percentage float64
+	HTTPStatus int
+	GRPCStatus codes.Code
+}
+
+// CorruptFault replaces the Bytes (1 by default) of the successful responses' payloads, at random
+// positions, with random bytes
+type CorruptFault struct {
+	Percentage float64
+	Bytes      int
+}
+
+// TruncateFault truncates the payloads of the successful responses to the Length bytes
+type TruncateFault struct {
+	Percentage float64
+	Length     int
+}
+
+// Validate checks, that the faults are consistent
+func (f *Faults) Validate() error {
+	if f.HeaderOnly && f.Header == "" {
+		return fmt.Errorf("header is required, if faults are injected by the header only")
+	}
+	if f.Delay != nil {
+		if err := validatePercentage(DelayFaultName, f.Delay.Percentage); err != nil {
+			return err
+		}
+		if err := f.Delay.validate(); err != nil {
+			return err
+		}
+	}
+	if f.Abort != nil {
+		if err := validatePercentage(AbortFaultName, f.Abort.Percentage); err != nil {
+			return err
+		}
+		if f.Abort.HTTPStatus != 0 && (f.Abort.HTTPStatus < 400 || f.Abort.HTTPStatus > 599) {
+			return fmt.Errorf("abort: http status must be between 400 and 599, got %d", f.Abort.HTTPStatus)
+		}
+		if f.Abort.GRPCStatus > codes.Unauthenticated {
+			return fmt.Errorf("abort: unknown grpc status: %d", f.Abort.GRPCStatus)
+		}
+	}
+	if f.Corrupt != nil {
+		if err := validatePercentage(CorruptFaultName, f.Corrupt.Percentage); err != nil {
+			return err
+		}
+		if f.Corrupt.Bytes < 0 {
+			return fmt.Errorf("corrupt: bytes must not be negative")
+		}
+	}
+	if f.Truncate != nil {
+		if err := validatePercentage(TruncateFaultName, f.Truncate.Percentage); err != nil {
+			return err
+		}
+		if f.Truncate.Length < 0 {
+			return fmt.Errorf("truncate: length must not be negative")
+		}
+	}
+	return nil
+}
+
+func validatePercentage(fault string, percentage float64) error {
+	if percentage < 0 || percentage > 100 {
+		return fmt.Errorf("%s: percentage must be between 0 and 100, got %v", fault, percentage)
+	}
+	return nil
+}
+
+func (d *DelayFault) validate() error {
+	distributions := 0
+	if d.Fixed > 0 {
+		distributions++
+	}
+	if d.Min > 0 || d.Max > 0 {
+		distributions++
+		if d.Max < d.Min {
+			return fmt.Errorf("delay: max must not be less than min")
+		}
+	}
+	if d.Mean > 0 || d.StdDev > 0 {
+		distributions++
+	}
+	if distributions != 1 {
+		return fmt.Errorf("delay: exactly one of fixed, min and max, or mean and stddev is required")
+	}
+	if d.Fixed < 0 || d.Min < 0 || d.Mean < 0 || d.StdDev < 0 {
+		return fmt.Errorf("delay: durations must not be negative")
+	}
+	return nil
+}
+
+// delay returns the delay of a request
+func (d *DelayFault) delay() time.Duration {
+	switch {
+	case d.Fixed > 0:
+		return d.Fixed
+	case d.Max > 0:
+		return d.Min + time.Duration(rand.Int63n(int64(d.Max-d.Min)+1))
+	default:
+		if delay := d.Mean + time.Duration(rand.NormFloat64()*float64(d.StdDev)); delay > 0 {
+			return delay
+		}
+		return 0
+	}
+}
+
+// error returns the error, that the request is aborted with
+func (a *AbortFault) error(id string, p protocol.Protocol) *errors.FiberError {
+	code := a.HTTPStatus
+	if code == 0 {
+		code = http.StatusServiceUnavailable
+	}
+	if p == protocol.GRPC {
+		code = int(a.GRPCStatus)
+		if a.GRPCStatus == codes.OK {
+			code = int(codes.Unavailable)
+		}
+	}
+	return &errors.FiberError{
+		Code:    code,
+		Message: fmt.Sprintf("fiber: request aborted by the fault injector %s", id),
+	}
+}
+
+func (c *CorruptFault) corrupt(payload []byte) []byte {
+	corrupted := append([]byte(nil), payload...)
+	if len(corrupted) == 0 {
+		return corrupted
+	}
+	count := c.Bytes
+	if count == 0 {
+		count = 1
+	}
+	for i := 0; i < count; i++ {
+		idx := rand.Intn(len(corrupted))
+		// the byte is always changed
+		corrupted[idx] ^= byte(1 + rand.Intn(255))
+	}
+	return corrupted
+}
+
+func (t *TruncateFault) truncate(payload []byte) []byte {
+	if len(payload) <= t.Length {
+		return payload
+	}
+	return payload[:t.Length]
+}
+
+// injected returns the faults to inject into the request, triggered either by the header of the request,
+// or by their percentages
+func (f *Faults) injected(req Request) Faults {
+	triggered := make(map[string]bool)
+	if f.Header != "" {
+		for key, values := range req.Header() {
+			if !strings.EqualFold(key, f.Header) {
+				continue
+			}
+			for _, value := range values {
+				for _, name := range strings.Split(value, ",") {
+					triggered[strings.ToLower(strings.TrimSpace(name))] = true
+				}
+			}
+		}
+	}
+	inject := func(name string, percentage float64) bool {
+		if triggered[name] {
+			return true
+		}
+		return !f.HeaderOnly && percentage > 0 && rand.Float64()*100 < percentage
+	}
+
+	var faults Faults
+	if f.Delay != nil && inject(DelayFaultName, f.Delay.Percentage) {
+		faults.Delay = f.Delay
+	}
+	if f.Abort != nil && inject(AbortFaultName, f.Abort.Percentage) {
+		faults.Abort = f.Abort
+	}
+	if f.Corrupt != nil && inject(CorruptFaultName, f.Corrupt.Percentage) {
+		faults.Corrupt = f.Corrupt
+	}
+	if f.Truncate != nil && inject(TruncateFaultName, f.Truncate.Percentage) {
+		faults.Truncate = f.Truncate
+	}
+	return faults
+}
+
+// FaultInjector is a network component, that wraps a single route and injects the faults into
+// its requests and responses, e.g. to test the fallbacks of the routing graph without breaking
+// the real backends. The faults can be replaced and the injection can be disabled and enabled again,
+// while requests are dispatched. The requests are dispatched to the route as they are, when the
+// injection is disabled
+type FaultInjector struct {
+	BaseComponent
+	route Component
+
+	faults   atomic.Pointer[Faults]
+	disabled atomic.Bool
+}
+
+// NewFaultInjector is a factory for the FaultInjector type. It injects no faults, until they are set
+func NewFaultInjector(id string, route Component) *FaultInjector {
+	if id == "" {
+		id = "fault_injector_" + util.UID()
+	}
+	return &FaultInjector{
+		BaseComponent: BaseComponent{id: id, kind: FaultInjectorKind},
+		route:         route,
+	}
+}
+
+// Route returns the route of the FaultInjector
+func (f *FaultInjector) Route() Component {
+	return f.route
+}
+
+// SetFaults validates and sets the faults to inject. The requests in flight keep the faults,
+// that were injected into them
+func (f *FaultInjector) SetFaults(faults Faults) error {
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+	f.faults.Store(&faults)
+	return nil
+}
+
+// Faults returns the faults, that the FaultInjector injects
+func (f *FaultInjector) Faults() Faults {
+	if faults := f.faults.Load(); faults != nil {
+		return *faults
+	}
+	return Faults{}
+}
+
+// Enable enables the injection of the faults. The injection is enabled by default
+func (f *FaultInjector) Enable() {
+	f.disabled.Store(false)
+}
+
+// Disable disables the injection of the faults
+func (f *FaultInjector) Disable() {
+	f.disabled.Store(true)
+}
+
+// Enabled returns whether the injection of the faults is enabled
+func (f *FaultInjector) Enabled() bool {
+	return !f.disabled.Load()
+}
+
+// Dispatch injects the faults into the request and dispatches it to the route, unless it's aborted.
+// The responses of the route are sent into the output channel, with the faulty payloads
+func (f *FaultInjector) Dispatch(ctx context.Context, req Request) ResponseQueue {
+	ctx, resp := f.beforeDispatch(ctx, req)
+	if resp != nil {
+		return f.shortCircuit(ctx, req, resp)
+	}
+	out := make(chan Response, 1)
+
+	queue := NewResponseQueue(out, 1)
+	defer f.afterDispatch(ctx, req, queue)
+	ctx, out = f.withTimeout(ctx, req, out)
+
+	go func() {
+		defer f.afterCompletion(ctx, req, queue)
+		defer close(out)
+
+		var faults Faults
+		if current := f.faults.Load(); current != nil && f.Enabled() {
+			faults = current.injected(req)
+		}
+
+		if faults.Delay != nil {
+			timer := time.NewTimer(faults.Delay.delay())
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				out <- NewErrorResponse(errors.ErrRequestTimeout(req.Protocol()))
+				return
+			}
+		}
+		if faults.Abort != nil {
+			out <- NewErrorResponse(faults.Abort.error(f.ID(), req.Protocol()))
+			return
+		}
+
+		for resp := range f.route.Dispatch(ctx, req).Iter() {
+			if resp.IsSuccess() && (faults.Corrupt != nil || faults.Truncate != nil) {
+				payload := resp.Payload()
+				if faults.Truncate != nil {
+					payload = faults.Truncate.truncate(payload)
+				}
+				if faults.Corrupt != nil {
+					payload = faults.Corrupt.corrupt(payload)
+				}
+				resp = &faultyResponse{Response: resp, payload: payload}
+			}
+			out <- resp
+		}
+	}()
+
+	return queue
+}
+
+// AddInterceptor can be used to add the given interceptor to the FaultInjector and optionally,
+// to its route
+func (f *FaultInjector) AddInterceptor(recursive bool, interceptors ...Interceptor) {
+	if recursive {
+		f.route.AddInterceptor(recursive, interceptors...)
+	}
+	f.BaseComponent.AddInterceptor(recursive, interceptors...)
+}
+
+// Close releases the resources held by the route of the FaultInjector
+func (f *FaultInjector) Close() error {
+	return closeComponent(f.route)
+}
+
+// faultyResponse is the response of the route with the corrupted or truncated payload. The headers
+// (metadata) of the original response are not sent back to the client
+type faultyResponse struct {
+	Response
+	payload []byte
+}
+
+func (r *faultyResponse) Payload() []byte {
+	return r.payload
+}
+
+func (r *faultyResponse) WithBackendName(backendName string) Response {
+	r.Response.WithBackendName(backendName)
+	return r
+}
+
+func (r *faultyResponse) WithLabel(key string, values ...string) Response {
+	r.Response.WithLabel(key, values...)
+	return r
+}
+
+func (r *faultyResponse) WithLabels(labels Labels) Response {
+	r.Response.WithLabels(labels)
+	return r
+}
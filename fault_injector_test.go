@@ -0,0 +1,185 @@
+package fiber_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/fibertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestFaultInjector_Abort(t *testing.T) {
+	route := fibertest.NewComponent("route", fibertest.OK("hello"))
+	injector := fiber.NewFaultInjector("injector", route)
+	require.NoError(t, injector.SetFaults(fiber.Faults{Abort: &fiber.AbortFault{Percentage: 100}}))
+
+	resp := fibertest.Dispatch(t, injector, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+	assert.Contains(t, string(resp.Payload()), "request aborted by the fault injector injector")
+
+	resp = fibertest.Dispatch(t, injector, fibertest.NewGRPCRequest("/test.Service/Echo", nil, nil))
+	require.NotNil(t, resp)
+	assert.Equal(t, int(codes.Unavailable), resp.StatusCode())
+
+	require.NoError(t, injector.SetFaults(fiber.Faults{Abort: &fiber.AbortFault{
+		Percentage: 100,
+		HTTPStatus: http.StatusTooManyRequests,
+		GRPCStatus: codes.ResourceExhausted,
+	}}))
+	resp = fibertest.Dispatch(t, injector, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode())
+	resp = fibertest.Dispatch(t, injector, fibertest.NewGRPCRequest("/test.Service/Echo", nil, nil))
+	require.NotNil(t, resp)
+	assert.Equal(t, int(codes.ResourceExhausted), resp.StatusCode())
+
+	assert.Equal(t, 0, route.Calls())
+}
+
+func TestFaultInjector_Delay(t *testing.T) {
+	route := fibertest.NewComponent("route", fibertest.OK("hello"))
+	injector := fiber.NewFaultInjector("injector", route)
+	require.NoError(t, injector.SetFaults(fiber.Faults{Delay: &fiber.DelayFault{
+		Percentage: 100,
+		Min:        50 * time.Millisecond,
+		Max:        60 * time.Millisecond,
+	}}))
+
+	start := time.Now()
+	resp := fibertest.Dispatch(t, injector, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	fibertest.AssertResponse(t, resp, http.StatusOK, "hello")
+
+	// the delay is cancelled with the request
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	resp = <-injector.Dispatch(ctx, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", "")).Iter()
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode())
+	assert.Equal(t, 1, route.Calls())
+}
+
+func TestFaultInjector_Payload(t *testing.T) {
+	route := fibertest.NewComponent("route", fibertest.OK("hello, world"))
+	router := fiber.NewLazyRouter("router")
+	injector := fiber.NewFaultInjector("injector", route)
+	router.SetRoutes(fibertest.Routes(injector))
+	router.SetStrategy(&orderedRoutingStrategy{})
+
+	require.NoError(t, injector.SetFaults(fiber.Faults{Truncate: &fiber.TruncateFault{Percentage: 100, Length: 5}}))
+	resp := fibertest.Dispatch(t, router, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "hello")
+	// the faulty responses are labelled by the routers
+	fibertest.AssertBackend(t, resp, "injector")
+
+	require.NoError(t, injector.SetFaults(fiber.Faults{Corrupt: &fiber.CorruptFault{Percentage: 100, Bytes: 3}}))
+	resp = fibertest.Dispatch(t, router, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	require.NotNil(t, resp)
+	assert.Len(t, resp.Payload(), len("hello, world"))
+	assert.NotEqual(t, "hello, world", string(resp.Payload()))
+}
+
+func TestFaultInjector_Header(t *testing.T) {
+	route := fibertest.NewComponent("route", fibertest.OK("hello"))
+	injector := fiber.NewFaultInjector("injector", route)
+	require.NoError(t, injector.SetFaults(fiber.Faults{
+		Abort:      &fiber.AbortFault{Percentage: 0},
+		Truncate:   &fiber.TruncateFault{Percentage: 100, Length: 1},
+		Header:     "X-Fiber-Fault",
+		HeaderOnly: true,
+	}))
+
+	// the faults are only injected into the requests, that trigger them
+	resp := fibertest.Dispatch(t, injector, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "hello")
+
+	req := fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", "")
+	req.Header()["X-Fiber-Fault"] = []string{"truncate"}
+	resp = fibertest.Dispatch(t, injector, req)
+	fibertest.AssertResponse(t, resp, http.StatusOK, "h")
+
+	// grpc metadata keys are lowercase
+	grpcReq := fibertest.NewGRPCRequest("/test.Service/Echo", nil, metadata.Pairs("x-fiber-fault", "Truncate, abort"))
+	resp = fibertest.Dispatch(t, injector, grpcReq)
+	require.NotNil(t, resp)
+	assert.Equal(t, int(codes.Unavailable), resp.StatusCode())
+}
+
+func TestFaultInjector_Disable(t *testing.T) {
+	route := fibertest.NewComponent("route", fibertest.OK("hello"))
+	injector := fiber.NewFaultInjector("injector", route)
+	require.NoError(t, injector.SetFaults(fiber.Faults{Abort: &fiber.AbortFault{Percentage: 100}}))
+	recorder := fibertest.NewRecorder()
+	injector.AddInterceptor(true, recorder)
+
+	injector.Disable()
+	assert.False(t, injector.Enabled())
+	resp := fibertest.Dispatch(t, injector, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "hello")
+
+	injector.Enable()
+	resp = fibertest.Dispatch(t, injector, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode())
+
+	recorder.Wait()
+	fibertest.AssertCalls(t, recorder, "injector", 2)
+	fibertest.AssertCalls(t, recorder, "route", 1)
+}
+
+func TestFaults_Validate(t *testing.T) {
+	tests := map[string]struct {
+		faults   fiber.Faults
+		expected string
+	}{
+		"valid": {
+			faults: fiber.Faults{
+				Delay:    &fiber.DelayFault{Percentage: 10, Mean: time.Second, StdDev: time.Millisecond},
+				Abort:    &fiber.AbortFault{Percentage: 5, HTTPStatus: http.StatusBadGateway},
+				Corrupt:  &fiber.CorruptFault{Percentage: 1},
+				Truncate: &fiber.TruncateFault{Percentage: 1},
+			},
+		},
+		"header only without header": {
+			faults:   fiber.Faults{HeaderOnly: true},
+			expected: "header is required, if faults are injected by the header only",
+		},
+		"invalid percentage": {
+			faults:   fiber.Faults{Abort: &fiber.AbortFault{Percentage: 101}},
+			expected: "abort: percentage must be between 0 and 100, got 101",
+		},
+		"successful abort status": {
+			faults:   fiber.Faults{Abort: &fiber.AbortFault{Percentage: 1, HTTPStatus: http.StatusOK}},
+			expected: "abort: http status must be between 400 and 599, got 200",
+		},
+		"no delay": {
+			faults:   fiber.Faults{Delay: &fiber.DelayFault{Percentage: 1}},
+			expected: "delay: exactly one of fixed, min and max, or mean and stddev is required",
+		},
+		"several delays": {
+			faults:   fiber.Faults{Delay: &fiber.DelayFault{Percentage: 1, Fixed: time.Second, Max: time.Second}},
+			expected: "delay: exactly one of fixed, min and max, or mean and stddev is required",
+		},
+		"min greater than max": {
+			faults:   fiber.Faults{Delay: &fiber.DelayFault{Percentage: 1, Min: time.Second, Max: time.Millisecond}},
+			expected: "delay: max must not be less than min",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.faults.Validate()
+			if tt.expected == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expected)
+			}
+		})
+	}
+}
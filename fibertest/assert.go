@@ -0,0 +1,106 @@
+package fibertest
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gojek/fiber"
+	fibergrpc "github.com/gojek/fiber/grpc"
+	fiberhttp "github.com/gojek/fiber/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// DispatchTimeout is the time, that Dispatch waits for the response for
+var DispatchTimeout = 5 * time.Second
+
+// TestingT is the subset of testing.TB, used by the assertions
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// NewHTTPRequest creates the http request. It panics, if the method or the URL are invalid
+func NewHTTPRequest(method string, url string, body string) *fiberhttp.Request {
+	httpReq, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	if err != nil {
+		panic(err)
+	}
+	req, err := fiberhttp.NewHTTPRequest(httpReq)
+	if err != nil {
+		panic(err)
+	}
+	return req
+}
+
+// NewGRPCRequest creates the grpc request of the service method, in the format "/{service}/{method}",
+// for the transparent grpc.Dispatcher(s)
+func NewGRPCRequest(fullMethod string, message []byte, md metadata.MD) *fibergrpc.Request {
+	if md == nil {
+		md = metadata.MD{}
+	}
+	req := fibergrpc.NewRequest(md, message, nil)
+	req.FullMethod = fullMethod
+	return req
+}
+
+// Dispatch dispatches the request by the component and returns its first response. It reports
+// the error and returns nil, if there's no response within DispatchTimeout
+func Dispatch(t TestingT, component fiber.Component, req fiber.Request) fiber.Response {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), DispatchTimeout)
+	defer cancel()
+
+	select {
+	case resp, ok := <-component.Dispatch(ctx, req).Iter():
+		if !ok {
+			t.Errorf("component %s returned no response", component.ID())
+			return nil
+		}
+		return resp
+	case <-ctx.Done():
+		t.Errorf("component %s didn't respond within %s", component.ID(), DispatchTimeout)
+		return nil
+	}
+}
+
+// AssertCalls asserts, that the component with the ID was called the expected number of times,
+// as recorded by the recorder
+func AssertCalls(t TestingT, recorder *Recorder, componentID string, expected int) bool {
+	t.Helper()
+	if calls := recorder.Calls(componentID); calls != expected {
+		t.Errorf("component %s was called %d times, expected %d", componentID, calls, expected)
+		return false
+	}
+	return true
+}
+
+// AssertBackend asserts, that the response came from the backend (route) with the ID
+func AssertBackend(t TestingT, resp fiber.Response, backend string) bool {
+	t.Helper()
+	if resp == nil {
+		t.Errorf("no response, expected the response from %s", backend)
+		return false
+	}
+	if resp.BackendName() != backend {
+		t.Errorf("response came from %q, expected %q", resp.BackendName(), backend)
+		return false
+	}
+	return true
+}
+
+// AssertResponse asserts the status code and the payload of the response
+func AssertResponse(t TestingT, resp fiber.Response, statusCode int, payload string) bool {
+	t.Helper()
+	if resp == nil {
+		t.Errorf("no response, expected the response with status %d", statusCode)
+		return false
+	}
+	if resp.StatusCode() != statusCode || string(resp.Payload()) != payload {
+		t.Errorf("response with status %d and payload %q, expected status %d and payload %q",
+			resp.StatusCode(), resp.Payload(), statusCode, payload)
+		return false
+	}
+	return true
+}
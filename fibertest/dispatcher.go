@@ -0,0 +1,183 @@
+// Package fibertest provides the fakes and helpers to unit-test the fiber components, routing strategies,
+// fan-ins and interceptors against the real routers: scripted Dispatcher(s) and Component(s), the Recorder
+// interceptor with the assertions of the dispatches, and the in-memory GRPCServer
+package fibertest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/errors"
+	fibergrpc "github.com/gojek/fiber/grpc"
+	fiberhttp "github.com/gojek/fiber/http"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Reply is the scripted reply of the fake Dispatcher to a request
+type Reply struct {
+	// Response creates the response to the request. A new response is created for every request,
+	// since the routers label the responses of their routes
+	Response func() fiber.Response
+	// Latency is the time to wait for, before replying. If the context of the request is done first,
+	// the timeout error is returned
+	Latency time.Duration
+	// Panic, if not nil, is the value, that the reply panics with, instead of replying
+	Panic interface{}
+}
+
+// After returns the copy of the reply, that is delayed by the latency
+func (r Reply) After(latency time.Duration) Reply {
+	r.Latency = latency
+	return r
+}
+
+// OK replies with the successful http response with the body
+func OK(body string) Reply {
+	return Status(http.StatusOK, body)
+}
+
+// Status replies with the http response with the status code and the body
+func Status(code int, body string) Reply {
+	return Reply{Response: func() fiber.Response {
+		return HTTPResponse(code, body, nil)
+	}}
+}
+
+// Error replies with the error response, e.g. errors.ErrRequestFailed
+func Error(err error) Reply {
+	return Reply{Response: func() fiber.Response {
+		return fiber.NewErrorResponse(err)
+	}}
+}
+
+// Panic makes the reply panic with the value. The Dispatcher recovers the panic and responds with
+// the errors.ErrRequestFailed, as fiber.Caller(s) don't recover the panics of their dispatchers
+func Panic(value interface{}) Reply {
+	return Reply{Panic: value}
+}
+
+// GRPCOK replies with the successful grpc response with the message
+func GRPCOK(message []byte) Reply {
+	return Reply{Response: func() fiber.Response {
+		return &fibergrpc.Response{
+			Metadata: metadata.MD{},
+			Message:  message,
+			Status:   *status.New(codes.OK, "Success"),
+		}
+	}}
+}
+
+// HTTPResponse creates the http response, the same way as the http.Dispatcher does: the responses with
+// an unsuccessful status code are the error responses
+func HTTPResponse(code int, body string, header http.Header) fiber.Response {
+	return fiberhttp.NewHTTPResponse(&http.Response{
+		StatusCode: code,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	})
+}
+
+// Dispatcher is the fake fiber.ContextDispatcher, that replies to the requests as scripted: with the
+// replies in their order, repeating the last one, once all of them are used. A Dispatcher without
+// replies responds with the empty successful http response
+type Dispatcher struct {
+	mu       sync.Mutex
+	replies  []Reply
+	requests []fiber.Request
+}
+
+// NewDispatcher creates the Dispatcher with the replies
+func NewDispatcher(replies ...Reply) *Dispatcher {
+	return &Dispatcher{replies: replies}
+}
+
+// Do replies to the request
+func (d *Dispatcher) Do(req fiber.Request) fiber.Response {
+	return d.DoContext(context.Background(), req)
+}
+
+// DoContext replies to the request with the next scripted reply. The panicking replies fail only
+// their requests
+func (d *Dispatcher) DoContext(ctx context.Context, req fiber.Request) (resp fiber.Response) {
+	d.mu.Lock()
+	reply := OK("")
+	if len(d.replies) > 0 {
+		reply = d.replies[len(d.replies)-1]
+		if len(d.requests) < len(d.replies) {
+			reply = d.replies[len(d.requests)]
+		}
+	}
+	d.requests = append(d.requests, req)
+	d.mu.Unlock()
+
+	if reply.Latency > 0 {
+		timer := time.NewTimer(reply.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return fiber.NewErrorResponse(errors.ErrRequestTimeout(req.Protocol()))
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			resp = fiber.NewErrorResponse(errors.ErrRequestFailed(req.Protocol(), fmt.Errorf("panic: %v", r)))
+		}
+	}()
+	if reply.Panic != nil {
+		panic(reply.Panic)
+	}
+	if reply.Response == nil {
+		return OK("").Response()
+	}
+	return reply.Response()
+}
+
+// Calls returns the number of the requests, that the Dispatcher received
+func (d *Dispatcher) Calls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.requests)
+}
+
+// Requests returns the requests, that the Dispatcher received, in their order
+func (d *Dispatcher) Requests() []fiber.Request {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]fiber.Request(nil), d.requests...)
+}
+
+// Component is the fake route: the fiber.Caller with the scripted Dispatcher
+type Component struct {
+	*fiber.Caller
+	Dispatcher *Dispatcher
+}
+
+// NewComponent creates the Component, whose Dispatcher replies with the replies
+func NewComponent(id string, replies ...Reply) *Component {
+	dispatcher := NewDispatcher(replies...)
+	caller, _ := fiber.NewCaller(id, dispatcher)
+	return &Component{Caller: caller, Dispatcher: dispatcher}
+}
+
+// Calls returns the number of the requests, that the Component dispatched
+func (c *Component) Calls() int {
+	return c.Dispatcher.Calls()
+}
+
+// Routes returns the components by their IDs, e.g. to set them as the routes of the router
+func Routes(components ...fiber.Component) map[string]fiber.Component {
+	routes := make(map[string]fiber.Component, len(components))
+	for _, component := range components {
+		routes[component.ID()] = component
+	}
+	return routes
+}
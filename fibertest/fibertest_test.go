@@ -0,0 +1,173 @@
+package fibertest_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/extras"
+	"github.com/gojek/fiber/fibertest"
+	fibergrpc "github.com/gojek/fiber/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// orderedRoutingStrategy selects the routes in the order of their IDs
+type orderedRoutingStrategy struct {
+	fiber.BaseFiberType
+}
+
+func (s *orderedRoutingStrategy) SelectRoute(
+	_ context.Context,
+	_ fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	ids := make([]string, 0, len(routes))
+	for id := range routes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	ordered := make([]fiber.Component, 0, len(ids))
+	for _, id := range ids {
+		ordered = append(ordered, routes[id])
+	}
+	return ordered[0], ordered[1:], fiber.NewLabelsMap(), nil
+}
+
+// recordingT records the errors, reported by the assertions
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Helper() {}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestComponent_LazyRouter(t *testing.T) {
+	routeA := fibertest.NewComponent("route_a",
+		fibertest.Status(http.StatusInternalServerError, "failed"),
+		fibertest.Panic("boom"),
+		fibertest.OK("a"))
+	routeB := fibertest.NewComponent("route_b", fibertest.OK("b"))
+
+	router := fiber.NewLazyRouter("router")
+	router.SetRoutes(fibertest.Routes(routeA, routeB))
+	router.SetStrategy(&orderedRoutingStrategy{})
+	recorder := fibertest.NewRecorder()
+	router.AddInterceptor(true, recorder)
+
+	// the failed and the panicked replies of route_a fall back to route_b, the last reply is repeated
+	for _, expected := range []string{"route_b", "route_b", "route_a", "route_a"} {
+		resp := fibertest.Dispatch(t, router, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+		fibertest.AssertBackend(t, resp, expected)
+		fibertest.AssertResponse(t, resp, http.StatusOK, strings.TrimPrefix(expected, "route_"))
+	}
+	recorder.Wait()
+
+	assert.Equal(t, 4, routeA.Calls())
+	assert.Equal(t, 2, routeB.Calls())
+	fibertest.AssertCalls(t, recorder, "router", 4)
+	fibertest.AssertCalls(t, recorder, "route_a", 4)
+	fibertest.AssertCalls(t, recorder, "route_b", 2)
+
+	dispatches := recorder.Dispatches()
+	require.Len(t, dispatches, 10)
+	assert.Equal(t, "router", dispatches[0].ComponentPath)
+	assert.Equal(t, "router/route_a", dispatches[1].ComponentPath)
+	require.Len(t, dispatches[1].Responses, 1)
+	assert.Equal(t, http.StatusInternalServerError, dispatches[1].Responses[0].StatusCode())
+	require.Len(t, dispatches[4].Responses, 1)
+	assert.Contains(t, string(dispatches[4].Responses[0].Payload()), "panic: boom")
+}
+
+func TestComponent_Panic(t *testing.T) {
+	caller, err := fiber.NewCaller("caller", fibertest.NewDispatcher(
+		fibertest.Panic("boom"),
+		fibertest.Reply{Response: func() fiber.Response { panic("failed") }},
+		fibertest.OK("ok")))
+	require.NoError(t, err)
+
+	// the panics fail only their requests, the next ones are dispatched as usual
+	for _, expected := range []string{"panic: boom", "panic: failed"} {
+		resp := fibertest.Dispatch(t, caller, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+		require.NotNil(t, resp)
+		assert.False(t, resp.IsSuccess())
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode())
+		assert.Contains(t, string(resp.Payload()), expected)
+	}
+	resp := fibertest.Dispatch(t, caller, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "ok")
+}
+
+func TestComponent_Latency(t *testing.T) {
+	routeA := fibertest.NewComponent("route_a", fibertest.OK("a").After(200*time.Millisecond))
+	routeB := fibertest.NewComponent("route_b", fibertest.OK("b").After(10*time.Millisecond))
+
+	combiner := fiber.NewCombiner("combiner")
+	combiner.SetRoutes(fibertest.Routes(routeA, routeB))
+	combiner.WithFanIn(&extras.FastestResponseFanIn{})
+
+	resp := fibertest.Dispatch(t, combiner, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertBackend(t, resp, "route_b")
+
+	// the reply is cancelled with the request
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	resp = routeA.Dispatcher.DoContext(ctx, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	assert.False(t, resp.IsSuccess())
+	assert.Equal(t, http.StatusRequestTimeout, resp.StatusCode())
+}
+
+func TestGRPCServer(t *testing.T) {
+	server := fibertest.NewGRPCServer(fibertest.WithGRPCHandler(
+		func(_ context.Context, fullMethod string, message []byte) ([]byte, error) {
+			if fullMethod == "/test.Service/Fail" {
+				return nil, status.Error(codes.Unavailable, "unavailable")
+			}
+			return []byte(fullMethod + ": " + string(message)), nil
+		}))
+	server.Start()
+	defer server.Stop()
+
+	dispatcher, err := server.NewDispatcher(fibergrpc.DispatcherConfig{Transparent: true})
+	require.NoError(t, err)
+	defer dispatcher.Close()
+	caller, err := fiber.NewCaller("route_grpc", dispatcher)
+	require.NoError(t, err)
+
+	resp := fibertest.Dispatch(t, caller, fibertest.NewGRPCRequest("/test.Service/Echo", []byte("hello"), nil))
+	fibertest.AssertResponse(t, resp, int(codes.OK), "/test.Service/Echo: hello")
+
+	resp = fibertest.Dispatch(t, caller, fibertest.NewGRPCRequest("/test.Service/Fail", nil, nil))
+	require.NotNil(t, resp)
+	assert.Equal(t, int(codes.Unavailable), resp.StatusCode())
+}
+
+func TestAssertions(t *testing.T) {
+	recorder := fibertest.NewRecorder()
+	component := fibertest.NewComponent("route_a", fibertest.OK("a"))
+	component.AddInterceptor(false, recorder)
+	resp := fibertest.Dispatch(t, component, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	recorder.Wait()
+
+	mockT := &recordingT{}
+	assert.True(t, fibertest.AssertCalls(mockT, recorder, "route_a", 1))
+	assert.False(t, fibertest.AssertCalls(mockT, recorder, "route_a", 2))
+	assert.False(t, fibertest.AssertBackend(mockT, resp, "route_b"))
+	assert.False(t, fibertest.AssertResponse(mockT, resp, http.StatusOK, "b"))
+	assert.Equal(t, []string{
+		"component route_a was called 1 times, expected 2",
+		`response came from "", expected "route_b"`,
+		`response with status 200 and payload "a", expected status 200 and payload "b"`,
+	}, mockT.errors)
+}
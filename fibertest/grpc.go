@@ -0,0 +1,74 @@
+package fibertest
+
+import (
+	"bytes"
+	"context"
+	"net"
+
+	fibergrpc "github.com/gojek/fiber/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const bufferSize = 1024 * 1024
+
+// GRPCHandler handles the calls of any service method, with the raw messages, e.g. to fake the grpc
+// backends without their proto definitions
+type GRPCHandler func(ctx context.Context, fullMethod string, message []byte) ([]byte, error)
+
+// WithGRPCHandler makes the server handle the calls of the unregistered services with the handler
+func WithGRPCHandler(handler GRPCHandler) grpc.ServerOption {
+	return grpc.UnknownServiceHandler(func(_ interface{}, stream grpc.ServerStream) error {
+		fullMethod, _ := grpc.MethodFromServerStream(stream)
+		// grpc.Dispatcher(s) send the raw messages with the fiber codec, that writes them into io.Writer(s)
+		message := new(bytes.Buffer)
+		if err := stream.RecvMsg(message); err != nil {
+			return err
+		}
+		response, err := handler(stream.Context(), fullMethod, message.Bytes())
+		if err != nil {
+			return err
+		}
+		return stream.SendMsg(response)
+	})
+}
+
+// GRPCServer is the in-memory grpc server, that the grpc.Dispatcher(s) connect to over bufconn. The services
+// are registered with the embedded grpc.Server, before the server is started:
+//
+//	server := fibertest.NewGRPCServer()
+//	testproto.RegisterGreeterServer(server, &greeter{})
+//	server.Start()
+//	defer server.Stop()
+type GRPCServer struct {
+	*grpc.Server
+	listener *bufconn.Listener
+}
+
+// NewGRPCServer creates the GRPCServer with the options
+func NewGRPCServer(opts ...grpc.ServerOption) *GRPCServer {
+	return &GRPCServer{
+		Server:   grpc.NewServer(opts...),
+		listener: bufconn.Listen(bufferSize),
+	}
+}
+
+// Start starts serving the calls in the background
+func (s *GRPCServer) Start() {
+	go func() {
+		_ = s.Server.Serve(s.listener)
+	}()
+}
+
+// Dial connects to the server
+func (s *GRPCServer) Dial(ctx context.Context, _ string) (net.Conn, error) {
+	return s.listener.DialContext(ctx)
+}
+
+// NewDispatcher creates the grpc.Dispatcher, that connects to the server, with the config. Its Endpoint
+// and Dialer are set by the server
+func (s *GRPCServer) NewDispatcher(config fibergrpc.DispatcherConfig) (*fibergrpc.Dispatcher, error) {
+	config.Endpoint = "bufconn"
+	config.Dialer = s.Dial
+	return fibergrpc.NewDispatcher(config)
+}
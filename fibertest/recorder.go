@@ -0,0 +1,107 @@
+package fibertest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gojek/fiber"
+)
+
+// recorderKey is used to keep the dispatch in the request context, until its responses are recorded
+type recorderKey struct {
+	recorder *Recorder
+}
+
+// RecordedDispatch is the request, dispatched by the component, recorded by the Recorder
+type RecordedDispatch struct {
+	ComponentID string
+	// ComponentPath is the path of the component in the graph, see fiber.CtxComponentPathKey
+	ComponentPath string
+	Request       fiber.Request
+	// Responses are recorded, once the dispatch is completed, see Recorder.Wait
+	Responses []fiber.Response
+}
+
+// Recorder is the interceptor, that records the requests, dispatched by the components, and their responses.
+// It's usually added to the component under test recursively:
+//
+//	recorder := fibertest.NewRecorder()
+//	router.AddInterceptor(true, recorder)
+type Recorder struct {
+	fiber.NoopAfterDispatchInterceptor
+
+	mu         sync.Mutex
+	dispatches []*RecordedDispatch
+	pending    sync.WaitGroup
+}
+
+// NewRecorder creates the Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// BeforeDispatch records the dispatch of the request
+func (r *Recorder) BeforeDispatch(ctx context.Context, req fiber.Request) context.Context {
+	dispatch := &RecordedDispatch{Request: req}
+	dispatch.ComponentID, _ = ctx.Value(fiber.CtxComponentIDKey).(string)
+	dispatch.ComponentPath, _ = ctx.Value(fiber.CtxComponentPathKey).(string)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatches = append(r.dispatches, dispatch)
+	r.pending.Add(1)
+	return context.WithValue(ctx, recorderKey{recorder: r}, dispatch)
+}
+
+// AfterCompletion records the responses of the dispatch
+func (r *Recorder) AfterCompletion(ctx context.Context, _ fiber.Request, queue fiber.ResponseQueue) {
+	dispatch, ok := ctx.Value(recorderKey{recorder: r}).(*RecordedDispatch)
+	if !ok {
+		return
+	}
+	defer r.pending.Done()
+
+	var responses []fiber.Response
+	for resp := range queue.Iter() {
+		responses = append(responses, resp)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dispatch.Responses = responses
+}
+
+// Wait waits for the recorded dispatches to complete
+func (r *Recorder) Wait() {
+	r.pending.Wait()
+}
+
+// Dispatches returns the recorded dispatches, in the order they were started in
+func (r *Recorder) Dispatches() []RecordedDispatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dispatches := make([]RecordedDispatch, len(r.dispatches))
+	for i, dispatch := range r.dispatches {
+		dispatches[i] = *dispatch
+	}
+	return dispatches
+}
+
+// Calls returns the number of the requests, dispatched by the component with the ID
+func (r *Recorder) Calls(componentID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	calls := 0
+	for _, dispatch := range r.dispatches {
+		if dispatch.ComponentID == componentID {
+			calls++
+		}
+	}
+	return calls
+}
+
+// Reset forgets the recorded dispatches
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dispatches = nil
+}
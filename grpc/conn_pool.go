@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-coldbrew/grpcpool"
+	"google.golang.org/grpc"
+)
+
+// sharedConnPool is a reference counted pool of grpc connections. Dispatchers created
+// for the same endpoint (and dial options) re-use a single pool, which is closed only
+// after all of these dispatchers have been closed.
+type sharedConnPool struct {
+	grpcpool.ConnPool
+
+	key  string
+	refs int
+}
+
+var connPools = struct {
+	sync.Mutex
+	pools map[string]*sharedConnPool
+}{
+	pools: make(map[string]*sharedConnPool),
+}
+
+// acquireConnPool returns the pool registered with the given key, or dials a new one
+// to the endpoint if no such pool exists
+func acquireConnPool(key string, endpoint string, opts ...grpc.DialOption) (*sharedConnPool, error) {
+	connPools.Lock()
+	defer connPools.Unlock()
+
+	if pool, ok := connPools.pools[key]; ok {
+		pool.refs++
+		return pool, nil
+	}
+
+	conn, err := grpcpool.DialContext(context.Background(), endpoint, ConnPoolCount, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pool := &sharedConnPool{
+		ConnPool: conn,
+		key:      key,
+		refs:     1,
+	}
+	connPools.pools[key] = pool
+	return pool, nil
+}
+
+// release decrements the number of references to the pool and closes
+// its connections, when the pool is no longer used
+func (p *sharedConnPool) release() error {
+	connPools.Lock()
+	defer connPools.Unlock()
+
+	p.refs--
+	if p.refs > 0 {
+		return nil
+	}
+	delete(connPools.pools, p.key)
+	return p.ConnPool.Close()
+}
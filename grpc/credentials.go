@@ -0,0 +1,132 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gojek/fiber"
+	"github.com/gojek/fiber/util"
+	"google.golang.org/grpc/credentials"
+)
+
+// TLSConfig captures the configuration of a TLS connection to the grpc server
+type TLSConfig struct {
+	// CAFile is the path to the PEM encoded CA bundle, used to verify the server certificate.
+	// The system roots are used, if not set
+	CAFile string
+	// CertFile and KeyFile are the paths to the PEM encoded client certificate and key,
+	// used for mTLS
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the server name used to verify the server certificate
+	ServerName string
+}
+
+// TransportCredentials loads the certificates and creates the transport credentials
+// for the TLS connection
+func (c *TLSConfig) TransportCredentials() (credentials.TransportCredentials, error) {
+	tlsConfig, err := util.NewClientTLSConfig(c.CAFile, c.CertFile, c.KeyFile, c.ServerName)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func (c *TLSConfig) properties() map[string]interface{} {
+	properties := make(map[string]interface{})
+	for key, value := range map[string]string{
+		"ca_file":     c.CAFile,
+		"cert_file":   c.CertFile,
+		"key_file":    c.KeyFile,
+		"server_name": c.ServerName,
+	} {
+		if value != "" {
+			properties[key] = value
+		}
+	}
+	return properties
+}
+
+// key uniquely identifies the TLS configuration, so that connection pools can be shared
+// between dispatchers with the same configuration
+func (c *TLSConfig) key() string {
+	return strings.Join([]string{c.CAFile, c.CertFile, c.KeyFile, c.ServerName}, "|")
+}
+
+// tokenCredentials implements credentials.PerRPCCredentials and attaches the bearer token
+// provided by the token func to every call
+type tokenCredentials struct {
+	token func() (string, error)
+	// properties describe the credentials, as they are defined in the fiber config
+	properties map[string]interface{}
+}
+
+func (c *tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	token, err := c.token()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// Describe describes the credentials, including the token or the path to the token file
+func (c *tokenCredentials) Describe() fiber.Description {
+	return fiber.Description{Properties: c.properties}
+}
+
+// RequireTransportSecurity returns true, bearer tokens are not sent over insecure connections
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// NewStaticTokenCredentials creates per-RPC credentials, that attach the given bearer token to every call
+func NewStaticTokenCredentials(token string) credentials.PerRPCCredentials {
+	return &tokenCredentials{
+		token:      func() (string, error) { return token, nil },
+		properties: map[string]interface{}{"type": "bearer_token", "token": token},
+	}
+}
+
+// NewTokenFileCredentials creates per-RPC credentials, that attach the bearer token read
+// from the given file to every call. The token is reloaded, whenever the file is modified
+func NewTokenFileCredentials(path string) (credentials.PerRPCCredentials, error) {
+	tokenFile := &tokenFile{path: path}
+	if _, err := tokenFile.token(); err != nil {
+		return nil, err
+	}
+	return &tokenCredentials{
+		token:      tokenFile.token,
+		properties: map[string]interface{}{"type": "token_file", "token_file": path},
+	}, nil
+}
+
+type tokenFile struct {
+	sync.Mutex
+
+	path    string
+	modTime time.Time
+	value   string
+}
+
+func (f *tokenFile) token() (string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read token file: %w", err)
+	}
+	if !info.ModTime().Equal(f.modTime) {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read token file: %w", err)
+		}
+		f.value = strings.TrimSpace(string(data))
+		f.modTime = info.ModTime()
+	}
+	return f.value, nil
+}
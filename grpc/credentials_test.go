@@ -0,0 +1,244 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	testproto "github.com/gojek/fiber/internal/testdata/gen/testdata/proto"
+	testutils "github.com/gojek/fiber/internal/testutils/grpc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	tlsCert tls.Certificate
+}
+
+func newTestCertificate(t *testing.T, template *x509.Certificate, parent *testCertificate) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		tlsCert: tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+	}
+}
+
+func (c *testCertificate) writePEM(t *testing.T, dir string, name string) (certFile string, keyFile string) {
+	certFile = filepath.Join(dir, name+".crt")
+	require.NoError(t, os.WriteFile(certFile,
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0600))
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	keyFile = filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(keyFile,
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+// runTLSServer starts the test UPI server, that requires client certificates signed by the CA
+// and the bearer token in the "authorization" metadata
+func runTLSServer(t *testing.T, ca *testCertificate, server *testCertificate, token string) string {
+	certPool := x509.NewCertPool()
+	certPool.AddCert(ca.cert)
+
+	authorize := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if values := md.Get("authorization"); len(values) == 0 || values[0] != "Bearer "+token {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return handler(ctx, req)
+	}
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{server.tlsCert},
+			ClientCAs:    certPool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		})),
+		grpc.UnaryInterceptor(authorize),
+	)
+	testproto.RegisterUniversalPredictionServiceServer(srv, &testutils.GrpcTestServer{MockResponse: mockResponse})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = srv.Serve(listener)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestDispatcher_TLS(t *testing.T) {
+	dir := t.TempDir()
+	notBefore, notAfter := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	ca := newTestCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fiber-test-ca"},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	server := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "fiber-test-server"},
+		DNSNames:     []string{"fiber.test"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca)
+	client := newTestCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "fiber-test-client"},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+
+	caFile, _ := ca.writePEM(t, dir, "ca")
+	certFile, keyFile := client.writePEM(t, dir, "client")
+	tokenFile := filepath.Join(dir, "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0600))
+
+	endpoint := runTLSServer(t, ca, server, "file-token")
+
+	tokenFileCredentials, err := NewTokenFileCredentials(tokenFile)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		config       DispatcherConfig
+		expectedErr  string
+		expectedCode codes.Code
+	}{
+		{
+			name: "mTLS with token file",
+			config: DispatcherConfig{
+				TLS: &TLSConfig{
+					CAFile:     caFile,
+					CertFile:   certFile,
+					KeyFile:    keyFile,
+					ServerName: "fiber.test",
+				},
+				PerRPCCredentials: tokenFileCredentials,
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "invalid static token",
+			config: DispatcherConfig{
+				TLS: &TLSConfig{
+					CAFile:     caFile,
+					CertFile:   certFile,
+					KeyFile:    keyFile,
+					ServerName: "fiber.test",
+				},
+				PerRPCCredentials: NewStaticTokenCredentials("invalid-token"),
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "missing CA bundle",
+			config: DispatcherConfig{
+				TLS: &TLSConfig{
+					CAFile: filepath.Join(dir, "unknown.crt"),
+				},
+			},
+			expectedErr: "fiber: grpc dispatcher: invalid tls config: unable to read CA bundle: " +
+				"open " + filepath.Join(dir, "unknown.crt") + ": no such file or directory",
+		},
+		{
+			name: "missing client key",
+			config: DispatcherConfig{
+				TLS: &TLSConfig{
+					CAFile:   caFile,
+					CertFile: certFile,
+				},
+			},
+			expectedErr: "fiber: grpc dispatcher: invalid tls config: both client certificate and key are required",
+		},
+		{
+			name: "token without tls",
+			config: DispatcherConfig{
+				PerRPCCredentials: NewStaticTokenCredentials("token"),
+			},
+			expectedErr: "fiber: grpc dispatcher: per-RPC credentials require a tls connection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Endpoint = endpoint
+			tt.config.ServiceMethod = serviceMethod
+			tt.config.Timeout = 5 * time.Second
+
+			dispatcher, err := NewDispatcher(tt.config)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			defer dispatcher.Close()
+
+			response := dispatcher.Do(&Request{})
+			assert.EqualValues(t, tt.expectedCode, response.StatusCode())
+		})
+	}
+}
+
+func TestNewTokenFileCredentials(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+
+	_, err := NewTokenFileCredentials(tokenFile)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first"), 0600))
+	creds, err := NewTokenFileCredentials(tokenFile)
+	require.NoError(t, err)
+
+	md, err := creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer first"}, md)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second"), 0600))
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(tokenFile, modTime, modTime))
+
+	md, err = creds.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"authorization": "Bearer second"}, md)
+}
@@ -4,15 +4,23 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"net"
+	"path"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/go-coldbrew/grpcpool"
 	"github.com/gojek/fiber"
 	fiberError "github.com/gojek/fiber/errors"
 	"github.com/gojek/fiber/protocol"
+	"github.com/gojek/fiber/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/encoding"
 	"google.golang.org/grpc/metadata"
@@ -29,23 +37,85 @@ const (
 	ConnPoolCount = 10
 )
 
+// StreamType defines the kind of RPC that is performed by the Dispatcher
+type StreamType string
+
+const (
+	// Unary is the default StreamType, where a single response is expected for each request
+	Unary StreamType = ""
+	// ServerStreaming is used for RPCs, where the server streams zero or more responses
+	// back for a single request message
+	ServerStreaming StreamType = "server"
+	// BidiStreaming is used for bidirectional streaming RPCs. The request payload is sent as
+	// the only client message, after which responses are streamed back until the server ends the stream
+	BidiStreaming StreamType = "bidirectional"
+)
+
 type Dispatcher struct {
 	timeout time.Duration
+	// streaming is the type of RPC performed by the dispatcher
+	streaming StreamType
 	// serviceMethod is the service and method of server point in the format "{grpc_service_name}/{method_name}"
 	serviceMethod string
 	// endpoint is the host+port of the grpc server, eg "127.0.0.1:50050"
 	endpoint string
+	// transparent dispatchers use the service method of the incoming request, instead of serviceMethod
+	transparent bool
+	// allowedMethods and deniedMethods are the patterns of service methods, that the
+	// transparent dispatcher is allowed / not allowed to call
+	allowedMethods []string
+	deniedMethods  []string
+	// tls is the configuration of the TLS connection to the server, if any
+	tls *TLSConfig
+	// perRPCCredentials, if set, are attached to every call
+	perRPCCredentials credentials.PerRPCCredentials
+	// preserveErrors makes the dispatcher return unsuccessful responses with the original
+	// status (including its details), header and trailer of the call
+	preserveErrors bool
 	// conn is the grpc connection dialed upon creation of dispatcher
 	conn grpc.ClientConnInterface
+	// closeOnce makes sure, that the dispatcher releases its reference to the shared pool only once
+	closeOnce sync.Once
+	closeErr  error
 }
 
 type DispatcherConfig struct {
 	ServiceMethod string
 	Endpoint      string
 	Timeout       time.Duration
+	Streaming     StreamType
+	// Transparent makes the dispatcher call the service method of the incoming request
+	// (see Request.FullMethod), so that a single dispatcher can front the entire gRPC service.
+	// ServiceMethod is not required for transparent dispatchers.
+	Transparent bool
+	// AllowedMethods is the list of service methods (or path.Match patterns, such as
+	// "/mypackage.Greeter/*"), that the transparent dispatcher is allowed to call.
+	// All methods are allowed, if empty
+	AllowedMethods []string
+	// DeniedMethods is the list of service methods (or path.Match patterns), that the transparent
+	// dispatcher is not allowed to call. It takes precedence over AllowedMethods
+	DeniedMethods []string
+	// TLS, if set, makes the dispatcher connect to the server over TLS, instead of plaintext
+	TLS *TLSConfig
+	// PerRPCCredentials, if set, are attached to every call, e.g. NewStaticTokenCredentials
+	PerRPCCredentials credentials.PerRPCCredentials
+	// PreserveErrors makes the dispatcher keep unsuccessful upstream responses as grpc Response(s),
+	// with the original status (including its details), metadata and trailer, instead of replacing
+	// them with fiber error responses. Such responses are still not successful, so routers can fall
+	// back to other routes
+	PreserveErrors bool
+	// Dialer, if set, connects to the Endpoint instead of the network dialer, e.g. to the in-memory
+	// server in tests (see fibertest.GRPCServer). Dispatchers with a Dialer don't share their connections
+	Dialer func(ctx context.Context, address string) (net.Conn, error)
 }
 
 func (d *Dispatcher) Do(request fiber.Request) fiber.Response {
+	return d.DoContext(context.Background(), request)
+}
+
+// DoContext makes the call within the given context, so the call is cancelled once the context
+// is done. The context's deadline, if it's earlier than the dispatcher's timeout, is propagated to the server
+func (d *Dispatcher) DoContext(ctx context.Context, request fiber.Request) fiber.Response {
 	grpcRequest, ok := request.(*Request)
 	if !ok {
 		return fiber.NewErrorResponse(
@@ -55,62 +125,350 @@ func (d *Dispatcher) Do(request fiber.Request) fiber.Response {
 			})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	serviceMethod, fiberErr := d.resolveServiceMethod(grpcRequest)
+	if fiberErr != nil {
+		return fiber.NewErrorResponse(fiberErr)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
 	defer cancel()
-	ctx = metadata.NewOutgoingContext(ctx, grpcRequest.Metadata)
+	ctx = metadata.NewOutgoingContext(ctx, outgoingMetadata(ctx, grpcRequest.Metadata))
 
 	response := new(bytes.Buffer)
-	var responseHeader metadata.MD
+	var responseHeader, responseTrailer metadata.MD
 
 	// Dispatcher will send both request and payload as bytes, with the use of codec
 	// to prevent marshaling. The codec content type will be sent with request and
 	// the server will attempt to unmarshal with the codec.
 	err := d.conn.Invoke(
 		ctx,
-		d.serviceMethod,
+		serviceMethod,
 		grpcRequest.Payload(),
 		response,
-		grpc.Header(&responseHeader),
-		grpc.CallContentSubtype(codecName),
-		grpc.WaitForReady(true),
+		d.callOptions(grpc.Header(&responseHeader), grpc.Trailer(&responseTrailer))...,
 	)
 	if err != nil {
-		// if ok is false, unknown codes.Unknown and Status msg is returned in Status
-		responseStatus, _ := status.FromError(err)
-		return fiber.NewErrorResponse(
-			fiberError.FiberError{
-				Code:    int(responseStatus.Code()),
-				Message: responseStatus.String(),
-			})
+		return d.errorResponse(err, responseHeader, responseTrailer)
 	}
 
 	return &Response{
 		Metadata: responseHeader,
+		Trailer:  responseTrailer,
 		Message:  response.Bytes(),
 		Status:   *status.New(codes.OK, "Success"),
 	}
 }
 
+// outgoingMetadata returns the metadata of the request with the trace context of ctx (e.g. the W3C
+// traceparent), injected with the OpenTelemetry propagator from ctx (see fiber.CtxTextMapPropagatorKey)
+// or the global one (see otel.SetTextMapPropagator), so the server's spans join the trace.
+// The metadata of the request is left intact
+func outgoingMetadata(ctx context.Context, md metadata.MD) metadata.MD {
+	propagator, ok := ctx.Value(fiber.CtxTextMapPropagatorKey).(propagation.TextMapPropagator)
+	if !ok {
+		propagator = otel.GetTextMapPropagator()
+	}
+	traceContext := propagation.MapCarrier{}
+	propagator.Inject(ctx, traceContext)
+	if len(traceContext) == 0 {
+		return md
+	}
+	md = md.Copy()
+	for key, value := range traceContext {
+		md.Set(key, value)
+	}
+	return md
+}
+
+// IsStreaming returns true if the dispatcher is configured to perform streaming RPCs
+func (d *Dispatcher) IsStreaming() bool {
+	return d.streaming != Unary
+}
+
+// DoStream opens a stream to the configured service method and sends every message received
+// from the server as a separate Response into the output channel. The output channel is closed
+// when the server ends the stream, the stream fails (in which case the last response is an error
+// response) or the context is done.
+func (d *Dispatcher) DoStream(ctx context.Context, request fiber.Request) <-chan fiber.Response {
+	out := make(chan fiber.Response)
+
+	go func() {
+		defer close(out)
+
+		send := func(resp fiber.Response) bool {
+			select {
+			case out <- resp:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		grpcRequest, ok := request.(*Request)
+		if !ok {
+			send(fiber.NewErrorResponse(
+				fiberError.FiberError{
+					Code:    int(codes.InvalidArgument),
+					Message: "fiber: grpc dispatcher: only grpc.Request type of requests are supported",
+				}))
+			return
+		}
+
+		serviceMethod, fiberErr := d.resolveServiceMethod(grpcRequest)
+		if fiberErr != nil {
+			send(fiber.NewErrorResponse(fiberErr))
+			return
+		}
+
+		streamCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+		streamCtx = metadata.NewOutgoingContext(streamCtx, outgoingMetadata(streamCtx, grpcRequest.Metadata))
+
+		stream, err := d.conn.NewStream(
+			streamCtx,
+			&grpc.StreamDesc{
+				ServerStreams: true,
+				ClientStreams: d.streaming == BidiStreaming,
+			},
+			serviceMethod,
+			d.callOptions()...,
+		)
+		if err != nil {
+			send(d.errorResponse(err, nil, nil))
+			return
+		}
+
+		// io.EOF from SendMsg means that the stream was terminated by the server,
+		// the actual status is then returned by RecvMsg
+		if err = stream.SendMsg(grpcRequest.Payload()); err != nil && err != io.EOF {
+			send(d.errorResponse(err, nil, nil))
+			return
+		}
+		if err = stream.CloseSend(); err != nil {
+			send(d.errorResponse(err, nil, nil))
+			return
+		}
+
+		for {
+			message := new(bytes.Buffer)
+			if err = stream.RecvMsg(message); err != nil {
+				if err != io.EOF {
+					responseHeader, _ := stream.Header()
+					send(d.errorResponse(err, responseHeader, stream.Trailer()))
+				}
+				return
+			}
+
+			// header is available once the first message is received
+			responseHeader, _ := stream.Header()
+			if !send(&Response{
+				Metadata: responseHeader.Copy(),
+				Message:  message.Bytes(),
+				Status:   *status.New(codes.OK, "Success"),
+			}) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func (d *Dispatcher) callOptions(opts ...grpc.CallOption) []grpc.CallOption {
+	opts = append(opts,
+		grpc.CallContentSubtype(codecName),
+		grpc.WaitForReady(true),
+	)
+	if d.perRPCCredentials != nil {
+		opts = append(opts, grpc.PerRPCCredentials(d.perRPCCredentials))
+	}
+	return opts
+}
+
+// Close releases the grpc connections used by the dispatcher. It should be called
+// after the dispatcher is no longer in use, the subsequent calls do nothing.
+func (d *Dispatcher) Close() error {
+	d.closeOnce.Do(func() {
+		if pool, ok := d.conn.(*sharedConnPool); ok {
+			d.closeErr = pool.release()
+		}
+	})
+	return d.closeErr
+}
+
+// resolveServiceMethod returns the service method to be called for the given request
+func (d *Dispatcher) resolveServiceMethod(request *Request) (string, *fiberError.FiberError) {
+	if !d.transparent {
+		return d.serviceMethod, nil
+	}
+
+	serviceMethod := withLeadingSlash(request.FullMethod)
+	if request.FullMethod == "" {
+		return "", fiberError.ErrInvalidInput(
+			protocol.GRPC,
+			errors.New("grpc dispatcher: service method of the request is not set"))
+	}
+	if !d.isMethodAllowed(serviceMethod) {
+		return "", &fiberError.FiberError{
+			Code:    int(codes.PermissionDenied),
+			Message: fmt.Sprintf("fiber: grpc dispatcher: service method %s is not allowed", serviceMethod),
+		}
+	}
+	return serviceMethod, nil
+}
+
+func (d *Dispatcher) isMethodAllowed(serviceMethod string) bool {
+	if matchesAny(serviceMethod, d.deniedMethods) {
+		return false
+	}
+	return len(d.allowedMethods) == 0 || matchesAny(serviceMethod, d.allowedMethods)
+}
+
+func matchesAny(serviceMethod string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, serviceMethod); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// withLeadingSlash formats the service method (or pattern) as "/{grpc_service_name}/{method_name}"
+func withLeadingSlash(serviceMethod string) string {
+	if serviceMethod == "" || strings.HasPrefix(serviceMethod, "/") {
+		return serviceMethod
+	}
+	return "/" + serviceMethod
+}
+
+func withLeadingSlashes(serviceMethods []string) []string {
+	if len(serviceMethods) == 0 {
+		return nil
+	}
+	formatted := make([]string, len(serviceMethods))
+	for i, serviceMethod := range serviceMethods {
+		formatted[i] = withLeadingSlash(serviceMethod)
+	}
+	return formatted
+}
+
+// errorResponse creates the response for the failed call. Depending on the dispatcher's configuration,
+// it's either a fiber error response or the grpc Response, that preserves the original status,
+// header and trailer of the call
+func (d *Dispatcher) errorResponse(err error, header metadata.MD, trailer metadata.MD) fiber.Response {
+	// if ok is false, unknown codes.Unknown and Status msg is returned in Status
+	responseStatus, _ := status.FromError(err)
+	if d.preserveErrors {
+		if header == nil {
+			header = metadata.MD{}
+		}
+		return &Response{
+			Metadata: header,
+			Trailer:  trailer,
+			Status:   *responseStatus,
+		}
+	}
+	return fiber.NewErrorResponse(
+		fiberError.FiberError{
+			Code:    int(responseStatus.Code()),
+			Message: responseStatus.String(),
+		})
+}
+
+// Describe describes the properties of the dispatcher. The per-RPC credentials are only described,
+// if they were created with NewStaticTokenCredentials or NewTokenFileCredentials
+func (d *Dispatcher) Describe() fiber.Description {
+	properties := map[string]interface{}{
+		"protocol": string(protocol.GRPC),
+		"endpoint": d.endpoint,
+		"timeout":  d.timeout.String(),
+	}
+	if d.serviceMethod != "" {
+		properties["service_method"] = strings.TrimPrefix(d.serviceMethod, "/")
+	}
+	if d.streaming != Unary {
+		properties["streaming"] = string(d.streaming)
+	}
+	if d.transparent {
+		properties["transparent"] = true
+	}
+	if len(d.allowedMethods) > 0 {
+		properties["allowed_methods"] = d.allowedMethods
+	}
+	if len(d.deniedMethods) > 0 {
+		properties["denied_methods"] = d.deniedMethods
+	}
+	if d.tls != nil {
+		properties["tls"] = d.tls.properties()
+	}
+	if describer, ok := d.perRPCCredentials.(fiber.Describer); ok {
+		properties["credentials"] = describer.Describe().Properties
+	}
+	if d.preserveErrors {
+		properties["preserve_errors"] = true
+	}
+	return fiber.Description{Properties: properties}
+}
+
+// Validate checks the config the same way, as NewDispatcher does, without connecting to the endpoint
+func (config DispatcherConfig) Validate() error {
+	if config.Endpoint == "" || (config.ServiceMethod == "" && !config.Transparent) {
+		return errors.New("grpc dispatcher: missing config (endpoint/serviceMethod)")
+	}
+	switch config.Streaming {
+	case Unary, ServerStreaming, BidiStreaming:
+	default:
+		return fmt.Errorf("grpc dispatcher: unknown streaming type: %s", config.Streaming)
+	}
+	for _, pattern := range append(withLeadingSlashes(config.AllowedMethods), withLeadingSlashes(config.DeniedMethods)...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("grpc dispatcher: invalid service method pattern: %s", pattern)
+		}
+	}
+	if config.TLS != nil {
+		if _, err := config.TLS.TransportCredentials(); err != nil {
+			return fmt.Errorf("grpc dispatcher: invalid tls config: %s", err.Error())
+		}
+	}
+	if config.PerRPCCredentials != nil && config.PerRPCCredentials.RequireTransportSecurity() && config.TLS == nil {
+		return errors.New("grpc dispatcher: per-RPC credentials require a tls connection")
+	}
+	return nil
+}
+
 // NewDispatcher is the constructor to create a dispatcher. It will create the clientconn and set defaults.
-// Endpoint, serviceMethod and response proto are required minimally to work.
+// Endpoint, serviceMethod and response proto are required minimally to work. Transparent dispatchers
+// don't require the serviceMethod. Dispatchers to the same endpoint share a single pool of connections.
 func NewDispatcher(config DispatcherConfig) (*Dispatcher, error) {
 	configuredTimeout := TimeoutDefault
 	if config.Timeout != 0 {
 		configuredTimeout = config.Timeout
 	}
 
-	if config.Endpoint == "" || config.ServiceMethod == "" {
-		return nil, fiberError.ErrInvalidInput(
-			protocol.GRPC,
-			errors.New("grpc dispatcher: missing config (endpoint/serviceMethod)"))
+	if err := config.Validate(); err != nil {
+		return nil, fiberError.ErrInvalidInput(protocol.GRPC, err)
 	}
-	var serviceMethodStringBuilder strings.Builder
-	if !strings.HasPrefix(config.ServiceMethod, "/") {
-		serviceMethodStringBuilder.WriteString("/")
+
+	transportCredentials := insecure.NewCredentials()
+	poolKey := config.Endpoint
+	if config.TLS != nil {
+		tlsCredentials, err := config.TLS.TransportCredentials()
+		if err != nil {
+			return nil, fiberError.ErrInvalidInput(
+				protocol.GRPC,
+				fmt.Errorf("grpc dispatcher: invalid tls config: %s", err.Error()))
+		}
+		transportCredentials = tlsCredentials
+		poolKey = poolKey + "|" + config.TLS.key()
+	}
+
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(transportCredentials)}
+	if config.Dialer != nil {
+		dialOptions = append(dialOptions, grpc.WithContextDialer(config.Dialer))
+		poolKey = poolKey + "|dialer-" + util.UID()
 	}
-	serviceMethodStringBuilder.WriteString(config.ServiceMethod)
 
-	conn, err := grpcpool.DialContext(context.Background(), config.Endpoint, ConnPoolCount, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := acquireConnPool(poolKey, config.Endpoint, dialOptions...)
 	if err != nil {
 		// if ok is false, unknown codes.Unknown and Status msg is returned in Status
 		responseStatus, _ := status.FromError(err)
@@ -120,10 +478,17 @@ func NewDispatcher(config DispatcherConfig) (*Dispatcher, error) {
 	}
 
 	dispatcher := &Dispatcher{
-		timeout:       configuredTimeout,
-		serviceMethod: serviceMethodStringBuilder.String(),
-		endpoint:      config.Endpoint,
-		conn:          conn,
+		timeout:           configuredTimeout,
+		streaming:         config.Streaming,
+		serviceMethod:     withLeadingSlash(config.ServiceMethod),
+		endpoint:          config.Endpoint,
+		transparent:       config.Transparent,
+		allowedMethods:    withLeadingSlashes(config.AllowedMethods),
+		deniedMethods:     withLeadingSlashes(config.DeniedMethods),
+		conn:              conn,
+		tls:               config.TLS,
+		perRPCCredentials: config.PerRPCCredentials,
+		preserveErrors:    config.PreserveErrors,
 	}
 	return dispatcher, nil
 }
@@ -1,6 +1,7 @@
 package grpc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -17,6 +18,9 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
@@ -24,8 +28,10 @@ import (
 )
 
 const (
-	port          = 50055
-	serviceMethod = "testproto.UniversalPredictionService/PredictValues"
+	port             = 50055
+	streamingPort    = 50065
+	echoMetadataPort = 50075
+	serviceMethod    = "testproto.UniversalPredictionService/PredictValues"
 )
 
 var mockResponse *testproto.PredictValuesResponse
@@ -75,6 +81,19 @@ func TestMain(m *testing.M) {
 			MockResponse: mockResponse,
 		},
 	)
+	testutils.RunTestStreamingServer(
+		testutils.StreamingTestServer{
+			Port:  streamingPort,
+			Count: 3,
+		},
+	)
+	testutils.RunTestStreamingServer(
+		testutils.StreamingTestServer{
+			Port:         echoMetadataPort,
+			Count:        1,
+			EchoMetadata: true,
+		},
+	)
 	os.Exit(m.Run())
 }
 
@@ -105,6 +124,18 @@ func TestNewDispatcher(t *testing.T) {
 				protocol.GRPC,
 				errors.New("grpc dispatcher: missing config (endpoint/serviceMethod)")),
 		},
+		{
+			name: "unknown streaming type",
+			dispatcherConfig: DispatcherConfig{
+				ServiceMethod: serviceMethod,
+				Endpoint:      fmt.Sprintf(":%d", port),
+				Streaming:     "client",
+			},
+			expected: nil,
+			expectedErr: fiberError.ErrInvalidInput(
+				protocol.GRPC,
+				errors.New("grpc dispatcher: unknown streaming type: client")),
+		},
 		{
 			name: "ok response",
 			dispatcherConfig: DispatcherConfig{
@@ -131,7 +162,7 @@ func TestNewDispatcher(t *testing.T) {
 				require.NoError(t, err)
 				// responseProto and conn are ignored as they have pointer which value will not be identical
 				diff := cmp.Diff(tt.expected, got,
-					cmpopts.IgnoreFields(Dispatcher{}, "conn"),
+					cmpopts.IgnoreFields(Dispatcher{}, "conn", "closeOnce"),
 					cmp.AllowUnexported(Dispatcher{}),
 				)
 				require.Empty(t, diff)
@@ -200,3 +231,285 @@ func TestDispatcher_Do(t *testing.T) {
 		})
 	}
 }
+
+func TestDispatcher_DoStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		streaming StreamType
+		input     fiber.Request
+		expected  []fiber.Response
+	}{
+		{
+			name:      "non grpc request",
+			streaming: ServerStreaming,
+			input:     &http.Request{},
+			expected: []fiber.Response{
+				fiber.NewErrorResponse(fiberError.FiberError{
+					Code:    int(codes.InvalidArgument),
+					Message: "fiber: grpc dispatcher: only grpc.Request type of requests are supported",
+				}),
+			},
+		},
+		{
+			name:      "server streaming",
+			streaming: ServerStreaming,
+			input: &Request{
+				Message: []byte("payload"),
+			},
+			expected: []fiber.Response{
+				&Response{Message: []byte("payload"), Status: *status.New(codes.OK, "Success")},
+				&Response{Message: []byte("payload"), Status: *status.New(codes.OK, "Success")},
+				&Response{Message: []byte("payload"), Status: *status.New(codes.OK, "Success")},
+			},
+		},
+		{
+			name:      "bidirectional streaming",
+			streaming: BidiStreaming,
+			input: &Request{
+				Message: []byte("payload"),
+			},
+			expected: []fiber.Response{
+				&Response{Message: []byte("payload"), Status: *status.New(codes.OK, "Success")},
+				&Response{Message: []byte("payload"), Status: *status.New(codes.OK, "Success")},
+				&Response{Message: []byte("payload"), Status: *status.New(codes.OK, "Success")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher, err := NewDispatcher(DispatcherConfig{
+				ServiceMethod: "testproto.EchoService/Echo",
+				Endpoint:      fmt.Sprintf(":%d", streamingPort),
+				Timeout:       time.Second * 5,
+				Streaming:     tt.streaming,
+			})
+			require.NoError(t, err)
+			require.True(t, dispatcher.IsStreaming())
+
+			var responses []fiber.Response
+			for resp := range dispatcher.DoStream(context.Background(), tt.input) {
+				responses = append(responses, resp)
+			}
+
+			require.Len(t, responses, len(tt.expected))
+			for i, expected := range tt.expected {
+				assert.Equal(t, expected.IsSuccess(), responses[i].IsSuccess())
+				assert.Equal(t, expected.StatusCode(), responses[i].StatusCode())
+				assert.Equal(t, expected.Payload(), responses[i].Payload())
+			}
+		})
+	}
+}
+
+func TestDispatcher_DoTransparent(t *testing.T) {
+	dispatcher, err := NewDispatcher(DispatcherConfig{
+		Endpoint:       fmt.Sprintf(":%d", port),
+		Timeout:        time.Second * 5,
+		Transparent:    true,
+		AllowedMethods: []string{"testproto.UniversalPredictionService/*"},
+		DeniedMethods:  []string{"/testproto.UniversalPredictionService/Denied*"},
+	})
+	require.NoError(t, err, "unable to create dispatcher")
+	defer dispatcher.Close()
+
+	tests := []struct {
+		name         string
+		input        *Request
+		expectedCode codes.Code
+	}{
+		{
+			name: "success",
+			input: &Request{
+				FullMethod: "/" + serviceMethod,
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name:         "missing service method",
+			input:        &Request{},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "method not allowed",
+			input: &Request{
+				FullMethod: "/testproto.AnotherService/PredictValues",
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name: "method denied",
+			input: &Request{
+				FullMethod: "/testproto.UniversalPredictionService/DeniedMethod",
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := dispatcher.Do(tt.input)
+			require.EqualValues(t, tt.expectedCode, response.StatusCode())
+
+			if tt.expectedCode == codes.OK {
+				responseProto := &testproto.PredictValuesResponse{}
+				err = proto.Unmarshal(response.Payload(), responseProto)
+				require.NoError(t, err)
+				assert.True(t, proto.Equal(mockResponse, responseProto), "actual proto response don't match expected")
+			}
+		})
+	}
+}
+
+func TestDispatcher_SharedConnPool(t *testing.T) {
+	endpoint := fmt.Sprintf("localhost:%d", port)
+	dispatcher1, err := NewDispatcher(DispatcherConfig{Endpoint: endpoint, ServiceMethod: serviceMethod})
+	require.NoError(t, err)
+	dispatcher2, err := NewDispatcher(DispatcherConfig{Endpoint: endpoint, Transparent: true})
+	require.NoError(t, err)
+
+	pool, ok := dispatcher1.conn.(*sharedConnPool)
+	require.True(t, ok)
+	assert.Same(t, pool, dispatcher2.conn)
+	assert.Equal(t, 2, pool.refs)
+
+	require.NoError(t, dispatcher1.Close())
+	assert.Equal(t, 1, pool.refs)
+	// connections are still in use by the second dispatcher
+	response := dispatcher2.Do(&Request{FullMethod: serviceMethod})
+	assert.True(t, response.IsSuccess())
+
+	require.NoError(t, dispatcher2.Close())
+	assert.Equal(t, 0, pool.refs)
+	assert.NotContains(t, connPools.pools, endpoint)
+}
+
+func TestDispatcher_CloseTwice(t *testing.T) {
+	endpoint := fmt.Sprintf("localhost:%d", port)
+	dispatcher1, err := NewDispatcher(DispatcherConfig{Endpoint: endpoint, ServiceMethod: serviceMethod})
+	require.NoError(t, err)
+	dispatcher2, err := NewDispatcher(DispatcherConfig{Endpoint: endpoint, ServiceMethod: serviceMethod})
+	require.NoError(t, err)
+	defer dispatcher2.Close()
+
+	pool, ok := dispatcher1.conn.(*sharedConnPool)
+	require.True(t, ok)
+	require.Same(t, pool, dispatcher2.conn)
+
+	require.NoError(t, dispatcher1.Close())
+	require.NoError(t, dispatcher1.Close())
+	// the second close doesn't release the reference of the other dispatcher
+	assert.Equal(t, 1, pool.refs)
+	assert.Contains(t, connPools.pools, endpoint)
+	response := dispatcher2.Do(&Request{FullMethod: serviceMethod})
+	assert.True(t, response.IsSuccess())
+}
+
+func TestDispatcher_DoPreserveErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		preserveErrors bool
+		expected       fiber.Response
+	}{
+		{
+			name: "fiber error response",
+			expected: fiber.NewErrorResponse(fiberError.FiberError{
+				Code: int(codes.Unimplemented),
+				Message: "rpc error: code = Unimplemented desc = " +
+					"unknown method Unknown for service testproto.UniversalPredictionService",
+			}),
+		},
+		{
+			name:           "preserved error response",
+			preserveErrors: true,
+			expected: &Response{
+				Metadata: metadata.MD{},
+				Status: *status.New(codes.Unimplemented,
+					"unknown method Unknown for service testproto.UniversalPredictionService"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher, err := NewDispatcher(DispatcherConfig{
+				ServiceMethod:  "testproto.UniversalPredictionService/Unknown",
+				Endpoint:       fmt.Sprintf(":%d", port),
+				Timeout:        time.Second * 5,
+				PreserveErrors: tt.preserveErrors,
+			})
+			require.NoError(t, err)
+
+			response := dispatcher.Do(&Request{})
+			assert.False(t, response.IsSuccess())
+			assert.Equal(t, tt.expected.StatusCode(), response.StatusCode())
+			if grpcResponse, ok := response.(*Response); ok {
+				assert.Equal(t, tt.expected.(*Response).Status.Message(), grpcResponse.Status.Message())
+			} else {
+				assert.Equal(t, tt.expected, response)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DoContext(t *testing.T) {
+	dispatcher, err := NewDispatcher(DispatcherConfig{
+		ServiceMethod: serviceMethod,
+		Endpoint:      fmt.Sprintf(":%d", port),
+		Timeout:       time.Second * 5,
+	})
+	require.NoError(t, err)
+
+	response := dispatcher.DoContext(context.Background(), &Request{})
+	assert.True(t, response.IsSuccess())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	response = dispatcher.DoContext(ctx, &Request{})
+	assert.False(t, response.IsSuccess())
+	assert.EqualValues(t, codes.Canceled, response.StatusCode())
+}
+
+func TestDispatcher_TraceContext(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+	expectedTraceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	for name, streaming := range map[string]StreamType{"unary": Unary, "server streaming": ServerStreaming} {
+		t.Run(name, func(t *testing.T) {
+			dispatcher, err := NewDispatcher(DispatcherConfig{
+				ServiceMethod: "testproto.EchoService/Echo",
+				Endpoint:      fmt.Sprintf(":%d", echoMetadataPort),
+				Timeout:       time.Second * 5,
+				Streaming:     streaming,
+			})
+			require.NoError(t, err)
+
+			request := &Request{Message: []byte("payload"), Metadata: metadata.Pairs("key", "value")}
+			var response fiber.Response
+			if streaming == Unary {
+				response = dispatcher.DoContext(ctx, request)
+			} else {
+				response = <-dispatcher.DoStream(ctx, request)
+			}
+			require.True(t, response.IsSuccess())
+
+			// the server echoes the metadata of the request
+			md := response.(*Response).Metadata
+			assert.Equal(t, []string{expectedTraceParent}, md.Get("traceparent"))
+			assert.Equal(t, []string{"value"}, md.Get("key"))
+			// the metadata of the original request is left intact
+			assert.Equal(t, metadata.Pairs("key", "value"), request.Metadata)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberErrors "github.com/gojek/fiber/errors"
+	"github.com/gojek/fiber/protocol"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Options captures a set of options that can be used as configurations for
+// the gRPC Handler
+type Options struct {
+	Timeout time.Duration
+	// MetadataAsTrailer, if set, makes the handler send the response metadata
+	// back as trailers instead of headers
+	MetadataAsTrailer bool
+}
+
+// Handler is the gRPC ingress for a fiber component. It is expected to be registered
+// as the unknown service handler of a grpc.Server (see ServerOptions), so that every
+// incoming call is dispatched on the fiber component, regardless of its service method
+type Handler struct {
+	fiber.Component
+
+	options Options
+}
+
+// NewHandler is a creator factory for the Handler
+func NewHandler(c fiber.Component, options Options) *Handler {
+	return &Handler{
+		Component: c,
+		options:   options,
+	}
+}
+
+// ServerOptions returns the grpc.ServerOption(s) required to serve the fiber component
+// with a grpc.Server. Incoming frames are received as raw bytes with the FiberCodec, and
+// all the calls are handled by the Handler.
+//
+//	server := grpc.NewServer(handler.ServerOptions()...)
+func (h *Handler) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ForceServerCodec(NewFiberCodec()),
+		grpc.UnknownServiceHandler(h.StreamHandler),
+	}
+}
+
+// StreamHandler implements grpc.StreamHandler. It receives the request message,
+// dispatches it on the fiber component and sends the response back to the client
+func (h *Handler) StreamHandler(_ interface{}, stream grpc.ServerStream) error {
+	message := new(bytes.Buffer)
+	if err := stream.RecvMsg(message); err != nil {
+		return fiberErrors.ErrReadRequestFailed(protocol.GRPC, err).GRPCStatus().Err()
+	}
+
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	req := NewRequest(md.Copy(), message.Bytes(), nil)
+	req.FullMethod, _ = grpc.MethodFromServerStream(stream)
+
+	resp, err := h.DoRequest(stream.Context(), req)
+	if err != nil {
+		return err.GRPCStatus().Err()
+	}
+	return h.write(stream, resp)
+}
+
+// DoRequest dispatches the given grpc request and returns the response / error
+func (h *Handler) DoRequest(ctx context.Context, req *Request) (fiber.Response, *fiberErrors.FiberError) {
+	if h.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.options.Timeout)
+		defer cancel()
+	}
+
+	select {
+	case resp, ok := <-h.Dispatch(ctx, req).Iter():
+		if ok {
+			return resp, nil
+		}
+		return nil, fiberErrors.ErrNoValidResponseFromRoutes(protocol.GRPC)
+	case <-ctx.Done():
+		return nil, fiberErrors.ErrRequestTimeout(protocol.GRPC)
+	}
+}
+
+// write sends the response metadata and payload to the client. Unsuccessful
+// responses are converted into the grpc status returned to the client, the status
+// of unsuccessful grpc Response(s) is passed through as is
+func (h *Handler) write(stream grpc.ServerStream, resp fiber.Response) error {
+	if grpcResp, ok := resp.(*Response); ok {
+		if len(grpcResp.Metadata) > 0 {
+			if h.options.MetadataAsTrailer || !resp.IsSuccess() {
+				stream.SetTrailer(grpcResp.Metadata)
+			} else if err := stream.SetHeader(grpcResp.Metadata); err != nil {
+				return err
+			}
+		}
+		stream.SetTrailer(grpcResp.Trailer)
+	}
+
+	if !resp.IsSuccess() {
+		return responseError(resp)
+	}
+	return stream.SendMsg(resp.Payload())
+}
+
+// responseError creates the grpc status error from an unsuccessful fiber response
+func responseError(resp fiber.Response) error {
+	if grpcResp, ok := resp.(*Response); ok {
+		return grpcResp.Status.Err()
+	}
+
+	fiberErr := &fiberErrors.FiberError{
+		Code:    resp.StatusCode(),
+		Message: string(resp.Payload()),
+	}
+	if _, ok := resp.(*fiber.ErrorResponse); ok {
+		// error responses carry the json encoded FiberError as the payload
+		var payload fiberErrors.FiberError
+		if err := json.Unmarshal(resp.Payload(), &payload); err == nil {
+			fiberErr.Message = payload.Message
+		}
+	}
+	return fiberErr.GRPCStatus().Err()
+}
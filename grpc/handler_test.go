@@ -0,0 +1,188 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberErrors "github.com/gojek/fiber/errors"
+	fibergrpc "github.com/gojek/fiber/grpc"
+	testproto "github.com/gojek/fiber/internal/testdata/gen/testdata/proto"
+	"github.com/gojek/fiber/internal/testutils"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/gojek/fiber/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func serveHandler(t *testing.T, handler *fibergrpc.Handler) testproto.UniversalPredictionServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(handler.ServerOptions()...)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return testproto.NewUniversalPredictionServiceClient(conn)
+}
+
+func TestHandler_StreamHandler(t *testing.T) {
+	responseProto := &testproto.PredictValuesResponse{
+		Metadata: &testproto.ResponseMetadata{
+			PredictionId: "abc",
+			ModelName:    "linear",
+		},
+	}
+	responsePayload, err := proto.Marshal(responseProto)
+	require.NoError(t, err)
+
+	detailedStatus, err := status.New(codes.NotFound, "not found").
+		WithDetails(&testproto.ResponseMetadata{PredictionId: "abc"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		responses       []testUtilsHttp.DelayedResponse
+		options         fibergrpc.Options
+		expectedProto   *testproto.PredictValuesResponse
+		expectedHeader  metadata.MD
+		expectedTrailer metadata.MD
+		expectedStatus  *status.Status
+	}{
+		{
+			name: "ok response",
+			responses: []testUtilsHttp.DelayedResponse{
+				{
+					Response: &fibergrpc.Response{
+						Metadata: metadata.New(map[string]string{"key": "value"}),
+						Message:  responsePayload,
+						Status:   *status.New(codes.OK, "Success"),
+					},
+				},
+			},
+			options:        fibergrpc.Options{Timeout: time.Second},
+			expectedProto:  responseProto,
+			expectedHeader: metadata.New(map[string]string{"key": "value"}),
+		},
+		{
+			name: "ok response, metadata as trailer",
+			responses: []testUtilsHttp.DelayedResponse{
+				{
+					Response: &fibergrpc.Response{
+						Metadata: metadata.New(map[string]string{"key": "value"}),
+						Message:  responsePayload,
+						Status:   *status.New(codes.OK, "Success"),
+					},
+				},
+			},
+			options:         fibergrpc.Options{Timeout: time.Second, MetadataAsTrailer: true},
+			expectedProto:   responseProto,
+			expectedTrailer: metadata.New(map[string]string{"key": "value"}),
+		},
+		{
+			name: "error response",
+			responses: []testUtilsHttp.DelayedResponse{
+				{
+					Response: fiber.NewErrorResponse(fiberErrors.ErrNoValidResponseFromRoutes(protocol.GRPC)),
+				},
+			},
+			options:        fibergrpc.Options{Timeout: time.Second},
+			expectedStatus: status.New(codes.Unavailable, "fiber: no valid responses received from routes"),
+		},
+		{
+			name: "http error response",
+			responses: []testUtilsHttp.DelayedResponse{
+				{
+					Response: fiber.NewErrorResponse(fiberErrors.ErrInvalidInput(protocol.HTTP, assert.AnError)),
+				},
+			},
+			options:        fibergrpc.Options{Timeout: time.Second},
+			expectedStatus: status.New(codes.InvalidArgument, "fiber: "+assert.AnError.Error()),
+		},
+		{
+			name: "non-ok grpc response",
+			responses: []testUtilsHttp.DelayedResponse{
+				{
+					Response: &fibergrpc.Response{
+						Metadata: metadata.New(map[string]string{"key": "value"}),
+						Trailer:  metadata.New(map[string]string{"trailer-key": "value"}),
+						Status:   *detailedStatus,
+					},
+				},
+			},
+			options:        fibergrpc.Options{Timeout: time.Second},
+			expectedStatus: detailedStatus,
+			expectedTrailer: metadata.New(map[string]string{
+				"key":         "value",
+				"trailer-key": "value",
+			}),
+		},
+		{
+			name:           "no responses",
+			options:        fibergrpc.Options{Timeout: time.Second},
+			expectedStatus: status.New(codes.Unavailable, "fiber: no valid responses received from routes"),
+		},
+		{
+			name: "timeout exceeded",
+			responses: []testUtilsHttp.DelayedResponse{
+				{
+					Response: &fibergrpc.Response{
+						Message: responsePayload,
+						Status:  *status.New(codes.OK, "Success"),
+					},
+					Latency: 200 * time.Millisecond,
+				},
+			},
+			options:        fibergrpc.Options{Timeout: 50 * time.Millisecond},
+			expectedStatus: status.New(codes.DeadlineExceeded, "fiber: failed to receive a response within configured timeout"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := testutils.NewMockComponent("component", tt.responses...)
+			client := serveHandler(t, fibergrpc.NewHandler(component, tt.options))
+
+			var header, trailer metadata.MD
+			resp, err := client.PredictValues(
+				context.Background(),
+				&testproto.PredictValuesRequest{},
+				grpc.Header(&header),
+				grpc.Trailer(&trailer))
+
+			if tt.expectedStatus != nil {
+				actualStatus, ok := status.FromError(err)
+				require.True(t, ok)
+				assert.Equal(t, tt.expectedStatus.Code(), actualStatus.Code())
+				assert.Equal(t, tt.expectedStatus.Message(), actualStatus.Message())
+				assert.True(t, proto.Equal(tt.expectedStatus.Proto(), actualStatus.Proto()), "actual status don't match expected")
+			} else {
+				require.NoError(t, err)
+				assert.True(t, proto.Equal(tt.expectedProto, resp), "actual proto response don't match expected")
+			}
+			for key, values := range tt.expectedHeader {
+				assert.Equal(t, values, header.Get(key))
+			}
+			for key, values := range tt.expectedTrailer {
+				assert.Equal(t, values, trailer.Get(key))
+			}
+		})
+	}
+}
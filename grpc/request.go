@@ -12,6 +12,9 @@ type Request struct {
 	Metadata metadata.MD
 	Message  []byte
 	Proto    proto.Message
+	// FullMethod is the full name of the service method, that the request was received for,
+	// in the format "/{grpc_service_name}/{method_name}". It is used by transparent dispatchers
+	FullMethod string
 }
 
 func NewRequest(metadata metadata.MD, msg []byte, protoMsg proto.Message) *Request {
@@ -41,6 +44,9 @@ func (r *Request) Clone() (fiber.Request, error) {
 
 // OperationName is naming used in tracing interceptors
 func (r *Request) OperationName() string {
+	if r.FullMethod != "" {
+		return r.FullMethod
+	}
 	// For grpc implementation, serviceMethod and endpoint is init with dispatcher
 	return "grpc"
 }
@@ -72,6 +72,11 @@ func TestRequest_OperationName(t *testing.T) {
 			req:      Request{},
 			expected: "grpc",
 		},
+		{
+			name:     "request with service method",
+			req:      Request{FullMethod: "/testproto.UniversalPredictionService/PredictValues"},
+			expected: "/testproto.UniversalPredictionService/PredictValues",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
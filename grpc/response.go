@@ -2,6 +2,7 @@ package grpc
 
 import (
 	"strings"
+	"sync"
 
 	"github.com/gojek/fiber"
 	"google.golang.org/grpc/codes"
@@ -9,10 +10,17 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+// Response is the fiber response of the grpc call. Its labels can be read and set concurrently,
+// e.g. by the interceptors of the routes and the routers, that label the routes' responses
 type Response struct {
 	Metadata metadata.MD
-	Message  []byte
-	Status   status.Status
+	// Trailer holds the trailing metadata received from the server
+	Trailer metadata.MD
+	Message []byte
+	Status  status.Status
+
+	// mu guards the labels (the metadata) of the response
+	mu sync.RWMutex
 }
 
 func (r *Response) IsSuccess() bool {
@@ -30,6 +38,8 @@ func (r *Response) StatusCode() int {
 // Label returns all the values associated with the given key, in the response metadata.
 // If the key does not exist, an empty slice will be returned.
 func (r *Response) Label(key string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	return r.Metadata.Get(key)
 }
 
@@ -37,12 +47,16 @@ func (r *Response) Label(key string) []string {
 // If the key does not already exist, a new key will be created.
 // The modified response is returned.
 func (r *Response) WithLabel(key string, values ...string) fiber.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.Metadata.Append(key, values...)
 	return r
 }
 
 // WithLabels does the same thing as WithLabel but over a collection of key-values.
 func (r *Response) WithLabels(labels fiber.Labels) fiber.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for _, key := range labels.Keys() {
 		values := labels.Label(key)
 		r.Metadata.Append(key, values...)
@@ -57,6 +71,8 @@ func (r *Response) BackendName() string {
 // WithBackendName sets the given backend name in the response metadata.
 // The modified response is returned.
 func (r *Response) WithBackendName(backendName string) fiber.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.Metadata.Set("backend", backendName)
 	return r
 }
@@ -17,16 +17,16 @@ import (
 func TestResponse_Backend(t *testing.T) {
 	tests := []struct {
 		name        string
-		res         grpc.Response
-		want        grpc.Response
+		res         *grpc.Response
+		want        *grpc.Response
 		backendName string
 	}{
 		{
 			name: "ok",
-			res: grpc.Response{
+			res: &grpc.Response{
 				Metadata: map[string][]string{},
 			},
-			want: grpc.Response{
+			want: &grpc.Response{
 				Metadata: metadata.New(map[string]string{"backend": "testing"}),
 			},
 			backendName: "testing",
@@ -44,13 +44,13 @@ func TestResponse_Backend(t *testing.T) {
 func TestResponse_Status(t *testing.T) {
 	tests := []struct {
 		name            string
-		res             grpc.Response
+		res             *grpc.Response
 		expectedCode    int
 		expectedSuccess bool
 	}{
 		{
 			name: "ok",
-			res: grpc.Response{
+			res: &grpc.Response{
 				Status: *status.New(codes.OK, ""),
 			},
 			expectedCode:    0,
@@ -58,7 +58,7 @@ func TestResponse_Status(t *testing.T) {
 		},
 		{
 			name: "ok",
-			res: grpc.Response{
+			res: &grpc.Response{
 				Status: *status.New(codes.InvalidArgument, ""),
 			},
 			expectedCode:    3,
@@ -85,12 +85,12 @@ func TestResponse_Payload(t *testing.T) {
 	responseByte, _ := proto.Marshal(response)
 	tests := []struct {
 		name     string
-		req      grpc.Response
+		req      *grpc.Response
 		expected []byte
 	}{
 		{
 			name: "",
-			req: grpc.Response{
+			req: &grpc.Response{
 				Message: responseByte,
 			},
 			expected: responseByte,
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderGrpcTimeout is the header with the request timeout, in the grpc-timeout format,
+	// e.g. "100m" for 100 milliseconds
+	HeaderGrpcTimeout = "Grpc-Timeout"
+	// HeaderRequestTimeout is the header with the request timeout, either in milliseconds
+	// or as a duration string, e.g. "150ms"
+	HeaderRequestTimeout = "X-Request-Timeout"
+	// HeaderRequestDeadline is the header with the absolute request deadline, either as a
+	// RFC3339 timestamp or as milliseconds since the unix epoch
+	HeaderRequestDeadline = "X-Request-Deadline"
+)
+
+// requestTimeout returns the timeout requested by the client in the request headers.
+// If more than one header is present, the most restrictive timeout is returned.
+// The second returned value is false, if none of the headers is present
+func requestTimeout(header http.Header, now time.Time) (time.Duration, bool, error) {
+	var (
+		timeout time.Duration
+		found   bool
+	)
+
+	parsers := []struct {
+		name  string
+		parse func(string) (time.Duration, error)
+	}{
+		{name: HeaderGrpcTimeout, parse: parseGrpcTimeout},
+		{name: HeaderRequestTimeout, parse: parseRequestTimeout},
+		{name: HeaderRequestDeadline, parse: func(value string) (time.Duration, error) {
+			deadline, err := parseRequestDeadline(value)
+			return deadline.Sub(now), err
+		}},
+	}
+
+	for _, parser := range parsers {
+		value := header.Get(parser.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := parser.parse(value)
+		if err != nil {
+			return 0, false, fmt.Errorf("invalid %s header: %w", parser.name, err)
+		}
+		if !found || parsed < timeout {
+			timeout, found = parsed, true
+		}
+	}
+	return timeout, found, nil
+}
+
+// parseGrpcTimeout parses the timeout in the format of the grpc-timeout header,
+// that is a positive integer followed by the unit: H, M, S, m, u or n
+func parseGrpcTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 {
+		return 0, fmt.Errorf("malformed timeout: %s", value)
+	}
+	units := map[byte]time.Duration{
+		'H': time.Hour,
+		'M': time.Minute,
+		'S': time.Second,
+		'm': time.Millisecond,
+		'u': time.Microsecond,
+		'n': time.Nanosecond,
+	}
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("unknown timeout unit: %s", value)
+	}
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("malformed timeout: %s", value)
+	}
+	return time.Duration(amount) * unit, nil
+}
+
+// parseRequestTimeout parses the timeout in milliseconds or as a duration string
+func parseRequestTimeout(value string) (time.Duration, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(millis) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// parseRequestDeadline parses the deadline as a RFC3339 timestamp or as milliseconds since the unix epoch
+func parseRequestDeadline(value string) (time.Time, error) {
+	if millis, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(millis), nil
+	}
+	return time.Parse(time.RFC3339Nano, strings.TrimSpace(value))
+}
+
+// formatRequestTimeout formats the remaining time until the deadline as the value
+// of the HeaderRequestTimeout header, in milliseconds
+func formatRequestTimeout(deadline time.Time, now time.Time) string {
+	remaining := deadline.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return strconv.FormatInt(remaining.Milliseconds(), 10)
+}
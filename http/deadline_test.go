@@ -0,0 +1,101 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	now := time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		header          http.Header
+		expectedTimeout time.Duration
+		expectedFound   bool
+		expectedErr     string
+	}{
+		{
+			name:   "no headers",
+			header: http.Header{},
+		},
+		{
+			name:            "grpc-timeout",
+			header:          http.Header{HeaderGrpcTimeout: {"150m"}},
+			expectedTimeout: 150 * time.Millisecond,
+			expectedFound:   true,
+		},
+		{
+			name:            "request timeout in milliseconds",
+			header:          http.Header{HeaderRequestTimeout: {"200"}},
+			expectedTimeout: 200 * time.Millisecond,
+			expectedFound:   true,
+		},
+		{
+			name:            "request timeout as duration",
+			header:          http.Header{HeaderRequestTimeout: {"1.5s"}},
+			expectedTimeout: 1500 * time.Millisecond,
+			expectedFound:   true,
+		},
+		{
+			name:            "rfc3339 deadline",
+			header:          http.Header{HeaderRequestDeadline: {now.Add(time.Second).Format(time.RFC3339Nano)}},
+			expectedTimeout: time.Second,
+			expectedFound:   true,
+		},
+		{
+			name:            "unix deadline",
+			header:          http.Header{HeaderRequestDeadline: {"1672574400300"}},
+			expectedTimeout: 300 * time.Millisecond,
+			expectedFound:   true,
+		},
+		{
+			name: "most restrictive header",
+			header: http.Header{
+				HeaderGrpcTimeout:     {"1S"},
+				HeaderRequestTimeout:  {"100"},
+				HeaderRequestDeadline: {now.Add(500 * time.Millisecond).Format(time.RFC3339Nano)},
+			},
+			expectedTimeout: 100 * time.Millisecond,
+			expectedFound:   true,
+		},
+		{
+			name:        "unknown grpc-timeout unit",
+			header:      http.Header{HeaderGrpcTimeout: {"10x"}},
+			expectedErr: "invalid Grpc-Timeout header: unknown timeout unit: 10x",
+		},
+		{
+			name:        "malformed grpc-timeout",
+			header:      http.Header{HeaderGrpcTimeout: {"-1m"}},
+			expectedErr: "invalid Grpc-Timeout header: malformed timeout: -1m",
+		},
+		{
+			name:        "malformed request timeout",
+			header:      http.Header{HeaderRequestTimeout: {"soon"}},
+			expectedErr: `invalid X-Request-Timeout header: time: invalid duration "soon"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout, found, err := requestTimeout(tt.header, now)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFound, found)
+			assert.Equal(t, tt.expectedTimeout, timeout)
+		})
+	}
+}
+
+func TestFormatRequestTimeout(t *testing.T) {
+	now := time.Now()
+
+	assert.Equal(t, "250", formatRequestTimeout(now.Add(250*time.Millisecond), now))
+	assert.Equal(t, "0", formatRequestTimeout(now.Add(-time.Second), now))
+}
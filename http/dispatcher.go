@@ -1,10 +1,14 @@
 package http
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gojek/fiber"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // Client is the base interface for an http-client (to be able to mock actual implementation)
@@ -12,28 +16,110 @@ type Client interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
+// DispatcherOptions captures a set of optional configurations of the Dispatcher
+type DispatcherOptions struct {
+	// PreserveErrors makes the dispatcher keep unsuccessful (non-2xx) upstream responses
+	// as they are (status, headers and body), instead of replacing them with fiber error responses.
+	// Such responses are still not successful, so routers can fall back to other routes
+	PreserveErrors bool
+	// PropagateDeadline makes the dispatcher send the time remaining until the deadline
+	// of the request's context (in milliseconds) to the backend, in the HeaderRequestTimeout header
+	PropagateDeadline bool
+	// TransportProperties describe the configuration of the client's transport, such as its TLS
+	// or HTTP/2 settings, which can't be inspected on the client. They are included into the
+	// description of the dispatcher (see Describe)
+	TransportProperties map[string]interface{}
+}
+
 type Dispatcher struct {
 	httpClient Client
+	options    DispatcherOptions
 }
 
 func (d *Dispatcher) Do(req fiber.Request) fiber.Response {
 	if httpReq, ok := req.(*Request); ok {
-		resp, err := d.httpClient.Do(httpReq.Request)
-		if resp != nil && resp.Body != nil {
-			defer resp.Body.Close()
-			return NewHTTPResponse(resp)
+		return d.do(httpReq.Request)
+	}
+
+	return fiber.NewErrorResponse(errors.New("fiber: http.Dispatcher supports only http.Request type of requests"))
+}
+
+// DoContext dispatches the request with the given context, so that the request is cancelled
+// once the context is done
+func (d *Dispatcher) DoContext(ctx context.Context, req fiber.Request) fiber.Response {
+	if httpReq, ok := req.(*Request); ok {
+		outReq := httpReq.Request.WithContext(ctx)
+		// headers are shared between the clones of the request, so a copy is modified
+		if deadline, ok := ctx.Deadline(); ok && d.options.PropagateDeadline {
+			outReq.Header = outReq.Header.Clone()
+			outReq.Header.Set(HeaderRequestTimeout, formatRequestTimeout(deadline, time.Now()))
+		}
+		// the trace context (e.g. the W3C traceparent header) is injected, so the backend's spans join the trace
+		if traceContext := (propagation.MapCarrier{}); injectTraceContext(ctx, traceContext) {
+			outReq.Header = outReq.Header.Clone()
+			for key, value := range traceContext {
+				outReq.Header.Set(key, value)
+			}
 		}
-		return fiber.NewErrorResponse(err)
+		return d.do(outReq)
 	}
 
 	return fiber.NewErrorResponse(errors.New("fiber: http.Dispatcher supports only http.Request type of requests"))
 }
 
+// injectTraceContext injects the trace context of ctx into the carrier, with the OpenTelemetry propagator
+// from ctx (see fiber.CtxTextMapPropagatorKey) or the global one (see otel.SetTextMapPropagator).
+// It returns false, if there is nothing to inject
+func injectTraceContext(ctx context.Context, carrier propagation.MapCarrier) bool {
+	propagator, ok := ctx.Value(fiber.CtxTextMapPropagatorKey).(propagation.TextMapPropagator)
+	if !ok {
+		propagator = otel.GetTextMapPropagator()
+	}
+	propagator.Inject(ctx, carrier)
+	return len(carrier) > 0
+}
+
+func (d *Dispatcher) do(req *http.Request) fiber.Response {
+	resp, err := d.httpClient.Do(req)
+	if resp != nil && resp.Body != nil {
+		defer resp.Body.Close()
+		if d.options.PreserveErrors {
+			return NewUpstreamResponse(resp)
+		}
+		return NewHTTPResponse(resp)
+	}
+	return fiber.NewErrorResponse(err)
+}
+
+// Describe describes the properties of the dispatcher. The timeout is only known for *http.Client(s)
+func (d *Dispatcher) Describe() fiber.Description {
+	properties := make(map[string]interface{})
+	for key, value := range d.options.TransportProperties {
+		properties[key] = value
+	}
+	if httpClient, ok := d.httpClient.(*http.Client); ok {
+		properties["timeout"] = httpClient.Timeout.String()
+	}
+	if d.options.PreserveErrors {
+		properties["preserve_errors"] = true
+	}
+	if d.options.PropagateDeadline {
+		properties["propagate_deadline"] = true
+	}
+	return fiber.Description{Properties: properties}
+}
+
 func NewDispatcher(client Client) (fiber.Dispatcher, error) {
+	return NewDispatcherWithOptions(client, DispatcherOptions{})
+}
+
+// NewDispatcherWithOptions creates the Dispatcher with the given http client and options
+func NewDispatcherWithOptions(client Client, options DispatcherOptions) (fiber.Dispatcher, error) {
 	if client == nil {
 		return nil, errors.New("client can not be nil")
 	}
 	return &Dispatcher{
 		httpClient: client,
+		options:    options,
 	}, nil
 }
@@ -2,10 +2,13 @@ package http_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/gojek/fiber"
 	fiberHTTP "github.com/gojek/fiber/http"
@@ -13,6 +16,10 @@ import (
 	"github.com/gojek/fiber/protocol"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type unsupportedRequest struct {
@@ -116,3 +123,157 @@ func TestDispatcher_Do(t *testing.T) {
 	}
 
 }
+
+func TestDispatcher_DoPreserveErrors(t *testing.T) {
+	request := testUtilsHttp.MockReq("POST", "localhost:8080/dispatcher", "")
+	mockClient := new(MockHTTPClient)
+	mockClient.On("Do", request.Request).Once().Return(&http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": {"10"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte("slow down"))),
+	}, nil)
+
+	dispatcher, err := fiberHTTP.NewDispatcherWithOptions(mockClient, fiberHTTP.DispatcherOptions{
+		PreserveErrors: true,
+	})
+	assert.NoError(t, err)
+
+	resp := dispatcher.Do(request)
+	httpResp, ok := resp.(*fiberHTTP.Response)
+	assert.True(t, ok)
+	assert.False(t, httpResp.IsSuccess())
+	assert.Equal(t, http.StatusTooManyRequests, httpResp.StatusCode())
+	assert.Equal(t, []byte("slow down"), httpResp.Payload())
+	assert.Equal(t, "10", httpResp.Header().Get("Retry-After"))
+	mockClient.AssertExpectations(t)
+}
+
+func TestDispatcher_DoContext(t *testing.T) {
+	tests := []struct {
+		name              string
+		propagateDeadline bool
+		timeout           time.Duration
+		expectedHeader    bool
+	}{
+		{
+			name:              "deadline propagated",
+			propagateDeadline: true,
+			timeout:           time.Second,
+			expectedHeader:    true,
+		},
+		{
+			name:    "deadline not propagated",
+			timeout: time.Second,
+		},
+		{
+			name:              "no deadline",
+			propagateDeadline: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testUtilsHttp.MockReq("POST", "localhost:8080/dispatcher", "")
+
+			var outgoing *http.Request
+			mockClient := new(MockHTTPClient)
+			mockClient.On("Do", mock.Anything).Once().
+				Run(func(args mock.Arguments) {
+					outgoing = args.Get(0).(*http.Request)
+				}).
+				Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte("OK response"))),
+				}, nil)
+
+			dispatcher, err := fiberHTTP.NewDispatcherWithOptions(mockClient, fiberHTTP.DispatcherOptions{
+				PropagateDeadline: tt.propagateDeadline,
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			if tt.timeout > 0 {
+				ctx, cancel = context.WithTimeout(context.Background(), tt.timeout)
+			}
+			defer cancel()
+
+			contextDispatcher, ok := dispatcher.(fiber.ContextDispatcher)
+			require.True(t, ok)
+
+			resp := contextDispatcher.DoContext(ctx, request)
+			assert.True(t, resp.IsSuccess())
+			require.NotNil(t, outgoing)
+			assert.Equal(t, ctx, outgoing.Context())
+
+			if tt.expectedHeader {
+				timeout, err := strconv.Atoi(outgoing.Header.Get(fiberHTTP.HeaderRequestTimeout))
+				require.NoError(t, err)
+				assert.LessOrEqual(t, timeout, int(tt.timeout.Milliseconds()))
+				assert.Greater(t, timeout, 0)
+			} else {
+				assert.Empty(t, outgoing.Header.Get(fiberHTTP.HeaderRequestTimeout))
+			}
+			// the header of the original request is left intact
+			assert.Empty(t, request.Request.Header.Get(fiberHTTP.HeaderRequestTimeout))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDispatcher_TraceContext(t *testing.T) {
+	previous := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(previous) })
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	tracedCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	tests := []struct {
+		name                string
+		ctx                 context.Context
+		expectedTraceParent string
+	}{
+		{
+			name:                "trace context injected",
+			ctx:                 tracedCtx,
+			expectedTraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name: "no trace context",
+			ctx:  context.Background(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := testUtilsHttp.MockReq("POST", "localhost:8080/dispatcher", "")
+
+			var outgoing *http.Request
+			mockClient := new(MockHTTPClient)
+			mockClient.On("Do", mock.Anything).Once().
+				Run(func(args mock.Arguments) {
+					outgoing = args.Get(0).(*http.Request)
+				}).
+				Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte("OK response"))),
+				}, nil)
+
+			dispatcher, err := fiberHTTP.NewDispatcher(mockClient)
+			require.NoError(t, err)
+
+			resp := dispatcher.(fiber.ContextDispatcher).DoContext(tt.ctx, request)
+			assert.True(t, resp.IsSuccess())
+			require.NotNil(t, outgoing)
+			assert.Equal(t, tt.expectedTraceParent, outgoing.Header.Get("Traceparent"))
+			// the header of the original request is left intact
+			assert.Empty(t, request.Request.Header.Get("Traceparent"))
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -13,7 +13,14 @@ import (
 // Options captures a set of options that can be used as configurations for
 // the Request handler
 type Options struct {
+	// Timeout is the default timeout of the request, applied when the client hasn't supplied
+	// the timeout or deadline in the request headers (see HeaderGrpcTimeout, HeaderRequestTimeout
+	// and HeaderRequestDeadline). Requests without a deadline are not timed out, if it's zero
 	Timeout time.Duration
+	// MaxTimeout caps the timeout, requested by the client. Not applied, if zero
+	MaxTimeout time.Duration
+	// MinTimeout is the lower bound of the timeout, requested by the client. Not applied, if zero
+	MinTimeout time.Duration
 }
 
 // Handler is a structure used to capture a fiber component and a set of
@@ -48,7 +55,18 @@ func (h *Handler) ServeHTTP(writer http.ResponseWriter, httpReq *http.Request) {
 // DoRequest executes the given http request and returns the response / error
 func (h *Handler) DoRequest(httpReq *http.Request) (fiber.Response, *fiberErrors.FiberError) {
 	if req, err := NewHTTPRequest(httpReq); err == nil {
-		ctx, cancel := context.WithTimeout(req.Context(), h.options.Timeout)
+		timeout, err := h.timeout(req.Request.Header)
+		if err != nil {
+			return nil, fiberErrors.ErrInvalidInput(protocol.HTTP, err)
+		}
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), timeout)
+		} else {
+			ctx, cancel = context.WithCancel(req.Context())
+		}
 		defer cancel()
 
 		select {
@@ -57,7 +75,7 @@ func (h *Handler) DoRequest(httpReq *http.Request) (fiber.Response, *fiberErrors
 				return resp, nil
 			}
 			return nil, fiberErrors.ErrNoValidResponseFromRoutes(protocol.HTTP)
-		case <-time.After(h.options.Timeout):
+		case <-ctx.Done():
 			return nil, fiberErrors.ErrRequestTimeout(protocol.HTTP)
 		}
 	} else {
@@ -65,6 +83,29 @@ func (h *Handler) DoRequest(httpReq *http.Request) (fiber.Response, *fiberErrors
 	}
 }
 
+// timeout derives the effective timeout of the request from its headers and the handler options
+func (h *Handler) timeout(header http.Header) (time.Duration, error) {
+	timeout, found, err := requestTimeout(header, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return h.options.Timeout, nil
+	}
+
+	if h.options.MaxTimeout > 0 && timeout > h.options.MaxTimeout {
+		timeout = h.options.MaxTimeout
+	}
+	if timeout < h.options.MinTimeout {
+		timeout = h.options.MinTimeout
+	}
+	if timeout <= 0 {
+		// the deadline has already passed, time out immediately
+		timeout = time.Nanosecond
+	}
+	return timeout, nil
+}
+
 // write takes a response and writes its contents to the given writer
 func (h *Handler) write(resp fiber.Response, writer http.ResponseWriter) (err error) {
 	if httpResp, ok := resp.(*Response); ok {
@@ -160,3 +160,83 @@ func TestHandler_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_ClientDeadline(t *testing.T) {
+	response := testUtilsHttp.MockResp(200, string(responsePayload), nil, nil)
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		options        fiberHTTP.Options
+		latency        time.Duration
+		expectedStatus int
+	}{
+		{
+			name:           "no deadline",
+			latency:        20 * time.Millisecond,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "client timeout longer than the default",
+			header:         http.Header{fiberHTTP.HeaderRequestTimeout: {"200"}},
+			options:        fiberHTTP.Options{Timeout: 10 * time.Millisecond},
+			latency:        30 * time.Millisecond,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "client timeout exceeded",
+			header:         http.Header{fiberHTTP.HeaderGrpcTimeout: {"10m"}},
+			options:        fiberHTTP.Options{Timeout: time.Second},
+			latency:        50 * time.Millisecond,
+			expectedStatus: http.StatusRequestTimeout,
+		},
+		{
+			name: "deadline already passed",
+			header: http.Header{
+				fiberHTTP.HeaderRequestDeadline: {time.Now().Add(-time.Second).Format(time.RFC3339Nano)},
+			},
+			options:        fiberHTTP.Options{Timeout: time.Second},
+			latency:        10 * time.Millisecond,
+			expectedStatus: http.StatusRequestTimeout,
+		},
+		{
+			name:           "client timeout capped by max timeout",
+			header:         http.Header{fiberHTTP.HeaderRequestTimeout: {"1s"}},
+			options:        fiberHTTP.Options{MaxTimeout: 10 * time.Millisecond},
+			latency:        50 * time.Millisecond,
+			expectedStatus: http.StatusRequestTimeout,
+		},
+		{
+			name:           "client timeout raised to min timeout",
+			header:         http.Header{fiberHTTP.HeaderRequestTimeout: {"1"}},
+			options:        fiberHTTP.Options{MinTimeout: 200 * time.Millisecond},
+			latency:        20 * time.Millisecond,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid timeout header",
+			header:         http.Header{fiberHTTP.HeaderRequestTimeout: {"soon"}},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			component := testutils.NewMockComponent("component", testUtilsHttp.DelayedResponse{
+				Response: response,
+				Latency:  tt.latency,
+			})
+			handler := fiberHTTP.NewHandler(component, tt.options)
+
+			request := newHTTPRequest("POST", "localhost:8080/handler", http.NoBody)
+			for key, values := range tt.header {
+				request.Header[key] = values
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+		})
+	}
+}
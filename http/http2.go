@@ -0,0 +1,102 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// HTTP2Options captures a set of options of the HTTP/2 connections to the backend
+type HTTP2Options struct {
+	// Cleartext enables h2c, i.e. HTTP/2 over cleartext TCP with prior knowledge,
+	// for backends with the http:// scheme. Otherwise, HTTP/2 is negotiated over TLS
+	Cleartext bool
+	// StrictMaxConcurrentStreams makes the client respect the server's limit of concurrent
+	// streams across all connections: requests wait for a stream on an existing connection,
+	// instead of new connections being opened when the limit is reached
+	StrictMaxConcurrentStreams bool
+	// IdleConnTimeout is the time, for which idle connections are kept open for reuse.
+	// Defaults to 90 seconds, if zero. The cleartext connections are kept open, until the
+	// backend closes them (ReadIdleTimeout can be used to detect the broken ones)
+	IdleConnTimeout time.Duration
+	// ReadIdleTimeout, if set, enables health checks of the connections: a ping is sent,
+	// when no frames were received on a connection for this long
+	ReadIdleTimeout time.Duration
+	// PingTimeout is the time after which a connection is closed, if the response to
+	// the health check ping is not received. Defaults to 15 seconds
+	PingTimeout time.Duration
+}
+
+// NewHTTP2Transport creates a http.RoundTripper, that talks HTTP/2 to the backends.
+// HTTP/2 is negotiated over TLS (configured by tlsConfig, which may be nil), with
+// HTTP/1.1 as a fallback, unless options.Cleartext is set, in which case requests
+// are sent over cleartext TCP (h2c)
+func NewHTTP2Transport(tlsConfig *tls.Config, options HTTP2Options) (http.RoundTripper, error) {
+	return ConfigureHTTP2(NewTransport(tlsConfig, TransportOptions{}), options)
+}
+
+// ConfigureHTTP2 configures the transport (e.g. created with NewTransport) to talk HTTP/2
+// to the backends, see NewHTTP2Transport. The transport can't be configured more than once.
+// With options.Cleartext, the transport is left as it is: its dialer is used by the dedicated
+// h2c transport, that is returned instead
+func ConfigureHTTP2(transport *http.Transport, options HTTP2Options) (http.RoundTripper, error) {
+	if options.Cleartext {
+		return newH2CTransport(transport, options), nil
+	}
+
+	transport.ForceAttemptHTTP2 = true
+	if options.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = options.IdleConnTimeout
+	}
+	http2Transport, err := http2.ConfigureTransports(transport)
+	if err != nil {
+		return nil, err
+	}
+	http2Transport.StrictMaxConcurrentStreams = options.StrictMaxConcurrentStreams
+	http2Transport.ReadIdleTimeout = options.ReadIdleTimeout
+	http2Transport.PingTimeout = options.PingTimeout
+	return transport, nil
+}
+
+// newH2CTransport creates the HTTP/2 transport, that dials plain TCP connections with the dialer
+// of the transport in place of TLS, as documented for http2.Transport's AllowHTTP
+func newH2CTransport(transport *http.Transport, options HTTP2Options) *http2.Transport {
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			return dial(ctx, network, addr)
+		},
+		DisableCompression:         transport.DisableCompression,
+		StrictMaxConcurrentStreams: options.StrictMaxConcurrentStreams,
+		ReadIdleTimeout:            options.ReadIdleTimeout,
+		PingTimeout:                options.PingTimeout,
+	}
+}
+
+// H2COptions captures a set of options of the HTTP/2 connections, served by the h2c handler
+type H2COptions struct {
+	// MaxConcurrentStreams is the number of concurrent streams, that each client can
+	// open on a single connection. Defaults to 250, if zero
+	MaxConcurrentStreams uint32
+	// IdleTimeout is the time after which idle client connections are closed
+	IdleTimeout time.Duration
+}
+
+// NewH2CHandler wraps the handler (such as the fiber Handler), so it can also be served
+// over cleartext HTTP/2 (h2c), both with prior knowledge and via the HTTP/1.1 upgrade.
+// HTTP/1.1 requests are served by the handler as before
+func NewH2CHandler(handler http.Handler, options H2COptions) http.Handler {
+	return h2c.NewHandler(handler, &http2.Server{
+		MaxConcurrentStreams: options.MaxConcurrentStreams,
+		IdleTimeout:          options.IdleTimeout,
+	})
+}
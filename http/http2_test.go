@@ -0,0 +1,73 @@
+package http_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	fiberHTTP "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTP2Transport(t *testing.T) {
+	protoHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	})
+
+	h2cServer := httptest.NewServer(fiberHTTP.NewH2CHandler(protoHandler, fiberHTTP.H2COptions{
+		MaxConcurrentStreams: 10,
+	}))
+	defer h2cServer.Close()
+
+	tlsServer := httptest.NewUnstartedServer(protoHandler)
+	tlsServer.EnableHTTP2 = true
+	tlsServer.StartTLS()
+	defer tlsServer.Close()
+
+	tests := []struct {
+		name          string
+		url           string
+		tlsConfig     *tls.Config
+		options       fiberHTTP.HTTP2Options
+		expectedProto string
+	}{
+		{
+			name: "h2c",
+			url:  h2cServer.URL,
+			options: fiberHTTP.HTTP2Options{
+				Cleartext:                  true,
+				StrictMaxConcurrentStreams: true,
+			},
+			expectedProto: "HTTP/2.0",
+		},
+		{
+			name:          "http/1.1 over cleartext",
+			url:           h2cServer.URL,
+			expectedProto: "HTTP/1.1",
+		},
+		{
+			name: "http/2 over tls",
+			url:  tlsServer.URL,
+			tlsConfig: &tls.Config{
+				RootCAs: tlsServer.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs,
+			},
+			expectedProto: "HTTP/2.0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := fiberHTTP.NewHTTP2Transport(tt.tlsConfig, tt.options)
+			require.NoError(t, err)
+
+			client := &http.Client{Transport: transport}
+			for i := 0; i < 3; i++ {
+				resp, err := client.Get(tt.url)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedProto, string(readBytes(resp.Body)))
+			}
+		})
+	}
+}
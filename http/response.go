@@ -5,6 +5,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/gojek/fiber"
 	"github.com/gojek/fiber/errors"
@@ -13,9 +14,14 @@ import (
 // HeaderBackendName is the default backend name
 var headerBackendName = "X-Fiber-Route-ID"
 
+// Response is the fiber response of the http request. Its labels can be read and set concurrently,
+// e.g. by the interceptors of the routes and the routers, that label the routes' responses
 type Response struct {
 	*fiber.CachedPayload
 	response *http.Response
+
+	// mu guards the labels (the header) of the response
+	mu sync.RWMutex
 }
 
 // IsSuccess returns the success state of the request, which is true if the status
@@ -26,13 +32,17 @@ func (r *Response) IsSuccess() bool {
 // Label returns all the values associated with the given key, in the response header.
 // If the key does not exist, an empty slice will be returned.
 func (r *Response) Label(key string) []string {
-	return r.Header().Values(key)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.response.Header.Values(key)
 }
 
 // WithLabel appends the given value(s) to the key, in the response header.
 // If the key does not already exist, a new key will be created.
 // The modified response is returned.
 func (r *Response) WithLabel(key string, values ...string) fiber.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for _, value := range values {
 		r.Header().Add(key, value)
 	}
@@ -41,6 +51,8 @@ func (r *Response) WithLabel(key string, values ...string) fiber.Response {
 
 // WithLabels does the same thing as WithLabel but over a collection of key-values.
 func (r *Response) WithLabels(labels fiber.Labels) fiber.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	for _, key := range labels.Keys() {
 		values := labels.Label(key)
 		for _, value := range values {
@@ -58,6 +70,8 @@ func (r *Response) BackendName() string {
 // WithBackendName sets the given backend name in the response header.
 // The modified response is returned.
 func (r *Response) WithBackendName(backEnd string) fiber.Response {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.Header().Set(headerBackendName, backEnd)
 	return r
 }
@@ -101,6 +115,22 @@ func NewHTTPResponse(httpResponse *http.Response) fiber.Response {
 	}
 }
 
+// NewUpstreamResponse constructs a fiber http response from the http response, keeping
+// its status, headers and body as they are, regardless of the status code
+func NewUpstreamResponse(httpResponse *http.Response) fiber.Response {
+	if httpResponse == nil {
+		return fiber.NewErrorResponse(fmt.Errorf("empty response received"))
+	}
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return fiber.NewErrorResponse(fmt.Errorf("unable to read response body: %s", err.Error()))
+	}
+	return &Response{
+		response:      httpResponse,
+		CachedPayload: fiber.NewCachedPayload(body),
+	}
+}
+
 func isSuccessStatus(code int) bool {
 	return code/100 == 2
 }
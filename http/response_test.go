@@ -94,6 +94,52 @@ func TestNewHTTPResponse(t *testing.T) {
 	}
 }
 
+func TestNewUpstreamResponse(t *testing.T) {
+	tests := map[string]struct {
+		response        *http.Response
+		expectedSuccess bool
+		expectedStatus  int
+		expectedPayload []byte
+		expectedHeader  http.Header
+	}{
+		"ok response": {
+			response: &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       makeBody(responsePayload),
+			},
+			expectedSuccess: true,
+			expectedStatus:  http.StatusOK,
+			expectedPayload: responsePayload,
+			expectedHeader:  http.Header{"Content-Type": {"application/json"}},
+		},
+		"failure response": {
+			response: &http.Response{
+				StatusCode: http.StatusForbidden,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       makeBody([]byte("access denied")),
+			},
+			expectedSuccess: false,
+			expectedStatus:  http.StatusForbidden,
+			expectedPayload: []byte("access denied"),
+			expectedHeader:  http.Header{"Content-Type": {"text/plain"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := fiberHTTP.NewUpstreamResponse(tt.response)
+			httpResp, ok := resp.(*fiberHTTP.Response)
+			require.True(t, ok)
+
+			assert.Equal(t, tt.expectedSuccess, httpResp.IsSuccess())
+			assert.Equal(t, tt.expectedStatus, httpResp.StatusCode())
+			assert.Equal(t, tt.expectedPayload, httpResp.Payload())
+			assert.Equal(t, tt.expectedHeader, httpResp.Header())
+		})
+	}
+}
+
 func TestHTTPResponseLabel(t *testing.T) {
 	tests := map[string]struct {
 		response fiber.Response
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TransportOptions captures a set of options of the connections to the backends.
+// The defaults of http.DefaultTransport are used for the options, that are not set
+type TransportOptions struct {
+	// MaxIdleConns is the maximum number of idle connections across all hosts
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the maximum number of idle connections, that are kept for each host.
+	// Defaults to http.DefaultMaxIdleConnsPerHost
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the total number of connections for each host, including the
+	// connections in use. Unlimited, if zero
+	MaxConnsPerHost int
+	// IdleConnTimeout is the time, for which idle connections are kept open for reuse
+	IdleConnTimeout time.Duration
+	// DialTimeout is the maximum time to establish a TCP connection
+	DialTimeout time.Duration
+	// KeepAlive is the interval of the TCP keep-alive probes. Negative values disable them
+	KeepAlive time.Duration
+	// TLSHandshakeTimeout is the maximum time to wait for the TLS handshake
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout, if set, is the maximum time to wait for the response headers,
+	// after the request is written
+	ResponseHeaderTimeout time.Duration
+	// Proxy, if set, is the URL of the outbound proxy, that requests are sent through.
+	// Otherwise, the proxy is taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	Proxy *url.URL
+}
+
+// NewTransport creates the http.Transport with the given options and TLS config (which may be nil).
+// A single transport can be shared by several dispatchers, so they share its connections
+func NewTransport(tlsConfig *tls.Config, options TransportOptions) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	if options.DialTimeout > 0 || options.KeepAlive != 0 {
+		// the same defaults, as http.DefaultTransport has
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+		if options.DialTimeout > 0 {
+			dialer.Timeout = options.DialTimeout
+		}
+		if options.KeepAlive != 0 {
+			dialer.KeepAlive = options.KeepAlive
+		}
+		transport.DialContext = dialer.DialContext
+	}
+	if options.MaxIdleConns > 0 {
+		transport.MaxIdleConns = options.MaxIdleConns
+	}
+	if options.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	}
+	if options.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = options.IdleConnTimeout
+	}
+	if options.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = options.TLSHandshakeTimeout
+	}
+	transport.MaxConnsPerHost = options.MaxConnsPerHost
+	transport.ResponseHeaderTimeout = options.ResponseHeaderTimeout
+	if options.Proxy != nil {
+		transport.Proxy = http.ProxyURL(options.Proxy)
+	}
+	return transport
+}
@@ -0,0 +1,48 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	fiberHTTP "github.com/gojek/fiber/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	outboundProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("proxied:" + r.URL.Host))
+	}))
+	defer outboundProxy.Close()
+	proxyURL, err := url.Parse(outboundProxy.URL)
+	require.NoError(t, err)
+
+	defaults := fiberHTTP.NewTransport(nil, fiberHTTP.TransportOptions{})
+	assert.Equal(t, http.DefaultTransport.(*http.Transport).MaxIdleConns, defaults.MaxIdleConns)
+	assert.Equal(t, http.DefaultTransport.(*http.Transport).IdleConnTimeout, defaults.IdleConnTimeout)
+
+	transport := fiberHTTP.NewTransport(nil, fiberHTTP.TransportOptions{
+		MaxIdleConns:          10,
+		MaxIdleConnsPerHost:   5,
+		MaxConnsPerHost:       5,
+		IdleConnTimeout:       30 * time.Second,
+		DialTimeout:           time.Second,
+		TLSHandshakeTimeout:   time.Second,
+		ResponseHeaderTimeout: time.Second,
+		Proxy:                 proxyURL,
+	})
+	assert.Equal(t, 10, transport.MaxIdleConns)
+	assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 5, transport.MaxConnsPerHost)
+	assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, time.Second, transport.ResponseHeaderTimeout)
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get("http://backend.local")
+	require.NoError(t, err)
+	assert.Equal(t, "proxied:backend.local", string(readBytes(resp.Body)))
+}
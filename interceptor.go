@@ -14,6 +14,12 @@ var (
 	CtxComponentKindKey CtxKey = "CTX_COMPONENT_KIND"
 	// CtxComponentLabelsKey is used to denote the component's labels in the request context
 	CtxComponentLabelsKey CtxKey = "CTX_COMPONENT_LABELS"
+	// CtxTextMapPropagatorKey is used to denote the OpenTelemetry propagator (propagation.TextMapPropagator)
+	// in the request context, that the dispatchers inject the trace context into the calls to the backends with
+	CtxTextMapPropagatorKey CtxKey = "CTX_TEXT_MAP_PROPAGATOR"
+	// CtxComponentPathKey is used to denote the IDs of the component and its parents in the request
+	// context, separated with "/", e.g. "combiner/router/route_a"
+	CtxComponentPathKey CtxKey = "CTX_COMPONENT_PATH"
 )
 
 // Interceptor is the interface for a structural interceptor
@@ -23,6 +29,45 @@ type Interceptor interface {
 	AfterCompletion(ctx context.Context, req Request, queue ResponseQueue)
 }
 
+// ShortCircuitInterceptor is the Interceptor, that can answer the request itself, e.g. to reject the request
+// with the 401 or 400 FiberError, when it fails the authentication or the validation. ShortCircuit is called
+// instead of BeforeDispatch: if it returns a response, the component doesn't dispatch the request and responds
+// with the response instead. The response is passed to AfterDispatch and AfterCompletion of all the interceptors
+// of the component, whose BeforeDispatch is still called
+type ShortCircuitInterceptor interface {
+	Interceptor
+	ShortCircuit(ctx context.Context, req Request) (context.Context, Response)
+}
+
+// ShortCircuitFunc is the ShortCircuitInterceptor, that answers the request with the response of the function,
+// unless it's nil:
+//
+//	component.AddInterceptor(false, fiber.ShortCircuitFunc(func(ctx context.Context, req fiber.Request) fiber.Response {
+//		if len(req.Header()["Authorization"]) == 0 {
+//			return fiber.NewErrorResponse(errors.ErrUnauthenticated(req.Protocol(), errors.New("missing credentials")))
+//		}
+//		return nil
+//	}))
+type ShortCircuitFunc func(ctx context.Context, req Request) Response
+
+// ShortCircuit answers the request with the response of the function
+func (f ShortCircuitFunc) ShortCircuit(ctx context.Context, req Request) (context.Context, Response) {
+	return ctx, f(ctx, req)
+}
+
+// BeforeDispatch is an empty method, ShortCircuit is called instead
+func (f ShortCircuitFunc) BeforeDispatch(ctx context.Context, _ Request) context.Context {
+	return ctx
+}
+
+// AfterDispatch is an empty method
+func (f ShortCircuitFunc) AfterDispatch(context.Context, Request, ResponseQueue) {
+}
+
+// AfterCompletion is an empty method
+func (f ShortCircuitFunc) AfterCompletion(context.Context, Request, ResponseQueue) {
+}
+
 // NoopBeforeDispatchInterceptor does no operations before dispatch
 type NoopBeforeDispatchInterceptor struct{}
 
@@ -0,0 +1,146 @@
+package fiber_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/gojek/fiber"
+	fiberErrors "github.com/gojek/fiber/errors"
+	"github.com/gojek/fiber/extras"
+	"github.com/gojek/fiber/fibertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+// authenticator rejects the requests without the Authorization header
+var authenticator = fiber.ShortCircuitFunc(func(_ context.Context, req fiber.Request) fiber.Response {
+	for key := range req.Header() {
+		if http.CanonicalHeaderKey(key) == "Authorization" {
+			return nil
+		}
+	}
+	return fiber.NewErrorResponse(fiberErrors.ErrUnauthenticated(req.Protocol(), errors.New("missing credentials")))
+})
+
+// countingShortCircuit counts the calls of its ShortCircuit and BeforeDispatch, and answers every request
+type countingShortCircuit struct {
+	fiber.NoopAfterDispatchInterceptor
+	fiber.NoopAfterCompletionInterceptor
+	shortCircuits   int
+	beforeDispatchs int
+}
+
+func (i *countingShortCircuit) ShortCircuit(ctx context.Context, _ fiber.Request) (context.Context, fiber.Response) {
+	i.shortCircuits++
+	return ctx, fibertest.HTTPResponse(http.StatusOK, "short-circuited", nil)
+}
+
+func (i *countingShortCircuit) BeforeDispatch(ctx context.Context, _ fiber.Request) context.Context {
+	i.beforeDispatchs++
+	return ctx
+}
+
+func TestShortCircuitInterceptor(t *testing.T) {
+	tests := map[string]func(route fiber.Component) fiber.Component{
+		"caller": func(route fiber.Component) fiber.Component {
+			return route
+		},
+		"proxy": func(route fiber.Component) fiber.Component {
+			return fiber.NewProxy(fiber.NewBackend("proxy", "http://localhost"), route)
+		},
+		"combiner": func(route fiber.Component) fiber.Component {
+			combiner := fiber.NewCombiner("component")
+			combiner.SetRoutes(fibertest.Routes(route))
+			return combiner.WithFanIn(&extras.FastestResponseFanIn{})
+		},
+		"eager router": func(route fiber.Component) fiber.Component {
+			router := fiber.NewEagerRouter("component")
+			router.SetRoutes(fibertest.Routes(route))
+			router.SetStrategy(&orderedRoutingStrategy{})
+			return router
+		},
+		"lazy router": func(route fiber.Component) fiber.Component {
+			router := fiber.NewLazyRouter("component")
+			router.SetRoutes(fibertest.Routes(route))
+			router.SetStrategy(&orderedRoutingStrategy{})
+			return router
+		},
+		"fan out": func(route fiber.Component) fiber.Component {
+			fanOut := fiber.NewFanOut("component")
+			fanOut.SetRoutes(fibertest.Routes(route))
+			return fanOut
+		},
+		"fault injector": func(route fiber.Component) fiber.Component {
+			return fiber.NewFaultInjector("component", route)
+		},
+	}
+	for name, newComponent := range tests {
+		t.Run(name, func(t *testing.T) {
+			route := fibertest.NewComponent("route", fibertest.OK("ok"))
+			component := newComponent(route)
+			component.AddInterceptor(false, authenticator)
+			recorder := fibertest.NewRecorder()
+			component.AddInterceptor(false, recorder)
+
+			// the request is rejected without being dispatched
+			resp := fibertest.Dispatch(t, component, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+			require.NotNil(t, resp)
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode())
+			assert.Contains(t, string(resp.Payload()), "missing credentials")
+			assert.Equal(t, 0, route.Calls())
+
+			resp = fibertest.Dispatch(t, component, fibertest.NewGRPCRequest("/test.Service/Echo", nil, nil))
+			require.NotNil(t, resp)
+			assert.Equal(t, int(codes.Unauthenticated), resp.StatusCode())
+
+			req := fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", "")
+			req.Header()["Authorization"] = []string{"Bearer token"}
+			resp = fibertest.Dispatch(t, component, req)
+			fibertest.AssertResponse(t, resp, http.StatusOK, "ok")
+			assert.Equal(t, 1, route.Calls())
+
+			// the response of the interceptor is seen by the other interceptors
+			recorder.Wait()
+			dispatches := recorder.Dispatches()
+			require.Len(t, dispatches, 3)
+			require.Len(t, dispatches[0].Responses, 1)
+			assert.Equal(t, http.StatusUnauthorized, dispatches[0].Responses[0].StatusCode())
+		})
+	}
+}
+
+func TestShortCircuitInterceptor_Fallback(t *testing.T) {
+	routeA := fibertest.NewComponent("route_a", fibertest.OK("a"))
+	routeA.AddInterceptor(false, authenticator)
+	routeB := fibertest.NewComponent("route_b", fibertest.OK("b"))
+
+	router := fiber.NewLazyRouter("router")
+	router.SetRoutes(fibertest.Routes(routeA, routeB))
+	router.SetStrategy(&orderedRoutingStrategy{})
+
+	// the rejected route is a failed route
+	resp := fibertest.Dispatch(t, router, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertBackend(t, resp, "route_b")
+	fibertest.AssertResponse(t, resp, http.StatusOK, "b")
+	assert.Equal(t, 0, routeA.Calls())
+}
+
+func TestShortCircuitInterceptor_Order(t *testing.T) {
+	first := &countingShortCircuit{}
+	second := &countingShortCircuit{}
+	route := fibertest.NewComponent("route", fibertest.OK("ok"))
+	route.AddInterceptor(false, first, second)
+
+	resp := fibertest.Dispatch(t, route, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+	fibertest.AssertResponse(t, resp, http.StatusOK, "short-circuited")
+	assert.Equal(t, 0, route.Calls())
+
+	// the request is answered by the first interceptor, the second one sees it dispatched as usual
+	assert.Equal(t, 1, first.shortCircuits)
+	assert.Equal(t, 0, first.beforeDispatchs)
+	assert.Equal(t, 0, second.shortCircuits)
+	assert.Equal(t, 1, second.beforeDispatchs)
+}
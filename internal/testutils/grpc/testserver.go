@@ -3,6 +3,7 @@ package testutils
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 
 	testproto "github.com/gojek/fiber/internal/testdata/gen/testdata/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -49,3 +51,67 @@ func RunTestUPIServer(srv GrpcTestServer) {
 		}
 	}()
 }
+
+// rawCodec passes the frames through as raw bytes, so the streaming test server
+// can serve any service method without knowing its proto definitions
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) {
+	return *(v.(*[]byte)), nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*(v.(*[]byte)) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "raw"
+}
+
+// StreamingTestServer echoes every received message back Count times,
+// for any service method. If EchoMetadata is set, the metadata of the request
+// is also sent back as the response header
+type StreamingTestServer struct {
+	Port         int
+	Count        int
+	EchoMetadata bool
+}
+
+func (s *StreamingTestServer) handle(_ interface{}, stream grpc.ServerStream) error {
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok && s.EchoMetadata {
+		if err := stream.SetHeader(md); err != nil {
+			return err
+		}
+	}
+	for {
+		var msg []byte
+		if err := stream.RecvMsg(&msg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for i := 0; i < s.Count; i++ {
+			if err := stream.SendMsg(&msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func RunTestStreamingServer(srv StreamingTestServer) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", srv.Port))
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	s := grpc.NewServer(
+		grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(srv.handle),
+	)
+	log.Printf("Running Streaming Test Server at %v", srv.Port)
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			log.Fatalf("failed to serve: %v", err)
+		}
+	}()
+}
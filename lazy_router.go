@@ -2,6 +2,7 @@ package fiber
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/gojek/fiber/errors"
 	"github.com/gojek/fiber/util"
@@ -15,7 +16,8 @@ import (
 type LazyRouter struct {
 	*BaseMultiRouteComponent
 
-	strategy *baseRoutingStrategy
+	strategy atomic.Pointer[baseRoutingStrategy]
+	forced   forcedRoute
 }
 
 // NewLazyRouter initializes new LazyRouter
@@ -28,22 +30,39 @@ func NewLazyRouter(id string) *LazyRouter {
 	}
 }
 
-// SetStrategy sets routing strategy for this router
+// SetStrategy sets routing strategy for this router. The strategy can be replaced, while
+// requests are dispatched: the requests in flight keep using the previous strategy
 func (r *LazyRouter) SetStrategy(strategy RoutingStrategy) {
-	r.strategy = &baseRoutingStrategy{RoutingStrategy: strategy}
+	r.strategy.Store(&baseRoutingStrategy{RoutingStrategy: strategy})
+}
+
+// ForceRoute forces the router to select the route with the given ID, see RouteForcer
+func (r *LazyRouter) ForceRoute(id string) error {
+	return r.forced.force(id, r.GetRoutes())
+}
+
+// ForcedRoute returns the ID of the forced route, see RouteForcer
+func (r *LazyRouter) ForcedRoute() string {
+	return r.forced.get()
 }
 
 // Dispatch makes a synchronous call to a routing strategy to select the primary route and fallbacks.
 // After receiving a response it asynchronously asks a primary route to dispatch the request.
 // If all responseQueue from a primary route are OK, it sends them back to output
 // Otherwise it repeats the same with all fallback options one by one until one of fallbacks
-// successfully dispatches a request or all fallbacks tried and failed to dispatch it
+// successfully dispatches a request or all fallbacks tried and failed to dispatch it.
+// If none of the routes succeeds, the last unsuccessful upstream response (see isUpstreamError)
+// is sent back, so it can be passed to the client as it is
 func (r *LazyRouter) Dispatch(ctx context.Context, req Request) ResponseQueue {
-	ctx = r.beforeDispatch(ctx, req)
+	ctx, resp := r.beforeDispatch(ctx, req)
+	if resp != nil {
+		return r.shortCircuit(ctx, req, resp)
+	}
 	out := make(chan Response, 1)
 
 	queue := NewResponseQueue(out, 1)
 	defer r.afterDispatch(ctx, req, queue)
+	ctx, out = r.withTimeout(ctx, req, out)
 
 	go func() {
 		defer r.afterCompletion(ctx, req, queue)
@@ -52,7 +71,7 @@ func (r *LazyRouter) Dispatch(ctx context.Context, req Request) ResponseQueue {
 		var routes []Component
 		var labels Labels = NewLabelsMap()
 
-		routesOrderCh := r.strategy.getRoutesOrder(ctx, req, r.routes)
+		routesOrderCh := r.forced.routesOrder(ctx, req, r.strategy.Load(), r.GetRoutes())
 
 		select {
 		case routesOrderResponse, ok := <-routesOrderCh:
@@ -71,6 +90,7 @@ func (r *LazyRouter) Dispatch(ctx context.Context, req Request) ResponseQueue {
 		}
 
 		if len(routes) > 0 {
+			var upstreamError Response
 			// iterate over an ordered slice of possible routes
 			for _, route := range routes {
 				copyReq, _ := req.Clone()
@@ -83,6 +103,8 @@ func (r *LazyRouter) Dispatch(ctx context.Context, req Request) ResponseQueue {
 						if notClosed {
 							if ok = resp.IsSuccess(); ok {
 								responses = append(responses, resp.WithBackendName(route.ID()))
+							} else if isUpstreamError(resp) {
+								upstreamError = resp.WithBackendName(route.ID())
 							}
 						} else {
 							// all responseQueue from selected route are ok, sending them back to output
@@ -99,7 +121,11 @@ func (r *LazyRouter) Dispatch(ctx context.Context, req Request) ResponseQueue {
 				}
 			}
 			// if there are no valid response from all routes
-			out <- NewErrorResponse(errors.ErrNoValidResponseFromRoutes(req.Protocol()))
+			if upstreamError != nil {
+				out <- upstreamError.WithLabels(labels)
+			} else {
+				out <- NewErrorResponse(errors.ErrNoValidResponseFromRoutes(req.Protocol()))
+			}
 		} else {
 			// if no routes returned from strategy, return error
 			out <- NewErrorResponse(errors.ErrRouterStrategyReturnedEmptyRoutes(req.Protocol())).WithLabels(labels)
@@ -1,5 +1,12 @@
 package fiber
 
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
 // MultiRouteComponent - is a network component with zero or more possible routes,
 // such as FanOut, Combiner, Router
 type MultiRouteComponent interface {
@@ -9,6 +16,18 @@ type MultiRouteComponent interface {
 	GetRoutes() map[string]Component
 }
 
+// RouteController is implemented by the multi-route components, whose routes can be disabled
+// and enabled again while requests are dispatched, such as BaseMultiRouteComponent
+type RouteController interface {
+	// DisableRoute drains the route: no new requests are dispatched to it, the requests
+	// in flight are completed
+	DisableRoute(id string) error
+	// EnableRoute enables the route, that was disabled with DisableRoute
+	EnableRoute(id string) error
+	// DisabledRoutes returns the disabled routes by their IDs
+	DisabledRoutes() map[string]Component
+}
+
 // NewMultiRouteComponent is a factory function for creating a MultiRouteComponent
 func NewMultiRouteComponent(id string) *BaseMultiRouteComponent {
 	return &BaseMultiRouteComponent{
@@ -16,29 +35,133 @@ func NewMultiRouteComponent(id string) *BaseMultiRouteComponent {
 	}
 }
 
-// BaseMultiRouteComponent is a reference implementation of a MultiRouteComponent
+// BaseMultiRouteComponent is a reference implementation of a MultiRouteComponent. Its routes can be
+// changed (see SetRoutes and RouteController) concurrently with the requests being dispatched:
+// every request is dispatched to the snapshot of the routes, enabled when the request arrives
 type BaseMultiRouteComponent struct {
 	BaseComponent
-	routes map[string]Component
+
+	// mu serializes the changes of the routes
+	mu sync.Mutex
+	// routes are all the routes of the component, including the disabled ones
+	routes   map[string]Component
+	disabled map[string]bool
+	// enabled is the snapshot of the enabled routes, replaced whenever the routes are changed
+	enabled atomic.Pointer[map[string]Component]
 }
 
-// SetRoutes sets possible routes for this multi-route component
+// SetRoutes sets possible routes for this multi-route component. The routes, disabled
+// before, remain disabled, if they are set again
 func (multiRoute *BaseMultiRouteComponent) SetRoutes(routes map[string]Component) {
-	multiRoute.routes = routes
+	multiRoute.mu.Lock()
+	defer multiRoute.mu.Unlock()
+
+	multiRoute.routes = nil
+	if routes != nil {
+		multiRoute.routes = make(map[string]Component, len(routes))
+		for id, route := range routes {
+			multiRoute.routes[id] = route
+		}
+	}
+	multiRoute.updateEnabled()
 }
 
-// GetRoutes is a getter for the routes configured on the BaseMultiRouteComponent
+// GetRoutes is a getter for the enabled routes of the BaseMultiRouteComponent. The returned map must not be modified
 func (multiRoute *BaseMultiRouteComponent) GetRoutes() map[string]Component {
-	return multiRoute.routes
+	if enabled := multiRoute.enabled.Load(); enabled != nil {
+		return *enabled
+	}
+	return nil
+}
+
+// DisableRoute drains the route with the given ID, see RouteController
+func (multiRoute *BaseMultiRouteComponent) DisableRoute(id string) error {
+	multiRoute.mu.Lock()
+	defer multiRoute.mu.Unlock()
+
+	if _, exist := multiRoute.routes[id]; !exist {
+		return fmt.Errorf("unknown route: %s", id)
+	}
+	if multiRoute.disabled == nil {
+		multiRoute.disabled = make(map[string]bool)
+	}
+	multiRoute.disabled[id] = true
+	multiRoute.updateEnabled()
+	return nil
+}
+
+// EnableRoute enables the route with the given ID, see RouteController
+func (multiRoute *BaseMultiRouteComponent) EnableRoute(id string) error {
+	multiRoute.mu.Lock()
+	defer multiRoute.mu.Unlock()
+
+	if _, exist := multiRoute.routes[id]; !exist {
+		return fmt.Errorf("unknown route: %s", id)
+	}
+	delete(multiRoute.disabled, id)
+	multiRoute.updateEnabled()
+	return nil
+}
+
+// DisabledRoutes returns the disabled routes of the BaseMultiRouteComponent, see RouteController
+func (multiRoute *BaseMultiRouteComponent) DisabledRoutes() map[string]Component {
+	multiRoute.mu.Lock()
+	defer multiRoute.mu.Unlock()
+
+	disabled := make(map[string]Component)
+	for id := range multiRoute.disabled {
+		if route, exist := multiRoute.routes[id]; exist {
+			disabled[id] = route
+		}
+	}
+	return disabled
+}
+
+// allRoutes returns all the routes, including the disabled ones
+func (multiRoute *BaseMultiRouteComponent) allRoutes() []Component {
+	multiRoute.mu.Lock()
+	defer multiRoute.mu.Unlock()
+
+	routes := make([]Component, 0, len(multiRoute.routes))
+	for _, route := range multiRoute.routes {
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// updateEnabled replaces the snapshot of the enabled routes. It must be called with mu held
+func (multiRoute *BaseMultiRouteComponent) updateEnabled() {
+	if multiRoute.routes == nil {
+		multiRoute.enabled.Store(nil)
+		return
+	}
+	enabled := make(map[string]Component, len(multiRoute.routes))
+	for id, route := range multiRoute.routes {
+		if !multiRoute.disabled[id] {
+			enabled[id] = route
+		}
+	}
+	multiRoute.enabled.Store(&enabled)
 }
 
 // AddInterceptor can be used to (optionally, recursively) add one or more interceptors to
 // the BaseMultiRouteComponent
 func (multiRoute *BaseMultiRouteComponent) AddInterceptor(recursive bool, interceptors ...Interceptor) {
 	if recursive {
-		for _, route := range multiRoute.routes {
+		for _, route := range multiRoute.allRoutes() {
 			route.AddInterceptor(recursive, interceptors...)
 		}
 	}
 	multiRoute.BaseComponent.AddInterceptor(recursive, interceptors...)
 }
+
+// Close releases the resources held by all the routes of the BaseMultiRouteComponent
+func (multiRoute *BaseMultiRouteComponent) Close() error {
+	var errs []error
+	for _, route := range multiRoute.allRoutes() {
+		if err := closeComponent(route); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,6 +1,9 @@
 package fiber
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Proxy can be used to configure an intermediary for requests
 type Proxy struct {
@@ -26,3 +29,24 @@ func NewProxy(backend Backend, component Component) *Proxy {
 		backend:   backend,
 	}
 }
+
+// Close releases the resources held by the proxied component
+func (p *Proxy) Close() error {
+	return closeComponent(p.Component)
+}
+
+// SetTimeout sets the timeout of the proxied component (such as the Caller), see BaseComponent.SetTimeout.
+// It's ignored, if the proxied component has no timeout
+func (p *Proxy) SetTimeout(timeout time.Duration) {
+	if timed, ok := p.Component.(interface{ SetTimeout(time.Duration) }); ok {
+		timed.SetTimeout(timeout)
+	}
+}
+
+// Timeout returns the timeout of the proxied component, see SetTimeout
+func (p *Proxy) Timeout() time.Duration {
+	if timed, ok := p.Component.(interface{ Timeout() time.Duration }); ok {
+		return timed.Timeout()
+	}
+	return 0
+}
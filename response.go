@@ -1,6 +1,8 @@
 package fiber
 
 import (
+	"sync"
+
 	"github.com/gojek/fiber/errors"
 	"github.com/gojek/fiber/protocol"
 )
@@ -16,10 +18,15 @@ type Response interface {
 	WithLabels(Labels) Response
 }
 
+// ErrorResponse is the response of the failed request. Its labels and backend name can be read
+// and set concurrently
 type ErrorResponse struct {
 	*CachedPayload
+	code int
+
+	// mu guards the labels and the backend name
+	mu      sync.RWMutex
 	labels  Labels
-	code    int
 	backend string
 }
 
@@ -28,10 +35,14 @@ func (resp *ErrorResponse) IsSuccess() bool {
 }
 
 func (resp *ErrorResponse) BackendName() string {
+	resp.mu.RLock()
+	defer resp.mu.RUnlock()
 	return resp.backend
 }
 
 func (resp *ErrorResponse) WithBackendName(backendName string) Response {
+	resp.mu.Lock()
+	defer resp.mu.Unlock()
 	resp.backend = backendName
 	return resp
 }
@@ -41,15 +52,21 @@ func (resp *ErrorResponse) StatusCode() int {
 }
 
 func (resp *ErrorResponse) Label(key string) []string {
+	resp.mu.RLock()
+	defer resp.mu.RUnlock()
 	return resp.labels.Label(key)
 }
 
 func (resp *ErrorResponse) WithLabel(key string, values ...string) Response {
+	resp.mu.Lock()
+	defer resp.mu.Unlock()
 	resp.labels = resp.labels.WithLabel(key, values...)
 	return resp
 }
 
 func (resp *ErrorResponse) WithLabels(labels Labels) Response {
+	resp.mu.Lock()
+	defer resp.mu.Unlock()
 	for _, key := range labels.Keys() {
 		resp.labels = resp.labels.WithLabel(key, labels.Label(key)...)
 	}
@@ -58,9 +75,12 @@ func (resp *ErrorResponse) WithLabels(labels Labels) Response {
 
 func NewErrorResponse(err error) Response {
 	var fiberErr *errors.FiberError
-	if castedError, ok := err.(*errors.FiberError); ok {
+	switch castedError := err.(type) {
+	case *errors.FiberError:
 		fiberErr = castedError
-	} else {
+	case errors.FiberError:
+		fiberErr = &castedError
+	default:
 		fiberErr = errors.NewFiberError(protocol.HTTP, err)
 	}
 	payload, _ := fiberErr.ToJSON()
@@ -70,3 +90,11 @@ func NewErrorResponse(err error) Response {
 		labels:        NewLabelsMap(),
 	}
 }
+
+// isUpstreamError returns true, if the response is the unsuccessful response of the backend, that
+// the dispatcher kept as it is (see e.g. the PreserveErrors option of http.Dispatcher), rather than
+// replaced with an ErrorResponse
+func isUpstreamError(resp Response) bool {
+	_, isErrorResponse := resp.(*ErrorResponse)
+	return !resp.IsSuccess() && !isErrorResponse
+}
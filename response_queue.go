@@ -6,13 +6,24 @@ type ResponseQueue interface {
 	Iter() <-chan Response
 }
 
+// responseQueue keeps every response received so far, so that each subscriber
+// can iterate over all of them at its own pace. Subscribers never block the producer,
+// which allows a single queue to carry an arbitrary number of (streamed) responses.
 type responseQueue struct {
-	lock          sync.RWMutex
-	items         []Response
-	buffer        int
-	subscriptions []chan Response
+	lock   sync.Mutex
+	cond   *sync.Cond
+	items  []Response
+	buffer int
+	closed bool
+}
 
-	done chan struct{}
+func newResponseQueue(items []Response, bufferSize int) *responseQueue {
+	queue := &responseQueue{
+		items:  items,
+		buffer: bufferSize,
+	}
+	queue.cond = sync.NewCond(&queue.lock)
+	return queue
 }
 
 func (r *responseQueue) append(resp Response) {
@@ -20,37 +31,55 @@ func (r *responseQueue) append(resp Response) {
 	defer r.lock.Unlock()
 
 	r.items = append(r.items, resp)
-	for _, subscription := range r.subscriptions {
-		subscription <- resp
+	r.cond.Broadcast()
+}
+
+func (r *responseQueue) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closed = true
+	r.cond.Broadcast()
+}
+
+// next blocks until the response with the given index is available and returns it.
+// The second returned value is false if the queue is closed and has no such response
+func (r *responseQueue) next(idx int) (Response, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for idx >= len(r.items) && !r.closed {
+		r.cond.Wait()
 	}
+	if idx < len(r.items) {
+		return r.items[idx], true
+	}
+	return nil, false
 }
 
 func (r *responseQueue) Iter() <-chan Response {
 	out := make(chan Response, r.buffer)
 
 	go func() {
-		r.lock.Lock()
-		r.subscriptions = append(r.subscriptions, out)
-		for _, resp := range r.items {
+		defer close(out)
+
+		for idx := 0; ; idx++ {
+			resp, ok := r.next(idx)
+			if !ok {
+				return
+			}
 			out <- resp
 		}
-		r.lock.Unlock()
-
-		<-r.done
-		close(out)
 	}()
 	return out
 }
 
 // NewResponseQueue takes an input channel and creates a Queue with all responseQueue from it
 func NewResponseQueue(in <-chan Response, bufferSize int) ResponseQueue {
-	queue := &responseQueue{
-		buffer: bufferSize,
-		done:   make(chan struct{}),
-	}
+	queue := newResponseQueue(nil, bufferSize)
 
 	go func(q *responseQueue) {
-		defer close(q.done)
+		defer q.close()
 
 		for resp := range in {
 			q.append(resp)
@@ -62,12 +91,7 @@ func NewResponseQueue(in <-chan Response, bufferSize int) ResponseQueue {
 // NewResponseQueueFromResponses takes list of responses and constructs
 // an instance of ResponseQueue from them
 func NewResponseQueueFromResponses(responses ...Response) ResponseQueue {
-	queue := &responseQueue{
-		items:  responses,
-		buffer: len(responses),
-		done:   make(chan struct{}),
-	}
-
-	close(queue.done)
+	queue := newResponseQueue(responses, len(responses))
+	queue.closed = true
 	return queue
 }
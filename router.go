@@ -1,5 +1,11 @@
 package fiber
 
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+)
+
 // Router is a network component, that uses provided RoutingStrategy to
 // select a route (child component), that should dispatch an incoming request
 type Router interface {
@@ -8,3 +14,54 @@ type Router interface {
 	// Sets routing strategy for this router
 	SetStrategy(strategy RoutingStrategy)
 }
+
+// RouteForcer is implemented by the routers, that can be forced to select the given route
+// for every request, regardless of their routing strategy, e.g. for testing
+type RouteForcer interface {
+	// ForceRoute forces the router to select the route with the given ID, or
+	// makes it use its routing strategy again, if the ID is empty
+	ForceRoute(id string) error
+	// ForcedRoute returns the ID of the forced route, or an empty string if it's not set
+	ForcedRoute() string
+}
+
+// forcedRoute is the ID of the route, that the router is forced to select
+type forcedRoute struct {
+	id atomic.Pointer[string]
+}
+
+func (f *forcedRoute) force(id string, routes map[string]Component) error {
+	if id == "" {
+		f.id.Store(nil)
+		return nil
+	}
+	if _, exist := routes[id]; !exist {
+		return fmt.Errorf("unknown route: %s", id)
+	}
+	f.id.Store(&id)
+	return nil
+}
+
+func (f *forcedRoute) get() string {
+	if id := f.id.Load(); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// routesOrder returns the forced route, if it's set and enabled, or the routes in
+// the order, selected by the routing strategy
+func (f *forcedRoute) routesOrder(
+	ctx context.Context,
+	req Request,
+	strategy *baseRoutingStrategy,
+	routes map[string]Component,
+) <-chan routesOrderResponse {
+	if route, exist := routes[f.get()]; exist {
+		out := make(chan routesOrderResponse, 1)
+		out <- routesOrderResponse{Components: []Component{route}, Labels: NewLabelsMap()}
+		close(out)
+		return out
+	}
+	return strategy.getRoutesOrder(ctx, req, routes)
+}
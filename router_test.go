@@ -0,0 +1,219 @@
+package fiber_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gojek/fiber"
+	fiberErrors "github.com/gojek/fiber/errors"
+	"github.com/gojek/fiber/fibertest"
+	fiberhttp "github.com/gojek/fiber/http"
+	testUtilsHttp "github.com/gojek/fiber/internal/testutils/http"
+	"github.com/gojek/fiber/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// orderedRoutingStrategy selects the routes in the order of their IDs
+type orderedRoutingStrategy struct {
+	fiber.BaseFiberType
+}
+
+func (s *orderedRoutingStrategy) SelectRoute(
+	_ context.Context,
+	_ fiber.Request,
+	routes map[string]fiber.Component,
+) (fiber.Component, []fiber.Component, fiber.Labels, error) {
+	ids := make([]string, 0, len(routes))
+	for id := range routes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	ordered := make([]fiber.Component, 0, len(ids))
+	for _, id := range ids {
+		ordered = append(ordered, routes[id])
+	}
+	if len(ordered) == 0 {
+		return nil, nil, fiber.NewLabelsMap(), nil
+	}
+	return ordered[0], ordered[1:], fiber.NewLabelsMap(), nil
+}
+
+// payloadComponent responds with its ID, with a new response for every request
+type payloadComponent struct {
+	*fiber.BaseComponent
+}
+
+func (c *payloadComponent) Dispatch(context.Context, fiber.Request) fiber.ResponseQueue {
+	return fiber.NewResponseQueueFromResponses(testUtilsHttp.MockResp(200, c.ID(), nil, nil))
+}
+
+type controlledRouter interface {
+	fiber.Router
+	fiber.RouteController
+	fiber.RouteForcer
+}
+
+func newControlledRouters() map[string]controlledRouter {
+	routes := func() map[string]fiber.Component {
+		routes := make(map[string]fiber.Component)
+		for _, id := range []string{"route-a", "route-b", "route-c"} {
+			routes[id] = &payloadComponent{BaseComponent: fiber.NewBaseComponent(id, fiber.CallerKind)}
+		}
+		return routes
+	}
+
+	lazyRouter := fiber.NewLazyRouter("lazy-router")
+	lazyRouter.SetRoutes(routes())
+	lazyRouter.SetStrategy(&orderedRoutingStrategy{})
+
+	eagerRouter := fiber.NewEagerRouter("eager-router")
+	eagerRouter.SetRoutes(routes())
+	eagerRouter.SetStrategy(&orderedRoutingStrategy{})
+
+	return map[string]controlledRouter{"lazy router": lazyRouter, "eager router": eagerRouter}
+}
+
+func dispatchPayload(t *testing.T, router fiber.Component) string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	resp, ok := <-router.Dispatch(ctx, testUtilsHttp.MockReq("GET", "http://localhost:8080", "")).Iter()
+	if !assert.True(t, ok) {
+		return ""
+	}
+	return string(resp.Payload())
+}
+
+func TestRouter_RouteControl(t *testing.T) {
+	for name, router := range newControlledRouters() {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, "route-a", dispatchPayload(t, router))
+
+			require.NoError(t, router.DisableRoute("route-a"))
+			assert.Equal(t, "route-b", dispatchPayload(t, router))
+			assert.Len(t, router.GetRoutes(), 2)
+			assert.Contains(t, router.DisabledRoutes(), "route-a")
+
+			// the disabled route can't be selected by the strategy, unless it's forced
+			require.NoError(t, router.ForceRoute("route-c"))
+			assert.Equal(t, "route-c", router.ForcedRoute())
+			assert.Equal(t, "route-c", dispatchPayload(t, router))
+			require.NoError(t, router.DisableRoute("route-c"))
+			assert.Equal(t, "route-b", dispatchPayload(t, router))
+
+			require.NoError(t, router.EnableRoute("route-c"))
+			assert.Equal(t, "route-c", dispatchPayload(t, router))
+			require.NoError(t, router.ForceRoute(""))
+			assert.Empty(t, router.ForcedRoute())
+			require.NoError(t, router.EnableRoute("route-a"))
+			assert.Equal(t, "route-a", dispatchPayload(t, router))
+			assert.Empty(t, router.DisabledRoutes())
+
+			assert.EqualError(t, router.DisableRoute("route-d"), "unknown route: route-d")
+			assert.EqualError(t, router.EnableRoute("route-d"), "unknown route: route-d")
+			assert.EqualError(t, router.ForceRoute("route-d"), "unknown route: route-d")
+		})
+	}
+}
+
+func TestRouter_ConcurrentRouteControl(t *testing.T) {
+	for name, router := range newControlledRouters() {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 20; j++ {
+						assert.Contains(t, []string{"route-a", "route-b", "route-c"}, dispatchPayload(t, router))
+					}
+				}()
+			}
+			for i := 0; i < 20; i++ {
+				assert.NoError(t, router.DisableRoute("route-a"))
+				assert.NoError(t, router.ForceRoute("route-c"))
+				router.SetStrategy(&orderedRoutingStrategy{})
+				assert.NoError(t, router.EnableRoute("route-a"))
+				assert.NoError(t, router.ForceRoute(""))
+			}
+			wg.Wait()
+		})
+	}
+}
+
+// upstreamStatus replies with the unsuccessful http response, kept as it is, the same way as the
+// http.Dispatcher does with PreserveErrors
+func upstreamStatus(code int, body string) fibertest.Reply {
+	return fibertest.Reply{Response: func() fiber.Response {
+		return fiberhttp.NewUpstreamResponse(&http.Response{
+			StatusCode: code,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		})
+	}}
+}
+
+func TestRouter_UpstreamErrors(t *testing.T) {
+	upstreamError := upstreamStatus(http.StatusServiceUnavailable, "unavailable")
+	fiberError := fibertest.Error(fiberErrors.ErrRequestFailed(protocol.HTTP, errors.New("connection refused")))
+
+	tests := map[string]struct {
+		routeA          fibertest.Reply
+		routeB          fibertest.Reply
+		expectedBackend string
+		expectedStatus  int
+		expectedPayload string
+	}{
+		"last upstream error": {
+			routeA:          upstreamError,
+			routeB:          upstreamStatus(http.StatusTooManyRequests, "throttled"),
+			expectedBackend: "route_b",
+			expectedStatus:  http.StatusTooManyRequests,
+			expectedPayload: "throttled",
+		},
+		"upstream error of the primary route": {
+			routeA:          upstreamError,
+			routeB:          fiberError,
+			expectedBackend: "route_a",
+			expectedStatus:  http.StatusServiceUnavailable,
+			expectedPayload: "unavailable",
+		},
+		"no upstream errors": {
+			routeA:          fiberError,
+			routeB:          fiberError,
+			expectedStatus:  http.StatusBadGateway,
+			expectedPayload: "no valid responses received from routes",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			routes := func() map[string]fiber.Component {
+				return fibertest.Routes(fibertest.NewComponent("route_a", tt.routeA), fibertest.NewComponent("route_b", tt.routeB))
+			}
+			lazyRouter := fiber.NewLazyRouter("lazy-router")
+			lazyRouter.SetRoutes(routes())
+			lazyRouter.SetStrategy(&orderedRoutingStrategy{})
+			eagerRouter := fiber.NewEagerRouter("eager-router")
+			eagerRouter.SetRoutes(routes())
+			eagerRouter.SetStrategy(&orderedRoutingStrategy{})
+
+			for _, router := range []fiber.Component{lazyRouter, eagerRouter} {
+				resp := fibertest.Dispatch(t, router, fibertest.NewHTTPRequest(http.MethodGet, "http://localhost", ""))
+				require.NotNil(t, resp, router.ID())
+				assert.Equal(t, tt.expectedStatus, resp.StatusCode(), router.ID())
+				assert.Contains(t, string(resp.Payload()), tt.expectedPayload, router.ID())
+				if tt.expectedBackend != "" {
+					fibertest.AssertBackend(t, resp, tt.expectedBackend)
+				}
+			}
+		})
+	}
+}
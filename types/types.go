@@ -1,11 +1,14 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/gojek/fiber"
 	"github.com/gojek/fiber/extras"
+	"github.com/gojek/fiber/extras/interceptor"
 )
 
 // Category is an alias for a string
@@ -16,11 +19,21 @@ const (
 	RoutingStrategy = "ROUTING_STRATEGY"
 	// FanIn is the type name use to define a FAN_IN
 	FanIn = "FAN_IN"
+	// Interceptor is the type name use to define an INTERCEPTOR
+	Interceptor = "INTERCEPTOR"
 )
 
+// ConfigurableInterceptor is the interceptor, that can be declared in the fiber config.
+// It's initialized with the properties from the config
+type ConfigurableInterceptor interface {
+	fiber.Type
+	fiber.Interceptor
+}
+
 var categories = map[Category]reflect.Type{
 	RoutingStrategy: reflect.TypeOf((*fiber.RoutingStrategy)(nil)).Elem(),
 	FanIn:           reflect.TypeOf((*fiber.FanIn)(nil)).Elem(),
+	Interceptor:     reflect.TypeOf((*ConfigurableInterceptor)(nil)).Elem(),
 }
 
 var types = map[Category]map[string]reflect.Type{
@@ -30,6 +43,14 @@ var types = map[Category]map[string]reflect.Type{
 	FanIn: {
 		"fiber.FastestResponseFanIn": reflect.TypeOf(&extras.FastestResponseFanIn{}).Elem(),
 	},
+	Interceptor: {
+		"fiber.AccessLogInterceptor":     reflect.TypeOf(&interceptor.AccessLogInterceptor{}).Elem(),
+		"fiber.CaptureInterceptor":       reflect.TypeOf(&interceptor.CaptureInterceptor{}).Elem(),
+		"fiber.LoggingInterceptor":       reflect.TypeOf(&interceptor.ResponseLoggingInterceptor{}).Elem(),
+		"fiber.PrometheusInterceptor":    reflect.TypeOf(&interceptor.PrometheusInterceptor{}).Elem(),
+		"fiber.OpenTelemetryInterceptor": reflect.TypeOf(&interceptor.OpenTelemetryInterceptor{}).Elem(),
+		"fiber.TracingInterceptor":       reflect.TypeOf(&interceptor.TracingInterceptor{}).Elem(),
+	},
 }
 
 func typeByName(category Category, typez string) (interface{}, error) {
@@ -78,3 +99,91 @@ func FanInByName(name string) (fiber.FanIn, error) {
 	}
 	return nil, fmt.Errorf("incompatible fan-in type: %s", name)
 }
+
+// InterceptorByName identifies an interceptor type that matches the type name specified
+// and returns an instance of that type
+func InterceptorByName(name string) (ConfigurableInterceptor, error) {
+	instance, err := typeByName(Interceptor, name)
+	if err != nil {
+		return nil, err
+	}
+	if typed, ok := instance.(ConfigurableInterceptor); ok {
+		return typed, nil
+	}
+	return nil, fmt.Errorf("incompatible interceptor type: %s", name)
+}
+
+// PropertiesValidator can be implemented by the interceptors, whose Initialize acquires resources, such as
+// the open files or the registered metrics, to check the properties without acquiring them. config.Validate
+// checks such interceptors with ValidateProperties, instead of initializing them
+type PropertiesValidator interface {
+	ValidateProperties(properties json.RawMessage) error
+}
+
+// PropertiesSchemaProvider can be implemented by the routing strategies, fan-ins and interceptors,
+// to describe the properties they are initialized with, as a JSON Schema. The schema is included
+// into the JSON Schema of the fiber config (see config.JSONSchema)
+type PropertiesSchemaProvider interface {
+	PropertiesSchema() map[string]interface{}
+}
+
+// Names returns the sorted names of the types, installed in the category
+func Names(category Category) []string {
+	names := make([]string, 0, len(types[category]))
+	for name := range types[category] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PropertiesSchema returns the JSON Schema of the properties of the type, installed in the category
+// with the given name. The second returned value is false, if the type doesn't describe its properties
+func PropertiesSchema(category Category, name string) (map[string]interface{}, bool) {
+	instance, err := typeByName(category, name)
+	if err != nil {
+		return nil, false
+	}
+	if provider, ok := instance.(PropertiesSchemaProvider); ok {
+		return provider.PropertiesSchema(), true
+	}
+	return nil, false
+}
+
+// PropertiesDescriber can be implemented by the routing strategies and fan-ins, to describe the
+// properties they were initialized with (see config.Marshal). The strategies and fan-ins, that don't
+// implement it, are described by their JSON encoding
+type PropertiesDescriber interface {
+	Properties() (json.RawMessage, error)
+}
+
+// NameOf returns the name, that the type of the given routing strategy or fan-in is installed
+// with in the category. The second returned value is false, if the type is not installed
+func NameOf(category Category, instance interface{}) (string, bool) {
+	instanceType := reflect.TypeOf(instance)
+	if instanceType == nil || instanceType.Kind() != reflect.Ptr {
+		return "", false
+	}
+	for _, name := range Names(category) {
+		if types[category][name] == instanceType.Elem() {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// Properties describes the properties of the routing strategy or fan-in (see PropertiesDescriber).
+// It returns nil, if the instance has no properties
+func Properties(instance interface{}) (json.RawMessage, error) {
+	if describer, ok := instance.(PropertiesDescriber); ok {
+		return describer.Properties()
+	}
+	properties, err := json.Marshal(instance)
+	if err != nil {
+		return nil, err
+	}
+	if string(properties) == "{}" || string(properties) == "null" {
+		return nil, nil
+	}
+	return properties, nil
+}
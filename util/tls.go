@@ -0,0 +1,44 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// NewClientTLSConfig creates a client-side tls.Config from the PEM encoded files. The server
+// certificate is verified against the CA bundle in caFile, or against the system roots if caFile
+// is empty. The client certificate (for mTLS) is loaded, if both certFile and keyFile are set.
+// serverName, if set, overrides the server name used to verify the server certificate.
+func NewClientTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if caFile != "" {
+		caBundle, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+		certPool := x509.NewCertPool()
+		if !certPool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no valid certificates found in CA bundle: %s", caFile)
+		}
+		cfg.RootCAs = certPool
+	}
+
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("both client certificate and key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}